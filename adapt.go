@@ -0,0 +1,158 @@
+package chikit
+
+// Adapter for legacy handlers that write directly to the ResponseWriter.
+// Captures their output and translates it into response state so the
+// Handler wrapper's buffered write path applies uniformly.
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// rawResponse is a non-JSON body captured by Adapt, written with its own content type.
+type rawResponse struct {
+	contentType string
+	body        []byte
+}
+
+// Adapt wraps a legacy handler that writes directly to the ResponseWriter so it
+// participates in the response state model. The handler's writes are captured and
+// translated when it returns:
+//   - A status >= 400 becomes SetError. A chikit error envelope body is kept as-is;
+//     otherwise the matching sentinel (or a generic error) is used with the body
+//     text (e.g., from http.Error) as the message.
+//   - A JSON success body becomes SetJSONResponse, written verbatim.
+//   - A non-JSON success body is written as-is with its Content-Type.
+//   - Headers set by the handler are copied to the response state.
+//
+// Without the Handler wrapper, the legacy handler runs unchanged.
+// Output is buffered, so streaming handlers that rely on http.Flusher should not be adapted.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(chikit.WithCanonlog()))
+//	r.Get("/legacy/report", chikit.Adapt(legacyReportHandler).ServeHTTP)
+func Adapt(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !HasState(r.Context()) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &captureWriter{header: make(http.Header)}
+		h.ServeHTTP(cw, r)
+
+		contentType := cw.header.Get("Content-Type")
+		for key, values := range cw.header {
+			switch http.CanonicalHeaderKey(key) {
+			case "Content-Type", "Content-Length":
+				continue
+			}
+			for _, value := range values {
+				AddHeader(r, key, value)
+			}
+		}
+
+		status := cw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		body := cw.body.Bytes()
+
+		if status >= 400 {
+			SetError(r, adaptedError(status, contentType, body))
+			return
+		}
+
+		switch {
+		case len(body) == 0:
+			SetResponse(r, status, nil)
+		case isJSONContentType(contentType, body):
+			SetJSONResponse(r, status, body)
+		default:
+			if contentType == "" {
+				contentType = http.DetectContentType(body)
+			}
+			SetResponse(r, status, rawResponse{contentType: contentType, body: body})
+		}
+	})
+}
+
+// adaptedError converts a captured error response into an APIError.
+func adaptedError(status int, contentType string, body []byte) *APIError {
+	if isJSONContentType(contentType, body) {
+		var envelope errorResponse
+		if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil && envelope.Error.Type != "" {
+			envelope.Error.Status = status
+			return envelope.Error
+		}
+	}
+
+	apiErr := errorForStatus(status)
+	if message := strings.TrimSpace(string(body)); message != "" && !isJSONContentType(contentType, body) {
+		return apiErr.With(message)
+	}
+	return apiErr
+}
+
+// errorForStatus returns the sentinel error for status, or a generic error
+// carrying status when there is no sentinel.
+func errorForStatus(status int) *APIError {
+	for _, sentinel := range []*APIError{
+		ErrBadRequest, ErrUnauthorized, ErrPaymentRequired, ErrForbidden, ErrNotFound,
+		ErrMethodNotAllowed, ErrConflict, ErrGone, ErrPayloadTooLarge, ErrUnprocessableEntity,
+		ErrRateLimited, ErrInternal, ErrNotImplemented, ErrServiceUnavailable, ErrGatewayTimeout,
+	} {
+		if sentinel.Status == status {
+			return sentinel
+		}
+	}
+
+	base := ErrBadRequest
+	if status >= 500 {
+		base = ErrInternal
+	}
+	apiErr := base.With(http.StatusText(status))
+	apiErr.Status = status
+	return apiErr
+}
+
+// isJSONContentType reports whether a captured body is JSON, by its Content-Type
+// or, when none was set, by its content.
+func isJSONContentType(contentType string, body []byte) bool {
+	if contentType == "" {
+		return json.Valid(body)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// captureWriter buffers a legacy handler's headers, status, and body.
+type captureWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *captureWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
@@ -0,0 +1,174 @@
+package chikit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdapt_JSONSuccess(t *testing.T) {
+	var state *State
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state = getState(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Legacy", "1")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":"42"}`)
+	})
+
+	handler := Handler()(Adapt(legacy))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/users", http.NoBody))
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", rec.Code)
+	}
+	if rec.Body.String() != `{"id":"42"}` {
+		t.Errorf("expected JSON body verbatim, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("X-Legacy") != "1" {
+		t.Error("expected legacy header to be copied")
+	}
+
+	// Direct writes land in state rather than on the wire
+	state.mu.Lock()
+	status, body := state.status, state.body
+	state.mu.Unlock()
+	if status != http.StatusCreated {
+		t.Errorf("expected state status 201, got %d", status)
+	}
+	if _, ok := body.(json.RawMessage); !ok {
+		t.Errorf("expected state body to be json.RawMessage, got %T", body)
+	}
+}
+
+func TestAdapt_MatchesStateOutcome(t *testing.T) {
+	legacy := Handler()(Adapt(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"name": "Ada"})
+	})))
+	modern := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, map[string]string{"name": "Ada"})
+	}))
+
+	legacyRec := httptest.NewRecorder()
+	legacy.ServeHTTP(legacyRec, httptest.NewRequest("GET", "/", http.NoBody))
+	modernRec := httptest.NewRecorder()
+	modern.ServeHTTP(modernRec, httptest.NewRequest("GET", "/", http.NoBody))
+
+	if legacyRec.Code != modernRec.Code || legacyRec.Body.String() != modernRec.Body.String() {
+		t.Errorf("expected same outcome, got %d %q vs %d %q",
+			legacyRec.Code, legacyRec.Body.String(), modernRec.Code, modernRec.Body.String())
+	}
+	if legacyRec.Header().Get("Content-Type") != modernRec.Header().Get("Content-Type") {
+		t.Error("expected same Content-Type")
+	}
+}
+
+func TestAdapt_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		legacy          http.HandlerFunc
+		expectedStatus  int
+		expectedCode    ErrorCode
+		expectedMessage string
+	}{
+		{
+			name: "http.Error",
+			legacy: func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "User not found", http.StatusNotFound)
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedCode:    ErrorCodeNotFound,
+			expectedMessage: "User not found",
+		},
+		{
+			name: "status without body",
+			legacy: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			expectedStatus:  http.StatusForbidden,
+			expectedCode:    ErrorCodeForbidden,
+			expectedMessage: "Forbidden",
+		},
+		{
+			name: "status without sentinel",
+			legacy: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			},
+			expectedStatus:  http.StatusTeapot,
+			expectedCode:    ErrorCodeBadRequest,
+			expectedMessage: "I'm a teapot",
+		},
+		{
+			name: "error envelope",
+			legacy: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				io.WriteString(w, `{"error":{"type":"request_error","code":"conflict","message":"Email taken"}}`)
+			},
+			expectedStatus:  http.StatusConflict,
+			expectedCode:    ErrorCodeConflict,
+			expectedMessage: "Email taken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(Adapt(tt.legacy))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			var resp map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["error"].Code != tt.expectedCode {
+				t.Errorf("expected code %s, got %s", tt.expectedCode, resp["error"].Code)
+			}
+			if resp["error"].Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, resp["error"].Message)
+			}
+		})
+	}
+}
+
+func TestAdapt_NonJSONPassthrough(t *testing.T) {
+	handler := Handler()(Adapt(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		io.WriteString(w, "id,name\n1,Ada\n")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/export", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("expected Content-Type text/csv, got %s", ct)
+	}
+	if rec.Body.String() != "id,name\n1,Ada\n" {
+		t.Errorf("expected raw body, got %q", rec.Body.String())
+	}
+}
+
+func TestAdapt_WithoutWrapper(t *testing.T) {
+	handler := Adapt(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", rec.Code)
+	}
+}
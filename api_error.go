@@ -6,24 +6,80 @@
 package chikit
 
 import (
+	"context"
+	"errors"
 	"net/http"
 )
 
+// ErrorType is the broad category of an API error, serialized as "type".
+// Clients can switch on these values instead of raw strings.
+type ErrorType string
+
+// Error types used by chikit.
+const (
+	ErrorTypeRequest    ErrorType = "request_error"
+	ErrorTypeAuth       ErrorType = "auth_error"
+	ErrorTypeNotFound   ErrorType = "not_found"
+	ErrorTypeValidation ErrorType = "validation_error"
+	ErrorTypeRateLimit  ErrorType = "rate_limit_error"
+	ErrorTypeInternal   ErrorType = "internal_error"
+	ErrorTypeTimeout    ErrorType = "timeout_error"
+)
+
+// ErrorCode is the specific reason for an API error, serialized as "code".
+// Applications may define their own codes alongside these.
+type ErrorCode string
+
+// Error codes used by chikit.
+const (
+	ErrorCodeBadRequest         ErrorCode = "bad_request"
+	ErrorCodeUnauthorized       ErrorCode = "unauthorized"
+	ErrorCodePaymentRequired    ErrorCode = "payment_required"
+	ErrorCodeForbidden          ErrorCode = "forbidden"
+	ErrorCodeNotFound           ErrorCode = "resource_not_found"
+	ErrorCodeMethodNotAllowed   ErrorCode = "method_not_allowed"
+	ErrorCodeConflict           ErrorCode = "conflict"
+	ErrorCodeGone               ErrorCode = "gone"
+	ErrorCodePreconditionFailed ErrorCode = "precondition_failed"
+	ErrorCodePayloadTooLarge    ErrorCode = "payload_too_large"
+	ErrorCodeUnsupportedMedia   ErrorCode = "unsupported_media_type"
+	ErrorCodeUnprocessable      ErrorCode = "unprocessable"
+	ErrorCodeLimitExceeded      ErrorCode = "limit_exceeded"
+	ErrorCodeQuotaExceeded      ErrorCode = "quota_exceeded"
+	ErrorCodeInternal           ErrorCode = "internal"
+	ErrorCodeNotImplemented     ErrorCode = "not_implemented"
+	ErrorCodeServiceUnavailable ErrorCode = "service_unavailable"
+	ErrorCodeGatewayTimeout     ErrorCode = "gateway_timeout"
+	ErrorCodeClientClosed       ErrorCode = "client_closed_request"
+	ErrorCodeInvalidRequest     ErrorCode = "invalid_request"
+	ErrorCodeMissingHeader      ErrorCode = "missing_header"
+	ErrorCodeInvalidHeader      ErrorCode = "invalid_header"
+)
+
 // APIError represents a structured API error response.
 type APIError struct {
-	Type    string       `json:"type"`
-	Code    string       `json:"code,omitempty"`
+	Type    ErrorType    `json:"type"`
+	Code    ErrorCode    `json:"code,omitempty"`
 	Message string       `json:"message"`
 	Param   string       `json:"param,omitempty"`
 	Errors  []FieldError `json:"errors,omitempty"`
-	Status  int          `json:"-"`
+	// ErrorsByCode groups failing field params by validation code, replacing
+	// Errors when binding uses BindWithGroupedErrors.
+	ErrorsByCode    map[string][]string `json:"errors_by_code,omitempty"`
+	DocsURL         string              `json:"docs_url,omitempty"`
+	SuggestedAction string              `json:"suggested_action,omitempty"`
+	Status          int                 `json:"-"`
 }
 
 // FieldError represents a validation error for a specific field.
 type FieldError struct {
-	Param   string `json:"param"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Param   string `json:"param" xml:"param"`
+	Code    string `json:"code" xml:"code"`
+	Message string `json:"message" xml:"message"`
+
+	// path is the dotted path to a nested field (e.g., "items.0.sku") used for
+	// problem details pointers. Param is used when empty.
+	path string
 }
 
 type errorResponse struct {
@@ -68,32 +124,85 @@ func (e *APIError) WithParam(message, param string) *APIError {
 	return &dup
 }
 
+// WithDocs returns a copy of the error with a link to documentation about it.
+func (e *APIError) WithDocs(url string) *APIError {
+	if e == nil {
+		return nil
+	}
+	dup := *e
+	dup.DocsURL = url
+	return &dup
+}
+
+// WithAction returns a copy of the error with a suggested action for the client
+// (e.g., "Refresh the access token and retry").
+func (e *APIError) WithAction(action string) *APIError {
+	if e == nil {
+		return nil
+	}
+	dup := *e
+	dup.SuggestedAction = action
+	return &dup
+}
+
 // Predefined sentinel errors
 var (
-	ErrBadRequest          = &APIError{Type: "request_error", Code: "bad_request", Message: "Bad request", Status: http.StatusBadRequest}
-	ErrUnauthorized        = &APIError{Type: "auth_error", Code: "unauthorized", Message: "Unauthorized", Status: http.StatusUnauthorized}
-	ErrPaymentRequired     = &APIError{Type: "request_error", Code: "payment_required", Message: "Payment required", Status: http.StatusPaymentRequired}
-	ErrForbidden           = &APIError{Type: "auth_error", Code: "forbidden", Message: "Forbidden", Status: http.StatusForbidden}
-	ErrNotFound            = &APIError{Type: "not_found", Code: "resource_not_found", Message: "Resource not found", Status: http.StatusNotFound}
-	ErrMethodNotAllowed    = &APIError{Type: "request_error", Code: "method_not_allowed", Message: "Method not allowed", Status: http.StatusMethodNotAllowed}
-	ErrConflict            = &APIError{Type: "request_error", Code: "conflict", Message: "Conflict", Status: http.StatusConflict}
-	ErrGone                = &APIError{Type: "request_error", Code: "gone", Message: "Resource gone", Status: http.StatusGone}
-	ErrPayloadTooLarge     = &APIError{Type: "request_error", Code: "payload_too_large", Message: "Payload too large", Status: http.StatusRequestEntityTooLarge}
-	ErrUnprocessableEntity = &APIError{Type: "validation_error", Code: "unprocessable", Message: "Unprocessable entity", Status: http.StatusUnprocessableEntity}
-	ErrRateLimited         = &APIError{Type: "rate_limit_error", Code: "limit_exceeded", Message: "Rate limit exceeded", Status: http.StatusTooManyRequests}
-	ErrInternal            = &APIError{Type: "internal_error", Code: "internal", Message: "Internal server error", Status: http.StatusInternalServerError}
-	ErrNotImplemented      = &APIError{Type: "request_error", Code: "not_implemented", Message: "Not implemented", Status: http.StatusNotImplemented}
-	ErrServiceUnavailable  = &APIError{Type: "request_error", Code: "service_unavailable", Message: "Service unavailable", Status: http.StatusServiceUnavailable}
-	ErrGatewayTimeout      = &APIError{Type: "timeout_error", Code: "gateway_timeout", Message: "Request timed out", Status: http.StatusGatewayTimeout}
+	ErrBadRequest          = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeBadRequest, Message: "Bad request", Status: http.StatusBadRequest}
+	ErrUnauthorized        = &APIError{Type: ErrorTypeAuth, Code: ErrorCodeUnauthorized, Message: "Unauthorized", Status: http.StatusUnauthorized}
+	ErrPaymentRequired     = &APIError{Type: ErrorTypeRequest, Code: ErrorCodePaymentRequired, Message: "Payment required", Status: http.StatusPaymentRequired}
+	ErrForbidden           = &APIError{Type: ErrorTypeAuth, Code: ErrorCodeForbidden, Message: "Forbidden", Status: http.StatusForbidden}
+	ErrNotFound            = &APIError{Type: ErrorTypeNotFound, Code: ErrorCodeNotFound, Message: "Resource not found", Status: http.StatusNotFound}
+	ErrMethodNotAllowed    = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeMethodNotAllowed, Message: "Method not allowed", Status: http.StatusMethodNotAllowed}
+	ErrConflict            = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeConflict, Message: "Conflict", Status: http.StatusConflict}
+	ErrGone                = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeGone, Message: "Resource gone", Status: http.StatusGone}
+	ErrPreconditionFailed  = &APIError{Type: ErrorTypeRequest, Code: ErrorCodePreconditionFailed, Message: "Precondition failed", Status: http.StatusPreconditionFailed}
+	ErrPayloadTooLarge     = &APIError{Type: ErrorTypeRequest, Code: ErrorCodePayloadTooLarge, Message: "Payload too large", Status: http.StatusRequestEntityTooLarge}
+	ErrUnsupportedMedia    = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeUnsupportedMedia, Message: "Unsupported media type", Status: http.StatusUnsupportedMediaType}
+	ErrUnprocessableEntity = &APIError{Type: ErrorTypeValidation, Code: ErrorCodeUnprocessable, Message: "Unprocessable entity", Status: http.StatusUnprocessableEntity}
+	ErrRateLimited         = &APIError{Type: ErrorTypeRateLimit, Code: ErrorCodeLimitExceeded, Message: "Rate limit exceeded", Status: http.StatusTooManyRequests}
+	ErrQuotaExceeded       = &APIError{Type: ErrorTypeRateLimit, Code: ErrorCodeQuotaExceeded, Message: "Quota exceeded", Status: http.StatusTooManyRequests}
+	ErrInternal            = &APIError{Type: ErrorTypeInternal, Code: ErrorCodeInternal, Message: "Internal server error", Status: http.StatusInternalServerError}
+	ErrNotImplemented      = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeNotImplemented, Message: "Not implemented", Status: http.StatusNotImplemented}
+	ErrServiceUnavailable  = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeServiceUnavailable, Message: "Service unavailable", Status: http.StatusServiceUnavailable}
+	ErrGatewayTimeout      = &APIError{Type: ErrorTypeTimeout, Code: ErrorCodeGatewayTimeout, Message: "Request timed out", Status: http.StatusGatewayTimeout}
+	ErrClientClosed        = &APIError{Type: ErrorTypeRequest, Code: ErrorCodeClientClosed, Message: "Client closed request", Status: StatusClientClosedRequest}
 )
 
+// StatusClientClosedRequest is the non-standard 499 status (nginx, google.rpc.Code
+// CANCELLED) for requests the client abandoned before a response was written.
+const StatusClientClosedRequest = 499
+
 // NewValidationError creates a validation error with multiple field errors.
 func NewValidationError(errors []FieldError) *APIError {
 	return &APIError{
-		Type:    "validation_error",
-		Code:    "invalid_request",
+		Type:    ErrorTypeValidation,
+		Code:    ErrorCodeInvalidRequest,
 		Message: "Validation failed",
 		Errors:  errors,
 		Status:  http.StatusBadRequest,
 	}
 }
+
+// IsErrorType reports whether apiErr has the given type. Returns false for nil.
+//
+// Example:
+//
+//	if chikit.IsErrorType(apiErr, chikit.ErrorTypeValidation) {
+//		showFieldErrors(apiErr.Errors)
+//	}
+func IsErrorType(apiErr *APIError, t ErrorType) bool {
+	return apiErr != nil && apiErr.Type == t
+}
+
+// apiErrorFrom classifies err as an APIError: an *APIError in the chain is used as-is,
+// context.DeadlineExceeded becomes ErrGatewayTimeout, and anything else ErrInternal.
+func apiErrorFrom(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrGatewayTimeout
+	}
+	return ErrInternal
+}
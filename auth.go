@@ -2,7 +2,9 @@ package chikit
 
 import (
 	"context"
+	"encoding/base64"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -11,6 +13,8 @@ type authContextKey string
 const (
 	apiKeyKey      authContextKey = "api_key"
 	bearerTokenKey authContextKey = "bearer_token"
+	basicUserKey   authContextKey = "basic_user"
+	principalKey   authContextKey = "principal"
 )
 
 // APIKeyValidator validates an API key and returns true if valid.
@@ -21,11 +25,46 @@ const (
 // and must be safe for concurrent use. Avoid shared mutable state.
 type APIKeyValidator func(key string) bool
 
+// APIKeySource reads an API key from a request, returning "" if it is not present.
+// Use APIKeyHeader, APIKeyQuery, and APIKeyAuthorization with WithAPIKeySources.
+type APIKeySource func(r *http.Request) string
+
+// APIKeyHeader returns an APIKeySource that reads the key from the named header.
+func APIKeyHeader(name string) APIKeySource {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// APIKeyQuery returns an APIKeySource that reads the key from the named query parameter.
+func APIKeyQuery(name string) APIKeySource {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(name)
+	}
+}
+
+// APIKeyAuthorization returns an APIKeySource that reads the key from an
+// Authorization header using the given scheme (e.g., "Authorization: ApiKey <key>").
+// The scheme is matched case-insensitively.
+func APIKeyAuthorization(scheme string) APIKeySource {
+	return func(r *http.Request) string {
+		key, _ := authCredentials(r.Header.Get("Authorization"), scheme)
+		return key
+	}
+}
+
 // apiKeyConfig configures the APIKey middleware.
 type apiKeyConfig struct {
 	// Header is the HTTP header to read the API key from (default: "X-API-Key")
 	Header string
 
+	// QueryParam is a query parameter to read the API key from when the header is
+	// empty (default: none)
+	QueryParam string
+
+	// Sources are tried in order, replacing Header and QueryParam (default: none)
+	Sources []APIKeySource
+
 	// Validator is the function that validates the API key
 	Validator APIKeyValidator
 
@@ -34,7 +73,8 @@ type apiKeyConfig struct {
 	Optional bool
 }
 
-// APIKey returns middleware that validates API keys from a header.
+// APIKey returns middleware that validates API keys from a header, and optionally
+// other sources tried in order; the first non-empty value is validated.
 // Returns 401 (Unauthorized) if the key is missing (when required) or invalid.
 // The validated API key is stored in the request context and can be retrieved
 // using APIKeyFromContext.
@@ -50,6 +90,10 @@ type apiKeyConfig struct {
 //
 //	r.Use(chikit.APIKey(validator, chikit.WithAPIKeyHeader("X-Custom-Key")))
 //
+// Header with a query parameter fallback:
+//
+//	r.Use(chikit.APIKey(validator, chikit.WithAPIKeyQueryParam("api_key")))
+//
 // Optional authentication:
 //
 //	r.Use(chikit.APIKey(validator, chikit.WithOptionalAPIKey()))
@@ -64,29 +108,34 @@ func APIKey(validator APIKeyValidator, opts ...APIKeyOption) func(http.Handler)
 		opt(&config)
 	}
 
+	sources := config.Sources
+	if sources == nil {
+		sources = []APIKeySource{APIKeyHeader(config.Header)}
+		if config.QueryParam != "" {
+			sources = append(sources, APIKeyQuery(config.QueryParam))
+		}
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			key := r.Header.Get(config.Header)
+			var key string
+			for _, source := range sources {
+				if key = source(r); key != "" {
+					break
+				}
+			}
 
 			if key == "" {
 				if config.Optional {
 					next.ServeHTTP(w, r)
 					return
 				}
-				if HasState(r.Context()) {
-					SetError(r, ErrUnauthorized.With("Missing API key"))
-				} else {
-					http.Error(w, "Missing API key", http.StatusUnauthorized)
-				}
+				respond(w, r, ErrUnauthorized.With("Missing API key"))
 				return
 			}
 
 			if !config.Validator(key) {
-				if HasState(r.Context()) {
-					SetError(r, ErrUnauthorized.With("Invalid API key"))
-				} else {
-					http.Error(w, "Invalid API key", http.StatusUnauthorized)
-				}
+				respond(w, r, ErrUnauthorized.With("Invalid API key"))
 				return
 			}
 
@@ -107,6 +156,32 @@ func WithAPIKeyHeader(header string) APIKeyOption {
 	}
 }
 
+// WithAPIKeyQueryParam also reads the API key from the named query parameter
+// (e.g., "api_key") when the header is empty. Keys in URLs can end up in access
+// logs and browser history, so prefer headers for new clients.
+func WithAPIKeyQueryParam(name string) APIKeyOption {
+	return func(c *apiKeyConfig) {
+		c.QueryParam = name
+	}
+}
+
+// WithAPIKeySources sets the places the API key is read from, tried in order until
+// one is non-empty. Replaces WithAPIKeyHeader and WithAPIKeyQueryParam. Validation
+// and error responses are the same whichever source supplied the key.
+//
+// Example:
+//
+//	r.Use(chikit.APIKey(validator, chikit.WithAPIKeySources(
+//		chikit.APIKeyHeader("X-API-Key"),
+//		chikit.APIKeyAuthorization("ApiKey"),
+//		chikit.APIKeyQuery("api_key"),
+//	)))
+func WithAPIKeySources(sources ...APIKeySource) APIKeyOption {
+	return func(c *apiKeyConfig) {
+		c.Sources = sources
+	}
+}
+
 // WithOptionalAPIKey makes the API key optional.
 // When set, requests without an API key are allowed through without validation.
 // The API key will not be present in the context for these requests.
@@ -183,40 +258,23 @@ func BearerToken(validator BearerTokenValidator, opts ...BearerTokenOption) func
 					next.ServeHTTP(w, r)
 					return
 				}
-				if HasState(r.Context()) {
-					SetError(r, ErrUnauthorized.With("Missing authorization header"))
-				} else {
-					http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-				}
+				respond(w, r, ErrUnauthorized.With("Missing authorization header"))
 				return
 			}
 
-			// RFC 7235: "Bearer" scheme is case-insensitive
-			if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
-				if HasState(r.Context()) {
-					SetError(r, ErrUnauthorized.With("Invalid authorization format"))
-				} else {
-					http.Error(w, "Invalid authorization format", http.StatusUnauthorized)
-				}
+			token, ok := authCredentials(auth, "Bearer")
+			if !ok {
+				respond(w, r, ErrUnauthorized.With("Invalid authorization format"))
 				return
 			}
 
-			token := auth[7:] // Extract token after "Bearer "
 			if token == "" {
-				if HasState(r.Context()) {
-					SetError(r, ErrUnauthorized.With("Empty bearer token"))
-				} else {
-					http.Error(w, "Empty bearer token", http.StatusUnauthorized)
-				}
+				respond(w, r, ErrUnauthorized.With("Empty bearer token"))
 				return
 			}
 
 			if !config.Validator(token) {
-				if HasState(r.Context()) {
-					SetError(r, ErrUnauthorized.With("Invalid bearer token"))
-				} else {
-					http.Error(w, "Invalid bearer token", http.StatusUnauthorized)
-				}
+				respond(w, r, ErrUnauthorized.With("Invalid bearer token"))
 				return
 			}
 
@@ -253,3 +311,265 @@ func BearerTokenFromContext(ctx context.Context) (string, bool) {
 	token, ok := ctx.Value(bearerTokenKey).(string)
 	return token, ok
 }
+
+// BasicAuthValidator validates a username and password and returns true if valid.
+// Compare secrets with crypto/subtle.ConstantTimeCompare (or a password hash
+// comparison such as bcrypt) rather than ==, so response timing does not reveal
+// how much of a guess matched.
+//
+// Thread safety: Validators are called concurrently from multiple goroutines
+// and must be safe for concurrent use. Avoid shared mutable state.
+type BasicAuthValidator func(user, pass string) bool
+
+// basicAuthConfig configures the BasicAuth middleware.
+type basicAuthConfig struct {
+	// Validator is the function that validates the credentials
+	Validator BasicAuthValidator
+
+	// Realm is sent in the WWW-Authenticate challenge (default: "Restricted")
+	Realm string
+
+	// Optional determines whether credentials are required (default: false)
+	// When true, requests without an Authorization header are allowed through
+	Optional bool
+}
+
+// BasicAuth returns middleware that validates HTTP Basic credentials from the
+// Authorization header. Expects the header format "Basic <base64(user:pass)>".
+// Returns 401 (Unauthorized) with a WWW-Authenticate: Basic challenge if the
+// credentials are missing (when required), malformed, or rejected by the validator,
+// so browsers prompt for a login. The validated username is stored in the request
+// context and can be retrieved using BasicUserFromContext.
+//
+// Basic credentials are sent in clear text; only use BasicAuth over TLS.
+//
+// Example:
+//
+//	validator := func(user, pass string) bool {
+//		return user == "admin" &&
+//			subtle.ConstantTimeCompare([]byte(pass), []byte(adminPassword)) == 1
+//	}
+//	r.Use(chikit.BasicAuth(validator, chikit.WithBasicAuthRealm("Admin")))
+func BasicAuth(validator BasicAuthValidator, opts ...BasicAuthOption) func(http.Handler) http.Handler {
+	config := basicAuthConfig{
+		Validator: validator,
+		Realm:     "Restricted",
+		Optional:  false,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	challenge := `Basic realm=` + strconv.Quote(config.Realm) + `, charset="UTF-8"`
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			unauthorized := func(message string) {
+				if HasState(r.Context()) {
+					SetHeader(r, "WWW-Authenticate", challenge)
+				} else {
+					w.Header().Set("WWW-Authenticate", challenge)
+				}
+				respond(w, r, ErrUnauthorized.With(message))
+			}
+
+			auth := r.Header.Get("Authorization")
+
+			if auth == "" {
+				if config.Optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				unauthorized("Missing authorization header")
+				return
+			}
+
+			encoded, ok := authCredentials(auth, "Basic")
+			if !ok {
+				unauthorized("Invalid authorization format")
+				return
+			}
+
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				unauthorized("Invalid authorization format")
+				return
+			}
+
+			user, pass, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				unauthorized("Invalid authorization format")
+				return
+			}
+
+			if !config.Validator(user, pass) {
+				unauthorized("Invalid credentials")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), basicUserKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BasicAuthOption configures BasicAuth middleware.
+type BasicAuthOption func(*basicAuthConfig)
+
+// WithBasicAuthRealm sets the realm sent in the WWW-Authenticate challenge.
+// Default is "Restricted".
+func WithBasicAuthRealm(realm string) BasicAuthOption {
+	return func(c *basicAuthConfig) {
+		c.Realm = realm
+	}
+}
+
+// WithOptionalBasicAuth makes Basic credentials optional.
+// When set, requests without an Authorization header are allowed through without validation.
+// The username will not be present in the context for these requests.
+func WithOptionalBasicAuth() BasicAuthOption {
+	return func(c *basicAuthConfig) {
+		c.Optional = true
+	}
+}
+
+// BasicUserFromContext retrieves the username validated by BasicAuth from the request context.
+// Returns the username and true if present, or empty string and false if not present.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		if user, ok := chikit.BasicUserFromContext(r.Context()); ok {
+//			log.Printf("User: %s", user)
+//		}
+//	}
+func BasicUserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(basicUserKey).(string)
+	return user, ok
+}
+
+// authCredentials extracts the credentials from an Authorization header value of the
+// form "<scheme> <credentials>". Per RFC 7235 the scheme is matched case-insensitively.
+// Returns false if the header does not use the given scheme.
+func authCredentials(auth, scheme string) (string, bool) {
+	n := len(scheme)
+	if len(auth) <= n || auth[n] != ' ' || !strings.EqualFold(auth[:n], scheme) {
+		return "", false
+	}
+	return auth[n+1:], true
+}
+
+// SchemeValidator validates the credentials of an Authorization header and returns
+// the authenticated principal. The principal can be any value (user ID, claims struct,
+// etc.) and is stored in the request context on success.
+//
+// Thread safety: Validators are called concurrently from multiple goroutines
+// and must be safe for concurrent use. Avoid shared mutable state.
+type SchemeValidator func(credentials string) (principal any, ok bool)
+
+// authSchemeConfig configures the AuthScheme middleware.
+type authSchemeConfig struct {
+	// Optional determines whether the Authorization header is required (default: false)
+	// When true, requests without an Authorization header are allowed through
+	Optional bool
+}
+
+// AuthSchemeOption configures AuthScheme middleware.
+type AuthSchemeOption func(*authSchemeConfig)
+
+// WithOptionalAuthScheme makes the Authorization header optional.
+// When set, requests without an Authorization header are allowed through without validation.
+// No principal will be present in the context for these requests.
+func WithOptionalAuthScheme() AuthSchemeOption {
+	return func(c *authSchemeConfig) {
+		c.Optional = true
+	}
+}
+
+// AuthScheme returns middleware that validates an Authorization header using an
+// arbitrary scheme. Expects the header format "<scheme> <credentials>", with the
+// scheme matched case-insensitively. Returns 401 (Unauthorized) if the header is
+// missing (when required), uses a different scheme, has empty credentials, or the
+// validator rejects the credentials. The principal returned by the validator is
+// stored in the request context and can be retrieved using PrincipalFromContext.
+//
+// Use this for custom schemes without writing new middleware:
+//
+//	validator := func(creds string) (any, bool) {
+//		keyID, ok := verifyHMAC(creds)
+//		return keyID, ok
+//	}
+//	r.Use(chikit.AuthScheme("HMAC", validator))
+//
+// Optional authentication:
+//
+//	r.Use(chikit.AuthScheme("Signature", validator, chikit.WithOptionalAuthScheme()))
+func AuthScheme(scheme string, validator SchemeValidator, opts ...AuthSchemeOption) func(http.Handler) http.Handler {
+	if scheme == "" {
+		panic("AuthScheme: scheme must be non-empty")
+	}
+
+	config := authSchemeConfig{
+		Optional: false,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+
+			if auth == "" {
+				if config.Optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				respond(w, r, ErrUnauthorized.With("Missing authorization header"))
+				return
+			}
+
+			creds, ok := authCredentials(auth, scheme)
+			if !ok {
+				respond(w, r, ErrUnauthorized.With("Invalid authorization format"))
+				return
+			}
+
+			if creds == "" {
+				respond(w, r, ErrUnauthorized.With("Empty credentials"))
+				return
+			}
+
+			principal, ok := validator(creds)
+			if !ok {
+				respond(w, r, ErrUnauthorized.With("Invalid credentials"))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), principalKey, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// PrincipalFromContext retrieves the principal stored by AuthScheme from the request context.
+// Returns the principal and true if present, or nil and false if not present.
+// The returned value should be type-asserted to the type returned by the validator.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		if p, ok := chikit.PrincipalFromContext(r.Context()); ok {
+//			keyID := p.(string)
+//			log.Printf("Signed by key: %s", keyID)
+//		}
+//	}
+func PrincipalFromContext(ctx context.Context) (any, bool) {
+	principal := ctx.Value(principalKey)
+	if principal == nil {
+		return nil, false
+	}
+	return principal, true
+}
@@ -1,6 +1,8 @@
 package chikit
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -190,6 +192,63 @@ func TestAPIKey_OptionalWithMissingKey(t *testing.T) {
 	}
 }
 
+func TestAPIKey_Sources(t *testing.T) {
+	validator := func(key string) bool {
+		return key == "secret-key"
+	}
+
+	tests := []struct {
+		name        string
+		opts        []APIKeyOption
+		target      string
+		headers     map[string]string
+		wantStatus  int
+		wantMessage string
+	}{
+		{"query param fallback", []APIKeyOption{WithAPIKeyQueryParam("api_key")}, "/?api_key=secret-key", nil, http.StatusOK, ""},
+		{"header before query param", []APIKeyOption{WithAPIKeyQueryParam("api_key")}, "/?api_key=wrong", map[string]string{"X-API-Key": "secret-key"}, http.StatusOK, ""},
+		{"query param not read by default", nil, "/?api_key=secret-key", nil, http.StatusUnauthorized, "Missing API key"},
+		{"invalid key from query param", []APIKeyOption{WithAPIKeyQueryParam("api_key")}, "/?api_key=wrong", nil, http.StatusUnauthorized, "Invalid API key"},
+		{"authorization source", []APIKeyOption{WithAPIKeySources(APIKeyHeader("X-API-Key"), APIKeyAuthorization("ApiKey"))}, "/", map[string]string{"Authorization": "apikey secret-key"}, http.StatusOK, ""},
+		{"first non-empty source wins", []APIKeyOption{WithAPIKeySources(APIKeyQuery("key"), APIKeyHeader("X-API-Key"))}, "/?key=wrong", map[string]string{"X-API-Key": "secret-key"}, http.StatusUnauthorized, "Invalid API key"},
+		{"sources replace default header", []APIKeyOption{WithAPIKeySources(APIKeyQuery("key"))}, "/", map[string]string{"X-API-Key": "secret-key"}, http.StatusUnauthorized, "Missing API key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var key string
+			handler := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				key, _ = APIKeyFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest("GET", tt.target, http.NoBody)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			Handler()(APIKey(validator, tt.opts...)(handler)).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if key != "secret-key" {
+					t.Errorf("expected key in context, got %q", key)
+				}
+				return
+			}
+			var resp map[string]APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["error"].Message != tt.wantMessage {
+				t.Errorf("expected message %q, got %q", tt.wantMessage, resp["error"].Message)
+			}
+		})
+	}
+}
+
 func TestBearerToken_Valid(t *testing.T) {
 	validator := func(token string) bool {
 		return token == "valid-token"
@@ -544,3 +603,221 @@ func TestBearerToken_CaseInsensitive(t *testing.T) {
 		}
 	}
 }
+
+type testPrincipal struct {
+	KeyID string
+}
+
+func hmacValidator(creds string) (any, bool) {
+	if creds == "key1:signature" {
+		return testPrincipal{KeyID: "key1"}, true
+	}
+	return nil, false
+}
+
+func TestAuthScheme_Valid(t *testing.T) {
+	var principal any
+	var found bool
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, found = PrincipalFromContext(r.Context())
+		w.Write([]byte("ok"))
+	})
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Authorization", "hmac key1:signature")
+	rec := httptest.NewRecorder()
+
+	AuthScheme("HMAC", hmacValidator)(handler).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if !found {
+		t.Fatal("expected principal in context")
+	}
+	if p, ok := principal.(testPrincipal); !ok || p.KeyID != "key1" {
+		t.Errorf("expected principal with KeyID key1, got %#v", principal)
+	}
+}
+
+func TestAuthScheme_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		message string
+	}{
+		{"missing header", "", "Missing authorization header"},
+		{"wrong scheme", "Bearer key1:signature", "Invalid authorization format"},
+		{"scheme prefix only", "HMACX key1:signature", "Invalid authorization format"},
+		{"empty credentials", "HMAC ", "Empty credentials"},
+		{"invalid credentials", "HMAC key1:forged", "Invalid credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled := false
+			handler := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				handlerCalled = true
+			})
+
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			Handler()(AuthScheme("HMAC", hmacValidator)(handler)).ServeHTTP(rec, req)
+
+			if handlerCalled {
+				t.Error("handler should not be called")
+			}
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("expected status 401, got %d", rec.Code)
+			}
+
+			var resp map[string]APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["error"].Message != tt.message {
+				t.Errorf("expected message %q, got %q", tt.message, resp["error"].Message)
+			}
+		})
+	}
+}
+
+func TestAuthScheme_Optional(t *testing.T) {
+	var found bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, found = PrincipalFromContext(r.Context())
+		w.Write([]byte("ok"))
+	})
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	AuthScheme("HMAC", hmacValidator, WithOptionalAuthScheme())(handler).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if found {
+		t.Error("expected no principal in context")
+	}
+}
+
+func basicValidator(user, pass string) bool {
+	return user == "admin" && subtle.ConstantTimeCompare([]byte(pass), []byte("s3cret:pass")) == 1
+}
+
+func basicHeader(creds string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
+}
+
+func TestBasicAuth_Valid(t *testing.T) {
+	var user string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ = BasicUserFromContext(r.Context())
+		w.Write([]byte("ok"))
+	})
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Authorization", basicHeader("admin:s3cret:pass"))
+	rec := httptest.NewRecorder()
+
+	BasicAuth(basicValidator)(handler).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if user != "admin" {
+		t.Errorf("expected user admin, got %q", user)
+	}
+	if rec.Header().Get("WWW-Authenticate") != "" {
+		t.Error("expected no challenge on success")
+	}
+}
+
+func TestBasicAuth_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		message string
+	}{
+		{"missing header", "", "Missing authorization header"},
+		{"wrong scheme", "Bearer token", "Invalid authorization format"},
+		{"invalid base64", "Basic not-base64!", "Invalid authorization format"},
+		{"missing colon", basicHeader("admin"), "Invalid authorization format"},
+		{"wrong password", basicHeader("admin:wrong"), "Invalid credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled := false
+			handler := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				handlerCalled = true
+			})
+
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			Handler()(BasicAuth(basicValidator, WithBasicAuthRealm("Admin"))(handler)).ServeHTTP(rec, req)
+
+			if handlerCalled {
+				t.Error("handler should not be called")
+			}
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("expected status 401, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != `Basic realm="Admin", charset="UTF-8"` {
+				t.Errorf("unexpected challenge %q", got)
+			}
+
+			var resp map[string]APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["error"].Message != tt.message {
+				t.Errorf("expected message %q, got %q", tt.message, resp["error"].Message)
+			}
+		})
+	}
+}
+
+func TestBasicAuth_WithoutWrapper(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	BasicAuth(basicValidator)(http.NotFoundHandler()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("WWW-Authenticate"); got != `Basic realm="Restricted", charset="UTF-8"` {
+		t.Errorf("unexpected challenge %q", got)
+	}
+}
+
+func TestBasicAuth_Optional(t *testing.T) {
+	var found bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, found = BasicUserFromContext(r.Context())
+		w.Write([]byte("ok"))
+	})
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	BasicAuth(basicValidator, WithOptionalBasicAuth())(handler).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if found {
+		t.Error("expected no user in context")
+	}
+}
@@ -0,0 +1,155 @@
+package chikit
+
+// In-memory read-through caching for API key and bearer token validators,
+// so validators backed by a database are not called on every request.
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// DefaultValidatorCacheMaxEntries is the default maximum number of cached validation results.
+const DefaultValidatorCacheMaxEntries = 10000
+
+type validatorCacheConfig struct {
+	negativeTTL time.Duration
+	maxEntries  int
+	now         func() time.Time
+}
+
+// ValidatorCacheOption configures CachedValidator and CachedBearerValidator.
+type ValidatorCacheOption func(*validatorCacheConfig)
+
+// ValidatorCacheWithNegativeTTL also caches failed validations for d, shielding the
+// inner validator from repeated invalid credentials. Keep d short: it is how long a
+// newly issued key can be rejected after a miss. Default is 0 (failures are not cached).
+func ValidatorCacheWithNegativeTTL(d time.Duration) ValidatorCacheOption {
+	return func(c *validatorCacheConfig) {
+		c.negativeTTL = d
+	}
+}
+
+// ValidatorCacheWithMaxEntries bounds the number of cached results. When full, expired
+// entries are purged and new results are not cached until space frees up.
+// Default is DefaultValidatorCacheMaxEntries.
+func ValidatorCacheWithMaxEntries(n int) ValidatorCacheOption {
+	return func(c *validatorCacheConfig) {
+		c.maxEntries = n
+	}
+}
+
+// ValidatorCacheWithClock sets the function used to read the current time.
+// Default is time.Now. Intended for tests.
+func ValidatorCacheWithClock(now func() time.Time) ValidatorCacheOption {
+	return func(c *validatorCacheConfig) {
+		c.now = now
+	}
+}
+
+// CachedValidator wraps inner so successful validations are cached in memory for
+// ttl. Results are keyed by the SHA-256 of the key, so raw keys are not retained.
+// Safe for concurrent use; concurrent misses for the same key may each call inner.
+//
+// A revoked key keeps validating until its cached result expires, so choose ttl
+// as the longest acceptable revocation delay.
+//
+// Example:
+//
+//	validator := chikit.CachedValidator(lookupAPIKey, time.Minute,
+//		chikit.ValidatorCacheWithNegativeTTL(5*time.Second),
+//	)
+//	r.Use(chikit.APIKey(validator))
+func CachedValidator(inner APIKeyValidator, ttl time.Duration, opts ...ValidatorCacheOption) APIKeyValidator {
+	return newValidatorCache(inner, ttl, opts).validate
+}
+
+// CachedBearerValidator is CachedValidator for bearer tokens.
+func CachedBearerValidator(inner BearerTokenValidator, ttl time.Duration, opts ...ValidatorCacheOption) BearerTokenValidator {
+	return newValidatorCache(inner, ttl, opts).validate
+}
+
+type validatorCacheEntry struct {
+	valid   bool
+	expires time.Time
+}
+
+type validatorCache struct {
+	inner   func(string) bool
+	ttl     time.Duration
+	cfg     *validatorCacheConfig
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]validatorCacheEntry
+
+	// nextExpiry is no later than the earliest entry expiry, so a full cache is
+	// only swept once something may have expired.
+	nextExpiry time.Time
+}
+
+func newValidatorCache(inner func(string) bool, ttl time.Duration, opts []ValidatorCacheOption) *validatorCache {
+	cfg := &validatorCacheConfig{maxEntries: DefaultValidatorCacheMaxEntries, now: time.Now}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &validatorCache{
+		inner:   inner,
+		ttl:     ttl,
+		cfg:     cfg,
+		entries: make(map[[sha256.Size]byte]validatorCacheEntry),
+	}
+}
+
+func (c *validatorCache) validate(credential string) bool {
+	id := sha256.Sum256([]byte(credential))
+
+	c.mu.Lock()
+	entry, ok := c.entries[id]
+	c.mu.Unlock()
+	if ok && c.cfg.now().Before(entry.expires) {
+		return entry.valid
+	}
+
+	valid := c.inner(credential)
+
+	ttl := c.ttl
+	if !valid {
+		ttl = c.cfg.negativeTTL
+	}
+	if ttl <= 0 {
+		return valid
+	}
+
+	now := c.cfg.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[id]; !exists && len(c.entries) >= c.cfg.maxEntries {
+		if now.Before(c.nextExpiry) {
+			return valid
+		}
+		c.purgeExpired(now)
+		if len(c.entries) >= c.cfg.maxEntries {
+			return valid
+		}
+	}
+	expires := now.Add(ttl)
+	if len(c.entries) == 0 || expires.Before(c.nextExpiry) {
+		c.nextExpiry = expires
+	}
+	c.entries[id] = validatorCacheEntry{valid: valid, expires: expires}
+	return valid
+}
+
+// purgeExpired deletes entries expired at now and recomputes nextExpiry from
+// the rest. Must be called with c.mu held.
+func (c *validatorCache) purgeExpired(now time.Time) {
+	c.nextExpiry = time.Time{}
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+			continue
+		}
+		if c.nextExpiry.IsZero() || e.expires.Before(c.nextExpiry) {
+			c.nextExpiry = e.expires
+		}
+	}
+}
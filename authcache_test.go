@@ -0,0 +1,119 @@
+package chikit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCachedValidator_TTLAndRevocation(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	revoked := false
+	validator := CachedValidator(func(key string) bool {
+		calls++
+		return key == "valid-key" && !revoked
+	}, time.Minute, ValidatorCacheWithClock(func() time.Time { return now }))
+
+	for range 3 {
+		if !validator("valid-key") {
+			t.Fatal("expected key to validate")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 inner call within TTL, got %d", calls)
+	}
+
+	revoked = true
+	now = now.Add(time.Minute + time.Second)
+	if validator("valid-key") {
+		t.Error("expected revocation to be honored after TTL")
+	}
+	if calls != 2 {
+		t.Errorf("expected inner call after expiry, got %d calls", calls)
+	}
+}
+
+func TestCachedValidator_NegativeTTL(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	issued := false
+	validator := CachedValidator(func(string) bool {
+		calls++
+		return issued
+	}, time.Minute,
+		ValidatorCacheWithNegativeTTL(5*time.Second),
+		ValidatorCacheWithClock(func() time.Time { return now }),
+	)
+
+	validator("new-key")
+	validator("new-key")
+	if calls != 1 {
+		t.Errorf("expected failure to be cached, got %d inner calls", calls)
+	}
+
+	issued = true
+	if validator("new-key") {
+		t.Error("expected cached failure within negative TTL")
+	}
+
+	now = now.Add(6 * time.Second)
+	if !validator("new-key") {
+		t.Error("expected key to validate after negative TTL")
+	}
+}
+
+func TestCachedValidator_NoNegativeCachingByDefault(t *testing.T) {
+	calls := 0
+	validator := CachedValidator(func(string) bool {
+		calls++
+		return false
+	}, time.Minute)
+
+	validator("bad-key")
+	validator("bad-key")
+	if calls != 2 {
+		t.Errorf("expected failures to reach inner validator, got %d calls", calls)
+	}
+}
+
+func TestCachedBearerValidator_MaxEntries(t *testing.T) {
+	calls := 0
+	validator := CachedBearerValidator(func(string) bool {
+		calls++
+		return true
+	}, time.Minute, ValidatorCacheWithMaxEntries(1))
+
+	validator("token-a")
+	validator("token-b")
+	validator("token-a")
+	validator("token-b")
+	if calls != 3 {
+		t.Errorf("expected only the first token cached, got %d inner calls", calls)
+	}
+}
+
+func TestCachedBearerValidator_MaxEntriesEvictsExpired(t *testing.T) {
+	now := time.Now()
+	calls := map[string]int{}
+	validator := CachedBearerValidator(func(token string) bool {
+		calls[token]++
+		return true
+	}, time.Minute, ValidatorCacheWithMaxEntries(2), ValidatorCacheWithClock(func() time.Time { return now }))
+
+	validator("token-a")
+	now = now.Add(30 * time.Second)
+	validator("token-b")
+	validator("token-c") // full, nothing expired yet
+	validator("token-c")
+	if calls["token-c"] != 2 {
+		t.Errorf("expected token-c uncached while full, got %d inner calls", calls["token-c"])
+	}
+
+	now = now.Add(31 * time.Second) // token-a expired, token-b still cached
+	validator("token-c")
+	validator("token-c")
+	validator("token-b")
+	if calls["token-c"] != 3 || calls["token-b"] != 1 {
+		t.Errorf("expected token-a evicted for token-c, got calls %v", calls)
+	}
+}
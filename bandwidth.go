@@ -0,0 +1,205 @@
+package chikit
+
+// Response bandwidth throttling.
+// Limits the rate at which response bodies are written so a single download
+// cannot saturate egress from a constrained backend.
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// bandwidthMaxChunk caps the bytes written per throttled write so output is paced
+// smoothly instead of in one-second bursts.
+const bandwidthMaxChunk = 16 << 10
+
+type bandwidthConfig struct {
+	keyFn func(*http.Request) string
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// BandwidthOption configures BandwidthLimit middleware.
+type BandwidthOption func(*bandwidthConfig)
+
+// BandwidthWithKey shares the byte budget across all concurrent responses whose
+// requests return the same key (e.g., client IP or API key), so a client cannot
+// bypass the limit by opening parallel downloads. Requests where fn returns "" get
+// their own budget. Budgets are released when the key has no responses in flight.
+func BandwidthWithKey(fn func(*http.Request) string) BandwidthOption {
+	return func(c *bandwidthConfig) {
+		c.keyFn = fn
+	}
+}
+
+// bandwidthWithClock replaces the time source and sleep function, for tests.
+func bandwidthWithClock(now func() time.Time, sleep func(context.Context, time.Duration) error) BandwidthOption {
+	return func(c *bandwidthConfig) {
+		c.now = now
+		c.sleep = sleep
+	}
+}
+
+// sleepContext waits for d or until ctx is done, returning ctx.Err() if cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BandwidthLimit returns middleware that throttles response bodies to bytesPerSec
+// using a token bucket on bytes. Up to one second of data may be written at once;
+// after that, writes block until the budget refills. Headers are not delayed.
+//
+// Streaming works as usual: flushes pass through to the underlying writer (directly
+// or via http.ResponseController). If the request context is cancelled while a
+// write is waiting, the write returns the context error.
+//
+// Place BandwidthLimit before Handler so the buffered response Handler writes is
+// throttled. Responses are limited individually unless BandwidthWithKey is used.
+//
+// Example:
+//
+//	r.Use(chikit.BandwidthLimit(512<<10, chikit.BandwidthWithKey(func(r *http.Request) string {
+//		return r.RemoteAddr
+//	})))
+//	r.Use(chikit.Handler())
+func BandwidthLimit(bytesPerSec int64, opts ...BandwidthOption) func(http.Handler) http.Handler {
+	if bytesPerSec <= 0 {
+		panic("BandwidthLimit: bytesPerSec must be positive")
+	}
+	cfg := &bandwidthConfig{now: time.Now, sleep: sleepContext}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var mu sync.Mutex
+	shared := make(map[string]*sharedBandwidthBucket)
+
+	acquire := func(key string) *bandwidthBucket {
+		if key == "" {
+			return newBandwidthBucket(bytesPerSec, cfg.now())
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		sb, ok := shared[key]
+		if !ok {
+			sb = &sharedBandwidthBucket{bucket: newBandwidthBucket(bytesPerSec, cfg.now())}
+			shared[key] = sb
+		}
+		sb.refs++
+		return sb.bucket
+	}
+	release := func(key string) {
+		if key == "" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if sb := shared[key]; sb != nil {
+			sb.refs--
+			if sb.refs == 0 {
+				delete(shared, key)
+			}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var key string
+			if cfg.keyFn != nil {
+				key = cfg.keyFn(r)
+			}
+			bucket := acquire(key)
+			defer release(key)
+
+			next.ServeHTTP(&bandwidthWriter{
+				ResponseWriter: w,
+				ctx:            r.Context(),
+				bucket:         bucket,
+				cfg:            cfg,
+			}, r)
+		})
+	}
+}
+
+// bandwidthBucket is a token bucket measured in bytes. Reservations may take it
+// negative, so concurrent writers sharing a bucket queue fairly behind each other.
+type bandwidthBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+type sharedBandwidthBucket struct {
+	bucket *bandwidthBucket
+	refs   int
+}
+
+func newBandwidthBucket(bytesPerSec int64, now time.Time) *bandwidthBucket {
+	rate := float64(bytesPerSec)
+	return &bandwidthBucket{rate: rate, burst: rate, tokens: rate, last: now}
+}
+
+// reserve takes n bytes from the bucket and returns how long to wait before writing them.
+func (b *bandwidthBucket) reserve(n int, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// bandwidthWriter paces writes to the underlying ResponseWriter.
+type bandwidthWriter struct {
+	http.ResponseWriter
+	ctx    context.Context
+	bucket *bandwidthBucket
+	cfg    *bandwidthConfig
+}
+
+func (w *bandwidthWriter) Write(b []byte) (int, error) {
+	chunkSize := int(min(w.bucket.burst, bandwidthMaxChunk))
+	written := 0
+	for len(b) > 0 {
+		chunk := b[:min(len(b), chunkSize)]
+		if wait := w.bucket.reserve(len(chunk), w.cfg.now()); wait > 0 {
+			if err := w.cfg.sleep(w.ctx, wait); err != nil {
+				return written, err
+			}
+		}
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// Flush flushes the underlying ResponseWriter, if it supports flushing.
+func (w *bandwidthWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *bandwidthWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
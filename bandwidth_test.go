@@ -0,0 +1,138 @@
+package chikit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBandwidthClock advances time only when sleep is called.
+type fakeBandwidthClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeBandwidthClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeBandwidthClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.slept += d
+	return nil
+}
+
+func TestBandwidthLimit_PacesResponse(t *testing.T) {
+	clock := &fakeBandwidthClock{now: time.Unix(1700000000, 0)}
+	body := bytes.Repeat([]byte("x"), 10000)
+
+	handler := BandwidthLimit(1000, bandwidthWithClock(clock.Now, clock.Sleep))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write(body)
+		}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/download", http.NoBody))
+
+	if !bytes.Equal(rec.Body.Bytes(), body) {
+		t.Fatalf("expected full body of %d bytes, got %d", len(body), rec.Body.Len())
+	}
+	// The first second of data is written immediately; the remaining 9000 bytes at 1000 B/s.
+	if clock.slept != 9*time.Second {
+		t.Errorf("expected 9s of throttling, got %v", clock.slept)
+	}
+}
+
+func TestBandwidthLimit_SharedKey(t *testing.T) {
+	clock := &fakeBandwidthClock{now: time.Unix(1700000000, 0)}
+	mw := BandwidthLimit(1000,
+		BandwidthWithKey(func(r *http.Request) string { return r.Header.Get("X-Client") }),
+		bandwidthWithClock(clock.Now, clock.Sleep),
+	)
+
+	// The outer response holds the key while the inner one shares its budget.
+	inner := mw(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(bytes.Repeat([]byte("x"), 1000))
+	}))
+	outer := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("x"), 1000))
+		inner.ServeHTTP(httptest.NewRecorder(), r)
+	}))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("X-Client", "client-1")
+	outer.ServeHTTP(httptest.NewRecorder(), req)
+
+	if clock.slept != time.Second {
+		t.Errorf("expected concurrent responses for one key to share the budget (1s wait), got %v", clock.slept)
+	}
+}
+
+func TestBandwidthLimit_CancelledContext(t *testing.T) {
+	clock := &fakeBandwidthClock{now: time.Unix(1700000000, 0)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var writeErr error
+	handler := BandwidthLimit(100, bandwidthWithClock(clock.Now, clock.Sleep))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			cancel()
+			_, writeErr = w.Write(bytes.Repeat([]byte("x"), 1000))
+		}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody).WithContext(ctx))
+
+	if !errors.Is(writeErr, context.Canceled) {
+		t.Errorf("expected write to stop with context.Canceled, got %v", writeErr)
+	}
+	if rec.Body.Len() != 100 {
+		t.Errorf("expected only the initial burst to be written, got %d bytes", rec.Body.Len())
+	}
+}
+
+func TestBandwidthLimit_Flush(t *testing.T) {
+	handler := BandwidthLimit(1 << 20)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("chunk"))
+		w.(http.Flusher).Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+	if !rec.Flushed {
+		t.Error("expected flush to reach the underlying writer")
+	}
+}
+
+func TestBandwidthLimit_ThrottlesHandlerResponse(t *testing.T) {
+	clock := &fakeBandwidthClock{now: time.Unix(1700000000, 0)}
+	payload := string(bytes.Repeat([]byte("x"), 3000))
+
+	handler := BandwidthLimit(1000, bandwidthWithClock(clock.Now, clock.Sleep))(
+		Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			SetResponse(r, http.StatusOK, map[string]string{"data": payload})
+		})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if clock.slept < 2*time.Second {
+		t.Errorf("expected Handler's buffered response to be throttled, slept %v", clock.slept)
+	}
+}
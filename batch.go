@@ -0,0 +1,219 @@
+package chikit
+
+// Batch endpoint for dispatching multiple sub-requests in one HTTP call.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nhalm/canonlog"
+)
+
+// DefaultBatchMaxSize is the default maximum number of sub-requests per batch.
+const DefaultBatchMaxSize = 20
+
+// BatchRequest is a single sub-request in a batch.
+type BatchRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchResponse is the result of a single sub-request in a batch.
+// Body holds the sub-response as JSON; non-JSON bodies are encoded as a JSON string.
+type BatchResponse struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type batchConfig struct {
+	maxSize int
+}
+
+// BatchOption configures the Batch handler.
+type BatchOption func(*batchConfig)
+
+// BatchWithMaxSize sets the maximum number of sub-requests per batch.
+// Default is DefaultBatchMaxSize.
+func BatchWithMaxSize(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.maxSize = n
+	}
+}
+
+// Batch returns a handler that accepts a JSON array of sub-requests, dispatches each
+// against handler, and responds with a JSON array of results in the same order.
+//
+// Each sub-request is a synthetic request that inherits the parent's context, headers
+// (e.g., Authorization), host, and remote address. Sub-requests run sequentially and
+// are isolated: a failing or panicking sub-request yields its own error status without
+// failing the batch. The batch itself returns 200 unless the envelope is invalid.
+//
+// Returns 400 (Bad Request) if the body is not a JSON array, is empty, or exceeds
+// the maximum batch size. Returns 413 if MaxBodySize is exceeded.
+//
+// Example:
+//
+//	r := chi.NewRouter()
+//	r.Use(chikit.Handler())
+//	r.Get("/users/{id}", getUser)
+//	r.Post("/users", createUser)
+//	r.Post("/batch", chikit.Batch(r, chikit.BatchWithMaxSize(10)).ServeHTTP)
+//
+// Request:
+//
+//	[{"method": "GET", "path": "/users/1"}, {"method": "POST", "path": "/users", "body": {"name": "Ada"}}]
+//
+// Response:
+//
+//	[{"status": 200, "body": {"id": "1"}}, {"status": 400, "body": {"error": {...}}}]
+func Batch(handler http.Handler, opts ...BatchOption) http.Handler {
+	cfg := &batchConfig{maxSize: DefaultBatchMaxSize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		useWrapper := HasState(r.Context())
+
+		var reqs []BatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respond(w, r, ErrPayloadTooLarge.With("Request body too large"))
+			} else {
+				respond(w, r, ErrBadRequest.With("Batch body must be a JSON array of requests"))
+			}
+			return
+		}
+		if len(reqs) == 0 {
+			respond(w, r, ErrBadRequest.With("Batch must contain at least one request"))
+			return
+		}
+		if len(reqs) > cfg.maxSize {
+			respond(w, r, ErrBadRequest.With(fmt.Sprintf("Batch exceeds maximum size of %d", cfg.maxSize)))
+			return
+		}
+
+		ctx := r.Context()
+		if _, ok := canonlog.TryGetLogger(ctx); ok {
+			canonlog.InfoAdd(ctx, "batch_size", len(reqs))
+		}
+
+		results := make([]BatchResponse, len(reqs))
+		for i, sub := range reqs {
+			results[i] = dispatchBatchRequest(handler, r, sub)
+		}
+
+		if useWrapper {
+			SetResponse(r, http.StatusOK, results)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(results)
+	})
+}
+
+// dispatchBatchRequest runs a single sub-request and captures its response.
+func dispatchBatchRequest(handler http.Handler, parent *http.Request, sub BatchRequest) (result BatchResponse) {
+	if !strings.HasPrefix(sub.Path, "/") {
+		return batchErrorResponse(ErrBadRequest.With("Sub-request path must start with /"))
+	}
+	method := sub.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	// Shadow the parent's state so a sub-handler without its own Handler wrapper
+	// cannot write into the batch response, and the parent's chi route context so
+	// a router dispatches the sub-request from the top instead of as a sub-route.
+	ctx := context.WithValue(parent.Context(), stateKey, (*State)(nil))
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, (*chi.Context)(nil))
+
+	hasBody := len(sub.Body) > 0 && !bytes.Equal(sub.Body, []byte("null"))
+	var body io.Reader = http.NoBody
+	if hasBody {
+		body = bytes.NewReader(sub.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), sub.Path, body)
+	if err != nil {
+		return batchErrorResponse(ErrBadRequest.With("Invalid sub-request"))
+	}
+	req.Header = parent.Header.Clone()
+	req.Header.Del("Content-Length")
+	if hasBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Host = parent.Host
+	req.RemoteAddr = parent.RemoteAddr
+	req.RequestURI = sub.Path
+
+	rw := &batchRecorder{header: make(http.Header)}
+	defer func() {
+		if rec := recover(); rec != nil {
+			if _, ok := canonlog.TryGetLogger(ctx); ok {
+				canonlog.ErrorAdd(ctx, fmt.Errorf("batch sub-request %s %s panic: %v", req.Method, sub.Path, rec))
+			}
+			result = batchErrorResponse(ErrInternal)
+		}
+	}()
+	handler.ServeHTTP(rw, req)
+
+	return rw.result()
+}
+
+func batchErrorResponse(apiErr *APIError) BatchResponse {
+	body, _ := json.Marshal(errorResponse{Error: apiErr})
+	return BatchResponse{Status: apiErr.Status, Body: body}
+}
+
+// batchRecorder captures a sub-response in memory.
+type batchRecorder struct {
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rec *batchRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *batchRecorder) WriteHeader(code int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+	rec.status = code
+}
+
+func (rec *batchRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	return rec.body.Write(b)
+}
+
+func (rec *batchRecorder) result() BatchResponse {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	body := bytes.TrimSpace(rec.body.Bytes())
+	if len(body) == 0 {
+		return BatchResponse{Status: status}
+	}
+	if json.Valid(body) {
+		return BatchResponse{Status: status, Body: body}
+	}
+	encoded, _ := json.Marshal(string(body))
+	return BatchResponse{Status: status, Body: encoded}
+}
@@ -0,0 +1,189 @@
+package chikit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newBatchRouter(opts ...BatchOption) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(Handler())
+	r.Get("/users/{id}", func(_ http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if id != "1" {
+			SetError(r, ErrNotFound.With("User not found"))
+			return
+		}
+		SetResponse(r, http.StatusOK, map[string]string{"id": id, "auth": r.Header.Get("Authorization")})
+	})
+	r.Post("/users", func(_ http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			SetError(r, ErrBadRequest)
+			return
+		}
+		SetResponse(r, http.StatusCreated, map[string]string{"name": body.Name})
+	})
+	r.Get("/panic", func(_ http.ResponseWriter, _ *http.Request) {
+		panic("boom")
+	})
+	r.Get("/text", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("plain"))
+	})
+	r.Post("/batch", Batch(r, opts...).ServeHTTP)
+	return r
+}
+
+func doBatch(t *testing.T, handler http.Handler, body string) (*httptest.ResponseRecorder, []BatchResponse) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var results []BatchResponse
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+			t.Fatalf("failed to decode batch response: %v", err)
+		}
+	}
+	return rec, results
+}
+
+func TestBatch_IndependentStatuses(t *testing.T) {
+	router := newBatchRouter()
+
+	rec, results := doBatch(t, router, `[
+		{"method": "GET", "path": "/users/1"},
+		{"method": "GET", "path": "/users/2"},
+		{"method": "POST", "path": "/users", "body": {"name": "Ada"}}
+	]`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	expected := []int{http.StatusOK, http.StatusNotFound, http.StatusCreated}
+	for i, status := range expected {
+		if results[i].Status != status {
+			t.Errorf("result %d: expected status %d, got %d", i, status, results[i].Status)
+		}
+	}
+
+	var user map[string]string
+	if err := json.Unmarshal(results[0].Body, &user); err != nil {
+		t.Fatalf("failed to decode first body: %v", err)
+	}
+	if user["auth"] != "Bearer token" {
+		t.Errorf("expected parent Authorization header to be forwarded, got %q", user["auth"])
+	}
+
+	var notFound map[string]*APIError
+	if err := json.Unmarshal(results[1].Body, &notFound); err != nil {
+		t.Fatalf("failed to decode second body: %v", err)
+	}
+	if notFound["error"].Message != "User not found" {
+		t.Errorf("expected not found error, got %v", notFound["error"])
+	}
+
+	var created map[string]string
+	if err := json.Unmarshal(results[2].Body, &created); err != nil {
+		t.Fatalf("failed to decode third body: %v", err)
+	}
+	if created["name"] != "Ada" {
+		t.Errorf("expected name Ada, got %q", created["name"])
+	}
+}
+
+func TestBatch_SubRequestIsolation(t *testing.T) {
+	router := newBatchRouter()
+
+	rec, results := doBatch(t, router, `[
+		{"method": "GET", "path": "/panic"},
+		{"method": "GET", "path": "users/1"},
+		{"path": "/text"}
+	]`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	expected := []int{http.StatusInternalServerError, http.StatusBadRequest, http.StatusOK}
+	for i, status := range expected {
+		if results[i].Status != status {
+			t.Errorf("result %d: expected status %d, got %d", i, status, results[i].Status)
+		}
+	}
+	if string(results[2].Body) != `"plain"` {
+		t.Errorf("expected non-JSON body encoded as string, got %s", results[2].Body)
+	}
+}
+
+func TestBatch_InvalidEnvelope(t *testing.T) {
+	router := newBatchRouter(BatchWithMaxSize(2))
+	tooMany := `[{"path": "/users/1"}, {"path": "/users/1"}, {"path": "/users/1"}]`
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"not an array", `{"path": "/users/1"}`},
+		{"empty batch", `[]`},
+		{"exceeds max size", tooMany},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, _ := doBatch(t, router, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestBatch_WithoutWrapper(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if HasState(r.Context()) {
+			t.Error("expected sub-request not to see parent state")
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	rec, results := doBatch(t, Batch(inner), `[{"method": "GET", "path": "/a"}]`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if len(results) != 1 || results[0].Status != http.StatusAccepted {
+		t.Errorf("expected single 202 result, got %v", results)
+	}
+}
+
+func TestBatch_DoesNotLeakParentState(t *testing.T) {
+	inner := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusTeapot, nil)
+	})
+	handler := Handler()(Batch(inner))
+
+	rec, results := doBatch(t, handler, `[{"method": "GET", "path": "/a"}]`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if len(results) != 1 || results[0].Status != http.StatusOK {
+		t.Errorf("expected sub-request to be isolated from batch state, got %v", results)
+	}
+}
@@ -2,21 +2,31 @@ package chikit
 
 // Request binding and validation for Chi middleware.
 //
-// Provides JSON body and query parameter binding with struct tag validation
-// using go-playground/validator/v10.
+// Provides JSON body, query parameter, and path parameter binding with struct tag
+// validation using go-playground/validator/v10.
 
 import (
+	"bytes"
 	"context"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
+	"github.com/go-chi/chi/v5"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	"github.com/nhalm/canonlog"
 )
 
 type bindContextKey string
@@ -29,6 +39,12 @@ var (
 	defaultBindConfig = &bindConfig{formatter: defaultFormatter}
 )
 
+// Errors for JSON bodies that BindWithAllowedFields cannot filter.
+var (
+	errTrailingJSON  = errors.New("unexpected data after JSON value")
+	errNotJSONObject = errors.New("expected a JSON object")
+)
+
 func init() {
 	validate = validator.New(validator.WithRequiredStructEnabled())
 
@@ -39,6 +55,12 @@ func init() {
 		if name := strings.SplitN(fld.Tag.Get("query"), ",", 2)[0]; name != "" && name != "-" {
 			return name
 		}
+		if name := strings.SplitN(fld.Tag.Get("path"), ",", 2)[0]; name != "" && name != "-" {
+			return name
+		}
+		if name := strings.SplitN(fld.Tag.Get("form"), ",", 2)[0]; name != "" && name != "-" {
+			return name
+		}
 		return fld.Name
 	})
 }
@@ -48,7 +70,21 @@ func init() {
 type MessageFormatter func(field, tag, param string) string
 
 type bindConfig struct {
-	formatter MessageFormatter
+	formatter        MessageFormatter
+	translator       ut.Translator
+	validationStatus int
+	utf8Validation   bool
+	normalizeQuery   bool
+	allowEmptyBody   bool
+	allowedFields    []string
+	rejectDisallowed bool
+	groupErrors      bool
+	logBody          bool
+	useNumber        bool
+	disallowUnknown  bool
+	maxDepth         int
+	maxTokens        int
+	multipartMemory  int64
 }
 
 // BindOption configures the bind middleware.
@@ -61,6 +97,162 @@ func BindWithFormatter(fn MessageFormatter) BindOption {
 	}
 }
 
+// BindWithTranslator produces validation messages with trans instead of the
+// formatter, for APIs that localize validation feedback. Translations must be
+// registered for trans with RegisterTranslations; tags without a translation fall
+// back to the formatter.
+//
+// Example:
+//
+//	french := fr.New()
+//	trans, _ := ut.New(french, french).GetTranslator("fr")
+//	chikit.RegisterTranslations(trans, fr_translations.RegisterDefaultTranslations)
+//	r.Use(chikit.Binder(chikit.BindWithTranslator(trans)))
+func BindWithTranslator(trans ut.Translator) BindOption {
+	return func(c *bindConfig) {
+		c.translator = trans
+	}
+}
+
+// BindWithValidationStatus sets the HTTP status for validation failures.
+// Default is 400. Use 422 (Unprocessable Entity) for house styles that reserve 400
+// for malformed syntax; malformed JSON and query parse failures still return 400.
+func BindWithValidationStatus(status int) BindOption {
+	return func(c *bindConfig) {
+		c.validationStatus = status
+	}
+}
+
+// BindWithGroupedErrors serializes validation errors grouped by code instead of as a
+// flat list, for client UIs that highlight every field failing the same rule:
+//
+//	{"error": {"type": "validation_error", ..., "errors_by_code": {"required": ["name", "email"]}}}
+//
+// Fields appear in the order they failed. Messages are omitted in this form.
+// The default is the flat "errors" array.
+func BindWithGroupedErrors() BindOption {
+	return func(c *bindConfig) {
+		c.groupErrors = true
+	}
+}
+
+// BindWithCanonlogBody adds each JSON-bound request body to the canonical log line
+// as "request_body". Fields tagged `log:"redact"` are masked (see Redact), so secrets
+// such as passwords are not logged:
+//
+//	type LoginRequest struct {
+//		Email    string `json:"email"`
+//		Password string `json:"password" log:"redact"`
+//	}
+//
+// Requires WithCanonlog on the Handler; otherwise it has no effect.
+func BindWithCanonlogBody() BindOption {
+	return func(c *bindConfig) {
+		c.logBody = true
+	}
+}
+
+// BindWithUTF8Validation rejects string fields containing invalid UTF-8 or control
+// characters (other than tab, newline, and carriage return) with a validation_error
+// naming the field. Applies to JSON, Query, Path, and Form binding. Opt-in because it
+// walks every string field after decoding.
+//
+// For JSON, encoding/json replaces invalid UTF-8 with U+FFFD while decoding, so the
+// raw body is checked first and fields containing U+FFFD are reported when it is invalid.
+func BindWithUTF8Validation() BindOption {
+	return func(c *bindConfig) {
+		c.utf8Validation = true
+	}
+}
+
+// BindWithQueryNormalization normalizes query parameters before Query binds them:
+//   - Empty values are dropped (?page=&page=2 binds page=2)
+//   - Duplicate identical values for a key are collapsed (?tag=a&tag=a binds [a])
+//   - Bracket array notation is decoded (?tag[]=a&tag[]=b binds tag as [a b])
+//
+// Opt-in for interop with clients that send jQuery/PHP style query strings.
+func BindWithQueryNormalization() BindOption {
+	return func(c *bindConfig) {
+		c.normalizeQuery = true
+	}
+}
+
+// BindWithAllowEmptyBody treats an empty or missing JSON body as "{}" for endpoints
+// where the body is optional (e.g., PATCH with no changes). dest keeps its zero value
+// and validation still runs, so required fields are still enforced.
+// Without this option, an empty body returns 400.
+func BindWithAllowEmptyBody() BindOption {
+	return func(c *bindConfig) {
+		c.allowEmptyBody = true
+	}
+}
+
+// BindWithUseNumber decodes JSON numbers into interface-typed fields (any,
+// map[string]any, []any) as json.Number instead of float64, so 64-bit identifiers
+// and other large integers keep their full precision. Handlers reading such fields
+// must convert with Number.Int64, Number.Float64, or Number.String instead of
+// asserting float64. Typed numeric fields (int64, float64, ...) are unaffected.
+func BindWithUseNumber() BindOption {
+	return func(c *bindConfig) {
+		c.useNumber = true
+	}
+}
+
+// BindWithDisallowUnknownFields rejects JSON bodies containing fields that dest does
+// not have, instead of silently ignoring them, so client typos surface as errors.
+// The request fails with a validation_error naming the field (code "unknown_field",
+// message `unknown field "foo"`). Fields of nested objects are named without their
+// parent, since encoding/json does not report the path. The default is lenient.
+func BindWithDisallowUnknownFields() BindOption {
+	return func(c *bindConfig) {
+		c.disallowUnknown = true
+	}
+}
+
+// BindWithAllowedFields restricts JSON binding to the named top-level fields, as
+// mass-assignment protection for security-sensitive updates (e.g., preventing a client
+// from setting is_admin even though the struct has that field). Other fields are
+// silently dropped before decoding; use BindWithRejectDisallowedFields to reject them.
+// Names are JSON keys and match case-insensitively, like encoding/json.
+//
+// Typically applied per route:
+//
+//	r.With(chikit.Binder(chikit.BindWithAllowedFields("name", "email"))).
+//		Patch("/users/{id}", updateUser)
+func BindWithAllowedFields(fields ...string) BindOption {
+	return func(c *bindConfig) {
+		c.allowedFields = fields
+	}
+}
+
+// BindWithRejectDisallowedFields makes BindWithAllowedFields reject requests that
+// contain other fields with a validation_error listing each one (code "not_allowed"),
+// instead of silently dropping them.
+func BindWithRejectDisallowedFields() BindOption {
+	return func(c *bindConfig) {
+		c.rejectDisallowed = true
+	}
+}
+
+// BindWithMaxDepth rejects JSON bodies nested more than n objects or arrays deep
+// with 400, before decoding into dest. Guards against deeply nested payloads that
+// are small in bytes but expensive to decode. A top-level object has depth 1.
+func BindWithMaxDepth(n int) BindOption {
+	return func(c *bindConfig) {
+		c.maxDepth = n
+	}
+}
+
+// BindWithMaxTokens rejects JSON bodies containing more than n tokens (delimiters,
+// keys, and values) with 400, before decoding into dest. Guards against payloads
+// such as huge arrays of small values that stay under the body size limit but
+// allocate heavily when decoded.
+func BindWithMaxTokens(n int) BindOption {
+	return func(c *bindConfig) {
+		c.maxTokens = n
+	}
+}
+
 // Binder returns middleware with optional configuration.
 func Binder(opts ...BindOption) func(http.Handler) http.Handler {
 	cfg := &bindConfig{formatter: defaultFormatter}
@@ -83,6 +275,22 @@ func getBindConfig(ctx context.Context) *bindConfig {
 	return defaultBindConfig
 }
 
+// newBindValidationError builds a validation error using the configured status.
+func newBindValidationError(cfg *bindConfig, errs []FieldError) *APIError {
+	apiErr := NewValidationError(errs)
+	if cfg.validationStatus != 0 {
+		apiErr.Status = cfg.validationStatus
+	}
+	if cfg.groupErrors {
+		apiErr.ErrorsByCode = make(map[string][]string)
+		for _, fe := range errs {
+			apiErr.ErrorsByCode[fe.Code] = append(apiErr.ErrorsByCode[fe.Code], fe.Param)
+		}
+		apiErr.Errors = nil
+	}
+	return apiErr
+}
+
 func defaultFormatter(_, tag, param string) string {
 	switch tag {
 	case "required":
@@ -111,60 +319,398 @@ func defaultFormatter(_, tag, param string) string {
 // Returns true if binding and validation succeeded, false otherwise.
 // When validation fails, an error is set in the wrapper context (if available).
 //
+// Malformed JSON returns 400 with the byte offset of the syntax error. A value of the
+// wrong JSON type returns a validation_error with code "invalid_type" naming the field
+// (e.g., "items.0.quantity": "expected integer, got string").
+//
 // Body size limits: If validate.MaxBodySize middleware is active, requests exceeding
 // the limit during decode return ErrPayloadTooLarge (413). This handles chunked
 // transfers and requests with missing/incorrect Content-Length headers.
 func JSON(r *http.Request, dest any) bool {
 	ctx := r.Context()
+	cfg := getBindConfig(ctx)
 
-	if err := json.NewDecoder(r.Body).Decode(dest); err != nil {
-		if HasState(ctx) {
-			var maxBytesErr *http.MaxBytesError
-			if errors.As(err, &maxBytesErr) {
-				SetError(r, ErrPayloadTooLarge.With("Request body too large"))
-			} else {
-				SetError(r, ErrBadRequest.With("Invalid JSON request body"))
+	body, raw, ok := prepareJSONBody(r, cfg)
+	if !ok {
+		return false
+	}
+
+	if !decodeJSONBody(r, cfg, body, dest) {
+		return false
+	}
+
+	if cfg.logBody {
+		if logger, ok := canonlog.TryGetLogger(ctx); ok {
+			logger.InfoAdd("request_body", Redact(dest))
+		}
+	}
+
+	if cfg.utf8Validation {
+		if errs := utf8FieldErrors(dest, "json", !utf8.Valid(raw)); len(errs) > 0 {
+			if HasState(ctx) {
+				SetError(r, newBindValidationError(cfg, errs))
 			}
+			return false
 		}
-		return false
 	}
 
-	validateMu.RLock()
-	err := validate.Struct(dest)
-	validateMu.RUnlock()
+	return validateBound(r, cfg, dest)
+}
+
+// prepareJSONBody returns the reader to decode the request body from. Options that
+// inspect the raw body (UTF-8 validation, allowed fields, depth and token limits)
+// read it into raw first and are applied here. On failure the error is set and ok
+// is false.
+func prepareJSONBody(r *http.Request, cfg *bindConfig) (body io.Reader, raw []byte, ok bool) {
+	body = r.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	if !cfg.utf8Validation && cfg.allowedFields == nil && cfg.maxDepth <= 0 && cfg.maxTokens <= 0 {
+		return body, nil, true
+	}
 
+	raw, err := io.ReadAll(body)
 	if err != nil {
-		if HasState(ctx) {
-			cfg := getBindConfig(ctx)
-			SetError(r, NewValidationError(translateErrors(err, cfg.formatter)))
+		setJSONDecodeError(r, cfg, err)
+		return nil, nil, false
+	}
+
+	if msg := checkJSONLimits(raw, cfg.maxDepth, cfg.maxTokens); msg != "" {
+		if HasState(r.Context()) {
+			SetError(r, ErrBadRequest.With(msg))
+		}
+		return nil, nil, false
+	}
+
+	if cfg.allowedFields == nil {
+		return bytes.NewReader(raw), raw, true
+	}
+	filtered, ok := applyAllowedFields(r, cfg, raw)
+	if !ok {
+		return nil, nil, false
+	}
+	return bytes.NewReader(filtered), raw, true
+}
+
+// applyAllowedFields filters raw to the fields allowed by BindWithAllowedFields.
+// On failure, including disallowed fields with BindWithRejectDisallowedFields, the
+// error is set and ok is false.
+func applyAllowedFields(r *http.Request, cfg *bindConfig, raw []byte) ([]byte, bool) {
+	filtered, disallowed, err := filterAllowedFields(raw, cfg.allowedFields)
+	if err != nil {
+		setJSONDecodeError(r, cfg, err)
+		return nil, false
+	}
+	if len(disallowed) > 0 && cfg.rejectDisallowed {
+		if HasState(r.Context()) {
+			errs := make([]FieldError, len(disallowed))
+			for i, name := range disallowed {
+				errs[i] = FieldError{Param: name, Code: "not_allowed", Message: "field is not allowed"}
+			}
+			SetError(r, newBindValidationError(cfg, errs))
+		}
+		return nil, false
+	}
+	return filtered, true
+}
+
+// decodeJSONBody decodes body into dest with the decoder options in cfg. On failure
+// the error is set and it returns false.
+func decodeJSONBody(r *http.Request, cfg *bindConfig, body io.Reader, dest any) bool {
+	dec := json.NewDecoder(body)
+	if cfg.useNumber {
+		dec.UseNumber()
+	}
+	if cfg.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(dest)
+	if err == nil || (cfg.allowEmptyBody && errors.Is(err, io.EOF)) {
+		return true
+	}
+	if fe, ok := unknownFieldError(err); ok {
+		if HasState(r.Context()) {
+			SetError(r, newBindValidationError(cfg, []FieldError{fe}))
 		}
 		return false
 	}
+	setJSONDecodeError(r, cfg, err)
+	return false
+}
 
-	return true
+// JSONAs decodes the request body into a new T and validates it, like JSON, and
+// returns the value directly. On failure the error is set in the wrapper context
+// (if available) and ok is false; the returned value holds whatever was decoded.
+//
+// Example:
+//
+//	req, ok := chikit.JSONAs[CreateUserRequest](r)
+//	if !ok {
+//		return
+//	}
+func JSONAs[T any](r *http.Request) (T, bool) {
+	var dest T
+	ok := JSON(r, &dest)
+	return dest, ok
+}
+
+// filterAllowedFields removes top-level object fields not in allowed from raw and
+// returns the filtered body with the sorted names of removed fields. An empty body
+// or null is returned unchanged. Anything else that is not a single JSON object
+// returns an error, so trailing data or a malformed body cannot carry fields past
+// the filter to the decoder.
+func filterAllowedFields(raw []byte, allowed []string) ([]byte, []string, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return raw, nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	var fields map[string]json.RawMessage
+	if err := dec.Decode(&fields); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, nil, errNotJSONObject
+		}
+		return nil, nil, err
+	}
+	if dec.More() {
+		return nil, nil, errTrailingJSON
+	}
+	if fields == nil {
+		return raw, nil, nil
+	}
+
+	var disallowed []string
+	for name := range fields {
+		if !slices.ContainsFunc(allowed, func(a string) bool { return strings.EqualFold(a, name) }) {
+			disallowed = append(disallowed, name)
+			delete(fields, name)
+		}
+	}
+	sort.Strings(disallowed)
+
+	filtered, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filtered, disallowed, nil
+}
+
+// checkJSONLimits scans the first JSON value in raw token by token and returns a
+// message if it is nested deeper than maxDepth or has more than maxTokens tokens.
+// Zero limits are not checked. Syntax errors end the scan without a message, so the
+// decoder reports them.
+func checkJSONLimits(raw []byte, maxDepth, maxTokens int) string {
+	if maxDepth <= 0 && maxTokens <= 0 {
+		return ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	depth, tokens := 0, 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		tokens++
+		if maxTokens > 0 && tokens > maxTokens {
+			return fmt.Sprintf("Invalid JSON request body: more than %d tokens", maxTokens)
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if maxDepth > 0 && depth > maxDepth {
+				return fmt.Sprintf("Invalid JSON request body: nested deeper than %d levels", maxDepth)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return ""
+		}
+	}
+}
+
+// setJSONDecodeError sets the error for a failed JSON body read or decode. Syntax
+// errors report the byte offset, and type mismatches become a validation_error naming
+// the field with the expected and actual JSON types.
+func setJSONDecodeError(r *http.Request, cfg *bindConfig, err error) {
+	if !HasState(r.Context()) {
+		return
+	}
+	var maxBytesErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		SetError(r, ErrPayloadTooLarge.With("Request body too large"))
+	case errors.As(err, &syntaxErr):
+		SetError(r, ErrBadRequest.With(fmt.Sprintf("Invalid JSON request body: %s at offset %d", syntaxErr, syntaxErr.Offset)))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		SetError(r, ErrBadRequest.With("Invalid JSON request body: unexpected end of input"))
+	case errors.Is(err, errTrailingJSON), errors.Is(err, errNotJSONObject):
+		SetError(r, ErrBadRequest.With("Invalid JSON request body: "+err.Error()))
+	case errors.As(err, &typeErr):
+		SetError(r, newBindValidationError(cfg, []FieldError{{
+			Param:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: fmt.Sprintf("expected %s, got %s", jsonTypeName(typeErr.Type), jsonValueName(typeErr.Value)),
+		}}))
+	default:
+		SetError(r, ErrBadRequest.With("Invalid JSON request body"))
+	}
+}
+
+// jsonTypeName returns the JSON type a value of Go type t decodes from, for type
+// mismatch messages.
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return t.String()
+	}
+}
+
+// jsonValueName normalizes json.UnmarshalTypeError.Value ("bool", "number 3.5", ...)
+// to the JSON type name used by jsonTypeName.
+func jsonValueName(value string) string {
+	value, _, _ = strings.Cut(value, " ")
+	if value == "bool" {
+		return "boolean"
+	}
+	return value
+}
+
+// unknownFieldError converts the error json.Decoder returns for an unknown field under
+// DisallowUnknownFields into a FieldError. encoding/json has no typed error for it,
+// so the message is parsed; Param is left empty if the name cannot be extracted.
+func unknownFieldError(err error) (FieldError, bool) {
+	rest, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return FieldError{}, false
+	}
+	fe := FieldError{Code: "unknown_field", Message: "unknown field " + rest}
+	if name, err := strconv.Unquote(rest); err == nil {
+		fe.Param = name
+	}
+	return fe, true
 }
 
 // Query decodes query parameters into dest and validates it.
 // Returns true if binding and validation succeeded, false otherwise.
 // When validation fails, an error is set in the wrapper context (if available).
+// Slice fields bind all values of a repeated parameter (?tag=a&tag=b).
 func Query(r *http.Request, dest any) bool {
 	ctx := r.Context()
+	cfg := getBindConfig(ctx)
 
-	if err := decodeQuery(r, dest); err != nil {
+	query := r.URL.Query()
+	if cfg.normalizeQuery {
+		query = normalizeQuery(query)
+	}
+
+	if err := decodeValues(dest, "query", func(name string) []string { return query[name] }); err != nil {
 		if HasState(ctx) {
 			SetError(r, ErrBadRequest.With("Invalid query parameters"))
 		}
 		return false
 	}
 
+	if cfg.utf8Validation {
+		if errs := utf8FieldErrors(dest, "query", false); len(errs) > 0 {
+			if HasState(ctx) {
+				SetError(r, newBindValidationError(cfg, errs))
+			}
+			return false
+		}
+	}
+
+	return validateBound(r, cfg, dest)
+}
+
+// Path decodes chi URL parameters into dest and validates it.
+// Fields are mapped by their path tag (e.g., `path:"id"` for /users/{id}) and
+// converted like Query fields. Returns true if binding and validation succeeded,
+// false otherwise. When conversion or validation fails, an error is set in the
+// wrapper context (if available).
+//
+// Example:
+//
+//	type GetUserParams struct {
+//		ID string `path:"id" validate:"required,uuid"`
+//	}
+//
+//	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+//		var params GetUserParams
+//		if !chikit.Path(r, &params) {
+//			return
+//		}
+//	})
+func Path(r *http.Request, dest any) bool {
+	ctx := r.Context()
+	cfg := getBindConfig(ctx)
+
+	rctx := chi.RouteContext(ctx)
+	param := func(name string) []string {
+		if rctx == nil {
+			return nil
+		}
+		value := rctx.URLParam(name)
+		if value == "" {
+			return nil
+		}
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+		return []string{value}
+	}
+
+	if err := decodeValues(dest, "path", param); err != nil {
+		if HasState(ctx) {
+			SetError(r, ErrBadRequest.With("Invalid path parameters"))
+		}
+		return false
+	}
+
+	if cfg.utf8Validation {
+		if errs := utf8FieldErrors(dest, "path", false); len(errs) > 0 {
+			if HasState(ctx) {
+				SetError(r, newBindValidationError(cfg, errs))
+			}
+			return false
+		}
+	}
+
+	return validateBound(r, cfg, dest)
+}
+
+// validateBound runs struct validation on dest after decoding.
+// Returns false and sets a validation error in the wrapper context (if available) on failure.
+func validateBound(r *http.Request, cfg *bindConfig, dest any) bool {
 	validateMu.RLock()
 	err := validate.Struct(dest)
 	validateMu.RUnlock()
 
 	if err != nil {
-		if HasState(ctx) {
-			cfg := getBindConfig(ctx)
-			SetError(r, NewValidationError(translateErrors(err, cfg.formatter)))
+		if HasState(r.Context()) {
+			SetError(r, newBindValidationError(cfg, translateErrors(err, cfg)))
 		}
 		return false
 	}
@@ -180,7 +726,42 @@ func RegisterValidation(tag string, fn validator.Func) error {
 	return validate.RegisterValidation(tag, fn)
 }
 
-func translateErrors(err error, formatter MessageFormatter) []FieldError {
+// RegisterStructValidation registers fn to validate each of types (pass zero values,
+// e.g. DateRange{}) as a whole, for cross-field rules field tags cannot express.
+// Errors reported with sl.ReportError become FieldErrors like tag failures: the
+// param is the reported field name, the code is the tag, and the message comes from
+// the formatter. Must be called at startup before handling requests.
+//
+// Example:
+//
+//	type DateRange struct {
+//		Start time.Time `json:"start" validate:"required"`
+//		End   time.Time `json:"end" validate:"required"`
+//	}
+//
+//	chikit.RegisterStructValidation(func(sl validator.StructLevel) {
+//		dr := sl.Current().Interface().(DateRange)
+//		if !dr.End.After(dr.Start) {
+//			sl.ReportError(dr.End, "end", "End", "after_start", "")
+//		}
+//	}, DateRange{})
+func RegisterStructValidation(fn validator.StructLevelFunc, types ...any) {
+	validateMu.Lock()
+	defer validateMu.Unlock()
+	validate.RegisterStructValidation(fn, types...)
+}
+
+// RegisterTranslations registers validation message translations for trans using
+// register, typically RegisterDefaultTranslations from one of the
+// github.com/go-playground/validator/v10/translations packages. Use with
+// BindWithTranslator. Must be called at startup before handling requests.
+func RegisterTranslations(trans ut.Translator, register func(*validator.Validate, ut.Translator) error) error {
+	validateMu.Lock()
+	defer validateMu.Unlock()
+	return register(validate, trans)
+}
+
+func translateErrors(err error, cfg *bindConfig) []FieldError {
 	var errs validator.ValidationErrors
 	if !errors.As(err, &errs) {
 		return []FieldError{{
@@ -194,13 +775,41 @@ func translateErrors(err error, formatter MessageFormatter) []FieldError {
 		result[i] = FieldError{
 			Param:   e.Field(),
 			Code:    e.Tag(),
-			Message: formatter(e.Field(), e.Tag(), e.Param()),
+			Message: fieldMessage(e, cfg),
+			path:    fieldPath(e.Namespace(), e.Field()),
 		}
 	}
 	return result
 }
 
-func decodeQuery(r *http.Request, dest any) error {
+// fieldMessage returns the translated message for e when a translator is configured
+// and has a translation for the tag, and the formatter's message otherwise.
+func fieldMessage(e validator.FieldError, cfg *bindConfig) string {
+	if cfg.translator != nil {
+		// Translate returns e.Error() when no translation is registered for the tag
+		if msg := e.Translate(cfg.translator); msg != e.Error() {
+			return msg
+		}
+	}
+	return cfg.formatter(e.Field(), e.Tag(), e.Param())
+}
+
+// fieldPath converts a validator namespace (e.g., "CreateOrder.items[0].sku") to the
+// dotted path used for problem details pointers ("items.0.sku"), dropping the root struct
+// name. Falls back to field if the namespace has no root.
+func fieldPath(namespace, field string) string {
+	_, path, ok := strings.Cut(namespace, ".")
+	if !ok || path == "" {
+		return field
+	}
+	path = strings.ReplaceAll(path, "[", ".")
+	return strings.ReplaceAll(path, "]", "")
+}
+
+// decodeValues sets the fields of dest tagged with tagKey from the values lookup
+// returns for each tag name. Slice fields take every value; other fields take the
+// first. Missing and empty values leave the field unchanged.
+func decodeValues(dest any, tagKey string, lookup func(name string) []string) error {
 	rv := reflect.ValueOf(dest)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return fmt.Errorf("dest must be non-nil pointer to struct")
@@ -211,11 +820,9 @@ func decodeQuery(r *http.Request, dest any) error {
 	}
 	t := v.Type()
 
-	query := r.URL.Query()
-
 	for i := range t.NumField() {
 		structField := t.Field(i)
-		tag := structField.Tag.Get("query")
+		tag := structField.Tag.Get(tagKey)
 		if tag == "" || tag == "-" {
 			continue
 		}
@@ -226,11 +833,19 @@ func decodeQuery(r *http.Request, dest any) error {
 		}
 
 		name := strings.SplitN(tag, ",", 2)[0]
-		value := query.Get(name)
-		if value == "" {
+		values := lookup(name)
+		if fieldVal.Kind() == reflect.Slice && !isTextUnmarshaler(fieldVal) {
+			if err := setSliceField(fieldVal, values); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", name, err)
+			}
 			continue
 		}
 
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		value := values[0]
+
 		if err := setField(fieldVal, value); err != nil {
 			return fmt.Errorf("invalid value for %s: %w", name, err)
 		}
@@ -239,7 +854,59 @@ func decodeQuery(r *http.Request, dest any) error {
 	return nil
 }
 
+// setSliceField sets a slice field from all non-empty values of a repeated parameter.
+func setSliceField(field reflect.Value, values []string) error {
+	slice := reflect.MakeSlice(field.Type(), 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		elem := reflect.New(field.Type().Elem()).Elem()
+		if err := setField(elem, value); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	if slice.Len() > 0 {
+		field.Set(slice)
+	}
+	return nil
+}
+
+// normalizeQuery drops empty values, collapses duplicate values per key, and merges
+// bracket array keys ("tag[]") into their plain name. Keys are processed in sorted
+// order so merged values are deterministic.
+func normalizeQuery(query url.Values) url.Values {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	normalized := make(url.Values, len(query))
+	seen := make(map[string]map[string]bool, len(query))
+	for _, key := range keys {
+		name := strings.TrimSuffix(key, "[]")
+		for _, value := range query[key] {
+			if value == "" || seen[name][value] {
+				continue
+			}
+			if seen[name] == nil {
+				seen[name] = make(map[string]bool)
+			}
+			seen[name][value] = true
+			normalized[name] = append(normalized[name], value)
+		}
+	}
+	return normalized
+}
+
+// setField converts value to the field's type. Types implementing
+// encoding.TextUnmarshaler (e.g., uuid.UUID, netip.Addr) are decoded with it.
 func setField(field reflect.Value, value string) error {
+	if isTextUnmarshaler(field) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
@@ -275,3 +942,88 @@ func setField(field reflect.Value, value string) error {
 	}
 	return nil
 }
+
+// isTextUnmarshaler reports whether a pointer to field implements
+// encoding.TextUnmarshaler.
+func isTextUnmarshaler(field reflect.Value) bool {
+	return field.CanAddr() && field.Addr().Type().Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+// utf8FieldErrors walks the string fields of dest and reports those containing invalid
+// UTF-8 or disallowed control characters. Field names come from tagKey ("json",
+// "query", "path", or "form") and nested fields are joined with dots. When replaced is true, U+FFFD is
+// treated as invalid since the decoder substituted it for invalid input bytes.
+func utf8FieldErrors(dest any, tagKey string, replaced bool) []FieldError {
+	var errs []FieldError
+	var walk func(v reflect.Value, path string)
+	walk = func(v reflect.Value, path string) {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if !v.IsNil() {
+				walk(v.Elem(), path)
+			}
+		case reflect.Struct:
+			t := v.Type()
+			for i := range t.NumField() {
+				if name, ok := boundFieldName(t.Field(i), tagKey); ok {
+					walk(v.Field(i), joinFieldPath(path, name))
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := range v.Len() {
+				walk(v.Index(i), joinFieldPath(path, strconv.Itoa(i)))
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				walk(iter.Value(), joinFieldPath(path, fmt.Sprint(iter.Key().Interface())))
+			}
+		case reflect.String:
+			if code, msg := checkUTF8String(v.String(), replaced); code != "" {
+				errs = append(errs, FieldError{Param: path, Code: code, Message: msg})
+			}
+		}
+	}
+	walk(reflect.ValueOf(dest), "")
+
+	if replaced && len(errs) == 0 {
+		// Invalid bytes outside any bound string field (e.g., in an object key)
+		errs = append(errs, FieldError{Code: "utf8", Message: "must be valid UTF-8"})
+	}
+	return errs
+}
+
+// boundFieldName returns the name f is bound by under tagKey, falling back to the
+// Go field name. Returns false for unexported fields and fields tagged "-".
+func boundFieldName(f reflect.StructField, tagKey string) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	name := strings.SplitN(f.Tag.Get(tagKey), ",", 2)[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+func checkUTF8String(s string, replaced bool) (code, message string) {
+	if !utf8.ValidString(s) || (replaced && strings.ContainsRune(s, utf8.RuneError)) {
+		return "utf8", "must be valid UTF-8"
+	}
+	for _, c := range s {
+		if (c < 0x20 && c != '\t' && c != '\n' && c != '\r') || c == 0x7f {
+			return "control_character", "must not contain control characters"
+		}
+	}
+	return "", ""
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -2,12 +2,21 @@ package chikit
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
 )
 
 type CreateUserRequest struct {
@@ -81,8 +90,103 @@ func TestJSON_MalformedJSON(t *testing.T) {
 	if resp["error"].Type != "request_error" {
 		t.Errorf("expected error type request_error, got %s", resp["error"].Type)
 	}
-	if resp["error"].Message != "Invalid JSON request body" {
-		t.Errorf("expected message 'Invalid JSON request body', got %s", resp["error"].Message)
+	want := "Invalid JSON request body: invalid character 'a' looking for beginning of object key string at offset 31"
+	if resp["error"].Message != want {
+		t.Errorf("expected message %q, got %s", want, resp["error"].Message)
+	}
+}
+
+func TestJSON_DecodeErrorDetails(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku"`
+	}
+	type OrderRequest struct {
+		Quantity int        `json:"quantity"`
+		Gift     bool       `json:"gift"`
+		Items    []Item     `json:"items"`
+		Origin   netip.Addr `json:"origin"`
+	}
+
+	handler := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req OrderRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	})))
+
+	tests := []struct {
+		name        string
+		body        string
+		wantType    ErrorType
+		wantMessage string
+		wantErrors  []FieldError
+	}{
+		{
+			name:        "syntax error",
+			body:        `{"quantity": 1,}`,
+			wantType:    ErrorTypeRequest,
+			wantMessage: "Invalid JSON request body: invalid character '}' looking for beginning of object key string at offset 16",
+		},
+		{
+			name:        "truncated",
+			body:        `{"quantity": 1`,
+			wantType:    ErrorTypeRequest,
+			wantMessage: "Invalid JSON request body: unexpected end of input",
+		},
+		{
+			name:       "string for integer",
+			body:       `{"quantity": "two"}`,
+			wantType:   ErrorTypeValidation,
+			wantErrors: []FieldError{{Param: "quantity", Code: "invalid_type", Message: "expected integer, got string"}},
+		},
+		{
+			name:       "fraction for integer",
+			body:       `{"quantity": 1.5}`,
+			wantType:   ErrorTypeValidation,
+			wantErrors: []FieldError{{Param: "quantity", Code: "invalid_type", Message: "expected integer, got number"}},
+		},
+		{
+			name:       "nested field",
+			body:       `{"items": [{"sku": true}]}`,
+			wantType:   ErrorTypeValidation,
+			wantErrors: []FieldError{{Param: "items.0.sku", Code: "invalid_type", Message: "expected string, got boolean"}},
+		},
+		{
+			name:       "text unmarshaler",
+			body:       `{"origin": 10}`,
+			wantType:   ErrorTypeValidation,
+			wantErrors: []FieldError{{Param: "origin", Code: "invalid_type", Message: "expected string, got number"}},
+		},
+		{
+			name:       "wrong top-level type",
+			body:       `[1, 2]`,
+			wantType:   ErrorTypeValidation,
+			wantErrors: []FieldError{{Param: "", Code: "invalid_type", Message: "expected object, got array"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Error.Type != tt.wantType {
+				t.Errorf("expected type %s, got %s", tt.wantType, resp.Error.Type)
+			}
+			if tt.wantMessage != "" && resp.Error.Message != tt.wantMessage {
+				t.Errorf("expected message %q, got %q", tt.wantMessage, resp.Error.Message)
+			}
+			if !reflect.DeepEqual(resp.Error.Errors, tt.wantErrors) {
+				t.Errorf("expected errors %+v, got %+v", tt.wantErrors, resp.Error.Errors)
+			}
+		})
 	}
 }
 
@@ -125,6 +229,60 @@ func TestJSON_ValidationFailure(t *testing.T) {
 	}
 }
 
+func TestJSONAs(t *testing.T) {
+	var got CreateUserRequest
+	typed := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		req, ok := JSONAs[CreateUserRequest](r)
+		got = req
+		if !ok {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	})))
+	untyped := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req CreateUserRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	})))
+
+	rec := httptest.NewRecorder()
+	typed.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`{"email": "a@example.com", "age": 30}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got != (CreateUserRequest{Email: "a@example.com", Age: 30}) {
+		t.Errorf("expected decoded value, got %+v", got)
+	}
+
+	tests := []struct {
+		body string
+		want CreateUserRequest
+	}{
+		{`{"email": "invalid-email", "age": 15}`, CreateUserRequest{Email: "invalid-email", Age: 15}},
+		{`{"email": `, CreateUserRequest{}},
+	}
+	for _, tt := range tests {
+		body := tt.body
+		typedRec := httptest.NewRecorder()
+		typed.ServeHTTP(typedRec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		untypedRec := httptest.NewRecorder()
+		untyped.ServeHTTP(untypedRec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+		if typedRec.Code != untypedRec.Code || typedRec.Body.String() != untypedRec.Body.String() {
+			t.Errorf("body %q: expected JSONAs to match JSON (%d %s), got %d %s",
+				body, untypedRec.Code, untypedRec.Body.String(), typedRec.Code, typedRec.Body.String())
+		}
+		if typedRec.Code != http.StatusBadRequest {
+			t.Errorf("body %q: expected status 400, got %d", body, typedRec.Code)
+		}
+		if got != tt.want {
+			t.Errorf("body %q: expected returned value %+v, got %+v", body, tt.want, got)
+		}
+	}
+}
+
 func TestJSON_MissingRequired(t *testing.T) {
 	handler := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
 		var req CreateUserRequest
@@ -262,6 +420,85 @@ func TestQuery_TypeConversionError(t *testing.T) {
 	}
 }
 
+type GetPostParams struct {
+	UserID string     `path:"user_id" validate:"required,uuid"`
+	PostID int64      `path:"post_id" validate:"min=1"`
+	Draft  bool       `path:"draft"`
+	Host   netip.Addr `path:"host"`
+	Slug   string     `path:"slug"`
+}
+
+func servePath(t *testing.T, target string) (GetPostParams, *httptest.ResponseRecorder) {
+	t.Helper()
+	var params GetPostParams
+	r := chi.NewRouter()
+	r.Use(Handler())
+	r.Get("/users/{user_id}/posts/{post_id}/{draft}/{host}/{slug}", func(_ http.ResponseWriter, r *http.Request) {
+		if !Path(r, &params) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", target, http.NoBody))
+	return params, rec
+}
+
+func TestPath_ValidInput(t *testing.T) {
+	params, rec := servePath(t, "/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8/posts/42/true/10.0.0.1/hello%20world")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	want := GetPostParams{
+		UserID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		PostID: 42,
+		Draft:  true,
+		Host:   netip.MustParseAddr("10.0.0.1"),
+		Slug:   "hello world",
+	}
+	if params != want {
+		t.Errorf("expected %+v, got %+v", want, params)
+	}
+}
+
+func TestPath_TypeConversionError(t *testing.T) {
+	for _, target := range []string{
+		"/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8/posts/abc/true/10.0.0.1/x",
+		"/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8/posts/1/maybe/10.0.0.1/x",
+		"/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8/posts/1/true/not-an-ip/x",
+	} {
+		_, rec := servePath(t, target)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, rec.Code)
+		}
+		var resp map[string]APIError
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp["error"].Message != "Invalid path parameters" {
+			t.Errorf("%s: expected message 'Invalid path parameters', got %s", target, resp["error"].Message)
+		}
+	}
+}
+
+func TestPath_ValidationFailure(t *testing.T) {
+	_, rec := servePath(t, "/users/not-a-uuid/posts/0/false/10.0.0.1/x")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	var resp errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := []FieldError{
+		{Param: "user_id", Code: "uuid", Message: "must be a valid UUID"},
+		{Param: "post_id", Code: "min", Message: "must be at least 1"},
+	}
+	if resp.Error.Type != ErrorTypeValidation || !reflect.DeepEqual(resp.Error.Errors, want) {
+		t.Errorf("expected validation errors %+v, got %+v", want, resp.Error)
+	}
+}
+
 func TestCustomFormatter(t *testing.T) {
 	customFormatter := func(field, tag, _ string) string {
 		return "CUSTOM:" + field + ":" + tag
@@ -295,6 +532,48 @@ func TestCustomFormatter(t *testing.T) {
 	}
 }
 
+func TestBindWithTranslator(t *testing.T) {
+	french := fr.New()
+	trans, _ := ut.New(french, french).GetTranslator("fr")
+	if err := RegisterTranslations(trans, fr_translations.RegisterDefaultTranslations); err != nil {
+		t.Fatalf("failed to register translations: %v", err)
+	}
+	if err := RegisterValidation("translator_even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}); err != nil {
+		t.Fatalf("failed to register validation: %v", err)
+	}
+
+	type SeatRequest struct {
+		Email string `json:"email" validate:"required,email"`
+		Seats int    `json:"seats" validate:"translator_even"`
+	}
+
+	handler := Handler()(Binder(BindWithTranslator(trans))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req SeatRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	})))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"email": "not-an-email", "seats": 3}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	want := []FieldError{
+		{Param: "email", Code: "email", Message: "email doit être une adresse email valide"},
+		{Param: "seats", Code: "translator_even", Message: "translator_even"},
+	}
+	if !reflect.DeepEqual(resp.Error.Errors, want) {
+		t.Errorf("expected %+v, got %+v", want, resp.Error.Errors)
+	}
+}
+
 func TestDefaultFormatterWithoutMiddleware(t *testing.T) {
 	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
 		var req CreateUserRequest
@@ -365,6 +644,58 @@ func TestRegisterValidation(t *testing.T) {
 	}
 }
 
+type bookingDateRange struct {
+	Start time.Time `json:"start" validate:"required"`
+	End   time.Time `json:"end" validate:"required"`
+}
+
+func TestRegisterStructValidation(t *testing.T) {
+	RegisterStructValidation(func(sl validator.StructLevel) {
+		dr := sl.Current().Interface().(bookingDateRange)
+		if !dr.End.After(dr.Start) {
+			sl.ReportError(dr.End, "end", "End", "after_start", "")
+		}
+	}, bookingDateRange{})
+
+	type BookingRequest struct {
+		Guest string           `json:"guest" validate:"required"`
+		Stay  bookingDateRange `json:"stay"`
+	}
+
+	handler := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req BookingRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+	serve := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := serve(`{"guest": "ada", "stay": {"start": "2026-01-01T00:00:00Z", "end": "2026-01-05T00:00:00Z"}}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := serve(`{"stay": {"start": "2026-01-05T00:00:00Z", "end": "2026-01-01T00:00:00Z"}}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	var resp errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := []FieldError{
+		{Param: "guest", Code: "required", Message: "required"},
+		{Param: "end", Code: "after_start", Message: "after_start"},
+	}
+	if resp.Error.Type != ErrorTypeValidation || !reflect.DeepEqual(resp.Error.Errors, want) {
+		t.Errorf("expected %+v, got %+v", want, resp.Error.Errors)
+	}
+}
+
 func TestJSON_EmptyBody(t *testing.T) {
 	handler := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
 		var req CreateUserRequest
@@ -384,6 +715,73 @@ func TestJSON_EmptyBody(t *testing.T) {
 	}
 }
 
+func TestBindWithAllowEmptyBody(t *testing.T) {
+	type patchRequest struct {
+		Name string `json:"name" validate:"omitempty,min=2"`
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"empty body", "", http.StatusOK},
+		{"whitespace body", "  \n", http.StatusOK},
+		{"malformed body", "{", http.StatusBadRequest},
+		{"validation still runs", `{"name": "a"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded patchRequest
+			handler := Handler()(Binder(BindWithAllowEmptyBody())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				decoded = patchRequest{}
+				if !JSON(r, &decoded) {
+					return
+				}
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			req := httptest.NewRequest("PATCH", "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if tt.expected == http.StatusOK && decoded != (patchRequest{}) {
+				t.Errorf("expected zero value, got %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestBindWithAllowEmptyBody_RequiredFieldsEnforced(t *testing.T) {
+	handler := Handler()(Binder(BindWithAllowEmptyBody())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req CreateUserRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	})))
+
+	req := httptest.NewRequest("POST", "/", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+
+	var resp map[string]*APIError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp["error"].Type != ErrorTypeValidation {
+		t.Errorf("expected validation_error, got %s", resp["error"].Type)
+	}
+}
+
 func TestDefaultFormatter_AllTags(t *testing.T) {
 	type AllTagsRequest struct {
 		Email  string `json:"email" validate:"email"`
@@ -559,3 +957,579 @@ func TestJSON_BodyTooLarge(t *testing.T) {
 		t.Errorf("expected message 'Request body too large', got %s", resp["error"].Message)
 	}
 }
+
+func TestBindWithValidationStatus(t *testing.T) {
+	handler := Handler()(Binder(BindWithValidationStatus(http.StatusUnprocessableEntity))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req CreateUserRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	})))
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"validation failure uses configured status", `{"email": "invalid", "age": 25}`, http.StatusUnprocessableEntity},
+		{"malformed JSON stays 400", `{invalid`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
+
+func TestBindWithValidationStatus_Query(t *testing.T) {
+	handler := Handler()(Binder(BindWithValidationStatus(http.StatusUnprocessableEntity))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var query ListUsersRequest
+		if !Query(r, &query) {
+			return
+		}
+		SetResponse(r, http.StatusOK, query)
+	})))
+
+	req := httptest.NewRequest("GET", "/?limit=500", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/?limit=abc", http.NoBody)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+type utf8Request struct {
+	Name    string `json:"name"`
+	Address struct {
+		Street string `json:"street"`
+	} `json:"address"`
+	Tags []string `json:"tags"`
+}
+
+func TestBindWithUTF8Validation_JSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedCode  int
+		expectedParam string
+		expectedError string
+	}{
+		{"valid unicode", `{"name": "Zoë 日本 🎉", "tags": ["a\tb"]}`, http.StatusOK, "", ""},
+		{"invalid utf8", "{\"name\": \"bad\xff\xfe\"}", http.StatusBadRequest, "name", "utf8"},
+		{"invalid utf8 nested", "{\"address\": {\"street\": \"\xc3\x28\"}}", http.StatusBadRequest, "address.street", "utf8"},
+		{"control character", `{"tags": ["ok", "nul\u0000byte"]}`, http.StatusBadRequest, "tags.1", "control_character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(Binder(BindWithUTF8Validation())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				var req utf8Request
+				if !JSON(r, &req) {
+					return
+				}
+				SetResponse(r, http.StatusOK, req)
+			})))
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body.String())
+			}
+			if tt.expectedParam == "" {
+				return
+			}
+
+			var resp map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			errs := resp["error"].Errors
+			if len(errs) != 1 || errs[0].Param != tt.expectedParam || errs[0].Code != tt.expectedError {
+				t.Errorf("expected %s error on %s, got %v", tt.expectedError, tt.expectedParam, errs)
+			}
+		})
+	}
+}
+
+func TestBindWithUTF8Validation_Query(t *testing.T) {
+	handler := Handler()(Binder(BindWithUTF8Validation())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var query struct {
+			Search string `query:"search"`
+		}
+		if !Query(r, &query) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("GET", "/?search=%ff%fe", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/?search=caf%C3%A9", http.NoBody)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestJSON_InvalidUTF8AllowedWithoutOption(t *testing.T) {
+	handler := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req utf8Request
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("{\"name\": \"bad\xff\"}"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestQuery_SliceField(t *testing.T) {
+	var query struct {
+		IDs []int `query:"id"`
+	}
+	handler := Handler()(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if !Query(r, &query) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("GET", "/?id=1&id=2", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if len(query.IDs) != 2 || query.IDs[0] != 1 || query.IDs[1] != 2 {
+		t.Errorf("expected [1 2], got %v", query.IDs)
+	}
+}
+
+func TestBindWithQueryNormalization(t *testing.T) {
+	type listQuery struct {
+		Filter []string `query:"filter"`
+		Page   int      `query:"page" validate:"omitempty,min=1"`
+		Sort   string   `query:"sort"`
+	}
+
+	tests := []struct {
+		name           string
+		normalize      bool
+		query          string
+		expectedFilter []string
+		expectedPage   int
+	}{
+		{"bracket notation", true, "filter[]=a&filter[]=b", []string{"a", "b"}, 0},
+		{"duplicates collapsed", true, "filter=a&filter=a&filter[]=a", []string{"a"}, 0},
+		{"empty values dropped", true, "filter[]=&page=&page=2&sort=", nil, 2},
+		{"bracket ignored without option", false, "filter[]=a&filter[]=b", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []BindOption
+			if tt.normalize {
+				opts = append(opts, BindWithQueryNormalization())
+			}
+
+			var query listQuery
+			handler := Handler()(Binder(opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				if !Query(r, &query) {
+					return
+				}
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			req := httptest.NewRequest("GET", "/?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if !reflect.DeepEqual(query.Filter, tt.expectedFilter) {
+				t.Errorf("expected filter %v, got %v", tt.expectedFilter, query.Filter)
+			}
+			if query.Page != tt.expectedPage {
+				t.Errorf("expected page %d, got %d", tt.expectedPage, query.Page)
+			}
+		})
+	}
+}
+
+type allowedFieldsRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	IsAdmin bool   `json:"is_admin"`
+}
+
+type unknownFieldsRequest struct {
+	Name    string `json:"name"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+func TestBindWithDisallowUnknownFields(t *testing.T) {
+	serve := func(opts []BindOption, body string) *httptest.ResponseRecorder {
+		handler := Handler()(Binder(opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			var req unknownFieldsRequest
+			if !JSON(r, &req) {
+				return
+			}
+			SetResponse(r, http.StatusOK, nil)
+		})))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		return rec
+	}
+	strict := []BindOption{BindWithDisallowUnknownFields()}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected FieldError
+	}{
+		{"top-level", `{"name": "alice", "nmae": "typo"}`, FieldError{Param: "nmae", Code: "unknown_field", Message: `unknown field "nmae"`}},
+		{"nested", `{"name": "alice", "address": {"city": "Paris", "zip": "75001"}}`, FieldError{Param: "zip", Code: "unknown_field", Message: `unknown field "zip"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(strict, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var resp map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errs := resp["error"].Errors; len(errs) != 1 || errs[0] != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, errs)
+			}
+
+			if rec := serve(nil, tt.body); rec.Code != http.StatusOK {
+				t.Errorf("expected unknown fields to be ignored by default, got %d", rec.Code)
+			}
+		})
+	}
+
+	if rec := serve(strict, `{"name": "alice", "address": {"city": "Paris"}}`); rec.Code != http.StatusOK {
+		t.Errorf("expected known fields to bind, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBindWithAllowedFields(t *testing.T) {
+	var got allowedFieldsRequest
+	handler := Handler()(Binder(BindWithAllowedFields("name", "email"))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = allowedFieldsRequest{}
+		if !JSON(r, &got) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"Name": "alice", "email": "a@example.com", "is_admin": true}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got.Name != "alice" || got.Email != "a@example.com" {
+		t.Errorf("expected allowed fields to bind, got %+v", got)
+	}
+	if got.IsAdmin {
+		t.Error("expected is_admin to be dropped")
+	}
+}
+
+func TestBindWithRejectDisallowedFields(t *testing.T) {
+	handler := Handler()(Binder(BindWithAllowedFields("name"), BindWithRejectDisallowedFields())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req allowedFieldsRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"name": "alice", "is_admin": true, "email": "a@example.com"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp map[string]*APIError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	errs := resp["error"].Errors
+	if len(errs) != 2 || errs[0].Param != "email" || errs[1].Param != "is_admin" || errs[0].Code != "not_allowed" {
+		t.Errorf("expected not_allowed errors on email and is_admin, got %v", errs)
+	}
+
+	req = httptest.NewRequest("PATCH", "/", strings.NewReader(`{"name": "alice"}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 with only allowed fields, got %d", rec.Code)
+	}
+}
+
+func TestBindWithAllowedFields_Bypass(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantMessage string
+	}{
+		{"trailing garbage", `{"name": "x", "is_admin": true} junk`, "Invalid JSON request body: unexpected data after JSON value"},
+		{"trailing value", `{"name": "x", "is_admin": true}{}`, "Invalid JSON request body: unexpected data after JSON value"},
+		{"malformed", `{"name": "x", "is_admin": true`, "Invalid JSON request body: unexpected end of input"},
+		{"not an object", `[{"is_admin": true}]`, "Invalid JSON request body: expected a JSON object"},
+	}
+
+	for _, reject := range []bool{false, true} {
+		opts := []BindOption{BindWithAllowedFields("name")}
+		if reject {
+			opts = append(opts, BindWithRejectDisallowedFields())
+		}
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s reject=%v", tt.name, reject), func(t *testing.T) {
+				var got allowedFieldsRequest
+				handler := Handler()(Binder(opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					if !JSON(r, &got) {
+						return
+					}
+					SetResponse(r, http.StatusOK, nil)
+				})))
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest("PATCH", "/", strings.NewReader(tt.body)))
+
+				if rec.Code != http.StatusBadRequest {
+					t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+				}
+				if got.IsAdmin {
+					t.Error("expected is_admin not to bind")
+				}
+				var resp map[string]*APIError
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp["error"].Message != tt.wantMessage {
+					t.Errorf("expected message %q, got %q", tt.wantMessage, resp["error"].Message)
+				}
+			})
+		}
+	}
+}
+
+type groupedErrorsRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"min=18"`
+}
+
+func TestBindWithGroupedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []BindOption
+		grouped bool
+	}{
+		{"grouped", []BindOption{BindWithGroupedErrors()}, true},
+		{"flat by default", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(Binder(tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				var req groupedErrorsRequest
+				if !JSON(r, &req) {
+					return
+				}
+				SetResponse(r, http.StatusOK, req)
+			})))
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(`{"age": 12}`))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+
+			var resp map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			apiErr := resp["error"]
+
+			if !tt.grouped {
+				if len(apiErr.Errors) != 3 || apiErr.ErrorsByCode != nil {
+					t.Errorf("expected 3 flat errors and no errors_by_code, got %v / %v", apiErr.Errors, apiErr.ErrorsByCode)
+				}
+				return
+			}
+
+			if apiErr.Errors != nil {
+				t.Errorf("expected no flat errors, got %v", apiErr.Errors)
+			}
+			if got := apiErr.ErrorsByCode["required"]; !slices.Equal(got, []string{"name", "email"}) {
+				t.Errorf("expected required: [name email], got %v", got)
+			}
+			if got := apiErr.ErrorsByCode["min"]; !slices.Equal(got, []string{"age"}) {
+				t.Errorf("expected min: [age], got %v", got)
+			}
+		})
+	}
+}
+
+func TestBindWithUseNumber(t *testing.T) {
+	// 2^53 + 1 is not representable as float64.
+	const body = `{"id": 9007199254740993, "metadata": {"parent_id": 9007199254740993}}`
+
+	type Request struct {
+		ID       any            `json:"id"`
+		Metadata map[string]any `json:"metadata"`
+	}
+
+	tests := []struct {
+		name     string
+		opts     []BindOption
+		expected string
+	}{
+		{"default float64", nil, `{"id":9007199254740992,"metadata":{"parent_id":9007199254740992}}`},
+		{"use number", []BindOption{BindWithUseNumber()}, `{"id":9007199254740993,"metadata":{"parent_id":9007199254740993}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded Request
+			handler := Handler()(Binder(tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				if !JSON(r, &decoded) {
+					return
+				}
+				SetResponse(r, http.StatusOK, decoded)
+			})))
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.expected {
+				t.Errorf("expected round-trip body %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("json.Number value", func(t *testing.T) {
+		handler := Handler()(Binder(BindWithUseNumber())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			var req Request
+			if !JSON(r, &req) {
+				return
+			}
+			n, ok := req.Metadata["parent_id"].(json.Number)
+			if !ok {
+				t.Fatalf("expected json.Number, got %T", req.Metadata["parent_id"])
+			}
+			if id, err := n.Int64(); err != nil || id != 9007199254740993 {
+				t.Errorf("expected id 9007199254740993, got %d (err %v)", id, err)
+			}
+			SetResponse(r, http.StatusNoContent, nil)
+		})))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	})
+}
+
+func TestBindWithMaxDepthAndTokens(t *testing.T) {
+	deep := strings.Repeat(`{"a":`, 50) + "1" + strings.Repeat("}", 50)
+
+	tests := []struct {
+		name        string
+		opts        []BindOption
+		body        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{"normal input passes", []BindOption{BindWithMaxDepth(5), BindWithMaxTokens(20)}, `{"tags": ["a", "b"], "meta": {"k": 1}}`, http.StatusOK, ""},
+		{"depth at limit passes", []BindOption{BindWithMaxDepth(3)}, `{"meta": {"k": [1]}}`, http.StatusOK, ""},
+		{"too deep", []BindOption{BindWithMaxDepth(10)}, deep, http.StatusBadRequest, "Invalid JSON request body: nested deeper than 10 levels"},
+		{"too many tokens", []BindOption{BindWithMaxTokens(10)}, `{"tags": [` + strings.Repeat(`"x",`, 20) + `"x"]}`, http.StatusBadRequest, "Invalid JSON request body: more than 10 tokens"},
+		{"syntax errors still reported", []BindOption{BindWithMaxDepth(5)}, `{"tags": [}`, http.StatusBadRequest, "Invalid JSON request body: invalid character '}' looking for beginning of value at offset 11"},
+		{"unlimited by default", nil, deep, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(Binder(tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				var dest struct {
+					Tags []string `json:"tags"`
+					Meta any      `json:"meta"`
+					A    any      `json:"a"`
+				}
+				if !JSON(r, &dest) {
+					return
+				}
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantMessage == "" {
+				return
+			}
+			var resp map[string]APIError
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["error"].Message != tt.wantMessage {
+				t.Errorf("expected message %q, got %q", tt.wantMessage, resp["error"].Message)
+			}
+		})
+	}
+}
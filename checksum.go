@@ -0,0 +1,95 @@
+package chikit
+
+// Response checksum trailers for integrity-checked downloads.
+// Hashes the response body as it is written, so streamed responses are covered
+// without buffering, and sends the digest as a trailer after the body.
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// checksumTrailer is the trailer carrying the body digest, in RFC 3230 form
+// ("SHA-256=<base64>").
+const checksumTrailer = "Digest"
+
+// checksumAlgorithms maps the RFC 3230 algorithm names accepted by WithChecksumTrailer
+// to their hash constructors.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"SHA-256": sha256.New,
+	"SHA-512": sha512.New,
+}
+
+// checksumWriter hashes the bytes written through it and declares the Digest
+// trailer before the headers are sent.
+type checksumWriter struct {
+	http.ResponseWriter
+	algorithm string
+	hash      hash.Hash
+	declared  bool
+	wroteHead bool
+}
+
+func newChecksumWriter(w http.ResponseWriter, algorithm string) *checksumWriter {
+	return &checksumWriter{ResponseWriter: w, algorithm: algorithm, hash: checksumAlgorithms[algorithm]()}
+}
+
+// wrapChecksum wraps w in a checksumWriter when algorithm is set. The returned
+// finish sets the trailer and must be called after the response is written.
+func wrapChecksum(w http.ResponseWriter, algorithm string) (http.ResponseWriter, func()) {
+	if algorithm == "" {
+		return w, func() {}
+	}
+	checksum := newChecksumWriter(w, algorithm)
+	return checksum, checksum.finish
+}
+
+func (w *checksumWriter) WriteHeader(status int) {
+	if !w.wroteHead {
+		w.wroteHead = true
+		// Responses that cannot have a body cannot have trailers either
+		if status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified {
+			w.Header().Add("Trailer", checksumTrailer)
+			w.declared = true
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *checksumWriter) Write(b []byte) (int, error) {
+	if !w.wroteHead {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.hash.Write(b[:n])
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *checksumWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish sets the declared trailer to the digest of the bytes written.
+func (w *checksumWriter) finish() {
+	if w.declared {
+		w.Header().Set(checksumTrailer, w.algorithm+"="+base64.StdEncoding.EncodeToString(w.hash.Sum(nil)))
+	}
+}
+
+// normalizeChecksumAlgorithm returns the RFC 3230 name for algorithm ("sha256",
+// "SHA-256", ...), or "" if it is not supported.
+func normalizeChecksumAlgorithm(algorithm string) string {
+	name := strings.ToUpper(algorithm)
+	if !strings.Contains(name, "-") && strings.HasPrefix(name, "SHA") {
+		name = "SHA-" + name[3:]
+	}
+	if _, ok := checksumAlgorithms[name]; !ok {
+		return ""
+	}
+	return name
+}
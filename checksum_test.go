@@ -0,0 +1,88 @@
+package chikit
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithChecksumTrailer(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"buffered", func(_ http.ResponseWriter, r *http.Request) {
+			SetResponse(r, http.StatusOK, map[string]string{"report": strings.Repeat("x", 100)})
+		}},
+		{"streamed", func(_ http.ResponseWriter, r *http.Request) {
+			SetStream(r, http.StatusOK, func(w io.Writer) error {
+				for i := range 5 {
+					if _, err := io.WriteString(w, strings.Repeat(string(rune('a'+i)), 1000)); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}},
+		{"error", func(_ http.ResponseWriter, r *http.Request) {
+			SetError(r, ErrNotFound)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handler(WithChecksumTrailer("sha-256"))(tt.handler)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+			resp := rec.Result()
+			body, _ := io.ReadAll(resp.Body)
+			if got := resp.Header.Get("Trailer"); got != "Digest" {
+				t.Errorf("expected Digest to be declared in Trailer, got %q", got)
+			}
+			sum := sha256.Sum256(body)
+			want := "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+			if got := resp.Trailer.Get("Digest"); got != want {
+				t.Errorf("expected trailer %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestWithChecksumTrailer_Algorithms(t *testing.T) {
+	h := Handler(WithChecksumTrailer("SHA512"))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, "payload")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	resp := rec.Result()
+	body, _ := io.ReadAll(resp.Body)
+	sum := sha512.Sum512(body)
+	if got, want := resp.Trailer.Get("Digest"), "SHA-512="+base64.StdEncoding.EncodeToString(sum[:]); got != want {
+		t.Errorf("expected trailer %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected unsupported algorithm to panic")
+		}
+	}()
+	WithChecksumTrailer("crc32")
+}
+
+func TestWithChecksumTrailer_NoBody(t *testing.T) {
+	h := Handler(WithChecksumTrailer("sha-256"))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusNoContent, nil)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", "/", nil))
+
+	if got := rec.Header().Get("Trailer"); got != "" {
+		t.Errorf("expected no trailer for 204, got %q", got)
+	}
+}
@@ -0,0 +1,168 @@
+package chikit
+
+// Access logging in Apache Common/Combined Log Format for traditional log
+// pipelines (GoAccess, AWStats, logrotate-based tooling).
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clfTimeFormat is the CLF timestamp layout, e.g. 10/Oct/2000:13:55:36 -0700.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+type clfConfig struct {
+	common bool
+}
+
+// CLFOption configures CLFLog middleware.
+type CLFOption func(*clfConfig)
+
+// CLFWithCommonFormat writes Common Log Format lines, omitting the referer and
+// user-agent fields of the default Combined Log Format.
+func CLFWithCommonFormat() CLFOption {
+	return func(c *clfConfig) {
+		c.common = true
+	}
+}
+
+// CLFLog returns middleware that writes one Apache Combined Log Format line per
+// request to out:
+//
+//	192.0.2.1 - alice [10/Oct/2000:13:55:36 -0700] "GET /users?page=2 HTTP/1.1" 200 2326 "https://example.com/" "Mozilla/5.0"
+//
+// The user is the HTTP Basic auth username, or "-". Status and bytes come from
+// the response writer, so register CLFLog outside (before) Handler. If registered
+// inside Handler, the status is taken from the response state and bytes are logged as "-".
+// Writes to out are serialized.
+//
+// Example:
+//
+//	r.Use(chikit.CLFLog(os.Stdout))
+//	r.Use(chikit.Handler())
+func CLFLog(out io.Writer, opts ...CLFOption) func(http.Handler) http.Handler {
+	cfg := &clfConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var mu sync.Mutex
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			cw := &clfWriter{ResponseWriter: w}
+
+			next.ServeHTTP(cw, r)
+
+			status := cw.status
+			if status == 0 && cw.bytes == 0 {
+				if state := getState(r.Context()); state != nil {
+					snap := state.snapshot()
+					status = snap.status
+					if snap.err != nil {
+						status = snap.err.Status
+					}
+				}
+			}
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			line := formatCLFLine(cfg, r, start, status, cw.bytes)
+			mu.Lock()
+			io.WriteString(out, line)
+			mu.Unlock()
+		})
+	}
+}
+
+func formatCLFLine(cfg *clfConfig, r *http.Request, start time.Time, status int, bytes int64) string {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	user := "-"
+	if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = clfEscape(name)
+	}
+
+	size := "-"
+	if bytes > 0 {
+		size = strconv.FormatInt(bytes, 10)
+	}
+
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+
+	line := host + " - " + user + " [" + start.Format(clfTimeFormat) + "] " +
+		strconv.Quote(r.Method+" "+uri+" "+r.Proto) + " " +
+		strconv.Itoa(status) + " " + size
+	if !cfg.common {
+		line += " " + clfQuote(r.Referer()) + " " + clfQuote(r.UserAgent())
+	}
+	return line + "\n"
+}
+
+// clfEscape escapes an unquoted field the way Apache does, writing control bytes,
+// spaces, non-ASCII bytes, quotes, and backslashes as \xhh, so client-supplied
+// values cannot split the line or shift fields.
+func clfEscape(v string) string {
+	const hex = "0123456789abcdef"
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '\\' {
+			b.WriteString(`\x`)
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// clfQuote quotes a header value, logging empty values as "-".
+func clfQuote(v string) string {
+	if v == "" {
+		return `"-"`
+	}
+	return strconv.Quote(v)
+}
+
+// clfWriter records the status code and body size written to the client.
+type clfWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *clfWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *clfWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *clfWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
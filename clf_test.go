@@ -0,0 +1,86 @@
+package chikit
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCLFLog_CombinedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	handler := CLFLog(&buf)(Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusCreated, map[string]string{"id": "1"})
+	})))
+
+	req := httptest.NewRequest("POST", "/users?src=web", http.NoBody)
+	req.RemoteAddr = "192.0.2.1:4321"
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", `Mozilla/5.0 "test"`)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	pattern := regexp.MustCompile(`^192\.0\.2\.1 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] ` +
+		`"POST /users\?src=web HTTP/1\.1" 201 (\d+) "https://example\.com/" "Mozilla/5\.0 \\"test\\""\n$`)
+	m := pattern.FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("malformed CLF line: %q", buf.String())
+	}
+	if m[1] != "11" {
+		t.Errorf("expected 11 bytes, got %s", m[1])
+	}
+}
+
+func TestCLFLog_EscapesUser(t *testing.T) {
+	var buf bytes.Buffer
+	handler := CLFLog(&buf)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.RemoteAddr = "192.0.2.1:4321"
+	req.SetBasicAuth("a\nb c", "secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := buf.String()
+	if !strings.HasPrefix(line, `192.0.2.1 - a\x0ab\x20c [`) {
+		t.Errorf("expected escaped user, got %q", line)
+	}
+	if strings.Count(line, "\n") != 1 {
+		t.Errorf("expected a single log line, got %q", line)
+	}
+}
+
+func TestCLFLog_CommonFormat(t *testing.T) {
+	var buf bytes.Buffer
+	handler := CLFLog(&buf, CLFWithCommonFormat())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("DELETE", "/users/1", http.NoBody)
+	req.RemoteAddr = "[2001:db8::1]:4321"
+	req.Header.Set("User-Agent", "curl/8.0")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	pattern := regexp.MustCompile(`^2001:db8::1 - - \[[^\]]+\] "DELETE /users/1 HTTP/1\.1" 204 -\n$`)
+	if !pattern.MatchString(buf.String()) {
+		t.Errorf("malformed CLF line: %q", buf.String())
+	}
+}
+
+func TestCLFLog_InsideHandlerUsesState(t *testing.T) {
+	var buf bytes.Buffer
+	handler := Handler()(CLFLog(&buf)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetError(r, ErrNotFound)
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", http.NoBody))
+
+	pattern := regexp.MustCompile(`"GET /missing HTTP/1\.1" 404 - "-" "-"\n$`)
+	if !pattern.MatchString(buf.String()) {
+		t.Errorf("expected status from state, got %q", buf.String())
+	}
+}
@@ -0,0 +1,68 @@
+package chikit
+
+// Response compression for WithCompression.
+// Handler buffers the whole response, so bodies are compressed in one pass right
+// before they are written, without a streaming writer.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+)
+
+// negotiateCompression picks the coding to compress responses with from an
+// Accept-Encoding header: the client's most preferred of gzip and deflate, gzip for
+// "*", or "" if neither is acceptable.
+func negotiateCompression(header string) string {
+	for _, enc := range ParseAcceptEncoding(header) {
+		switch enc.Name {
+		case "gzip", "deflate":
+			return enc.Name
+		case "*":
+			return "gzip"
+		}
+	}
+	return ""
+}
+
+// compressibleType reports whether a body with contentType benefits from compression.
+// Formats that are already compressed are excluded.
+func compressibleType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "font/woff"):
+		return false
+	}
+	switch mediaType {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+		"application/x-bzip2", "application/x-xz", "application/x-7z-compressed", "application/x-rar-compressed":
+		return false
+	}
+	return true
+}
+
+// compressBody compresses body with encoding ("gzip" or "deflate").
+func compressBody(encoding string, body []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	var zw io.WriteCloser
+	if encoding == "deflate" {
+		zw = zlib.NewWriter(buf)
+	} else {
+		zw = gzip.NewWriter(buf)
+	}
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,130 @@
+package chikit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithCompression(t *testing.T) {
+	large := map[string]string{"data": strings.Repeat("a", 2048)}
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		handler          http.Handler
+		expectedEncoding string
+		expectVary       bool
+	}{
+		{
+			name:             "gzip",
+			acceptEncoding:   "gzip, deflate",
+			handler:          http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { SetResponse(r, http.StatusOK, large) }),
+			expectedEncoding: "gzip",
+			expectVary:       true,
+		},
+		{
+			name:             "deflate preferred",
+			acceptEncoding:   "gzip;q=0.5, deflate",
+			handler:          http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { SetResponse(r, http.StatusOK, large) }),
+			expectedEncoding: "deflate",
+			expectVary:       true,
+		},
+		{
+			name:           "client does not accept compression",
+			acceptEncoding: "br",
+			handler:        http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { SetResponse(r, http.StatusOK, large) }),
+			expectVary:     true,
+		},
+		{
+			name:           "below threshold",
+			acceptEncoding: "gzip",
+			handler: http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				SetResponse(r, http.StatusOK, map[string]string{"id": "1"})
+			}),
+		},
+		{
+			name:           "error response",
+			acceptEncoding: "gzip",
+			handler: http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				SetError(r, ErrBadRequest.With(strings.Repeat("b", 2048)))
+			}),
+			expectedEncoding: "gzip",
+			expectVary:       true,
+		},
+		{
+			name:           "already compressed type",
+			acceptEncoding: "gzip",
+			handler: Adapt(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				w.Write(bytes.Repeat([]byte{0x89}, 2048))
+			})),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(WithCompression(1024))(tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.expectedEncoding {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.expectedEncoding, got)
+			}
+			if got := rec.Header().Get("Vary") == "Accept-Encoding"; got != tt.expectVary {
+				t.Errorf("expected Vary: Accept-Encoding %v, got %q", tt.expectVary, rec.Header().Get("Vary"))
+			}
+
+			var body io.Reader = rec.Body
+			switch tt.expectedEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("invalid gzip body: %v", err)
+				}
+				body = zr
+			case "deflate":
+				zr, err := zlib.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("invalid deflate body: %v", err)
+				}
+				body = zr
+			}
+			if rec.Header().Get("Content-Type") == "application/json" {
+				var decoded map[string]any
+				if err := json.NewDecoder(body).Decode(&decoded); err != nil {
+					t.Errorf("failed to decode body: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip;q=0.8", "deflate"},
+		{"br, *;q=0.1", "gzip"},
+		{"gzip;q=0", ""},
+		{"identity", ""},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateCompression(tt.header); got != tt.expected {
+			t.Errorf("negotiateCompression(%q) = %q, want %q", tt.header, got, tt.expected)
+		}
+	}
+}
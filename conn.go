@@ -0,0 +1,97 @@
+package chikit
+
+// Per-connection request limiting to mitigate keep-alive abuse.
+//
+// Requires registering ConnContext on the http.Server so each TCP connection
+// gets its own request counter:
+//
+//	srv := &http.Server{
+//		Addr:        ":8080",
+//		Handler:     r,
+//		ConnContext: chikit.ConnContext,
+//	}
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+type connContextKey string
+
+const connCounterKey connContextKey = "conn_counter"
+
+// ConnContext attaches a per-connection request counter to the connection's base context.
+// Assign it to http.Server.ConnContext to enable MaxRequestsPerConn.
+func ConnContext(ctx context.Context, _ net.Conn) context.Context {
+	return context.WithValue(ctx, connCounterKey, new(atomic.Int64))
+}
+
+type connLimitConfig struct {
+	reject bool
+}
+
+// ConnLimitOption configures MaxRequestsPerConn middleware.
+type ConnLimitOption func(*connLimitConfig)
+
+// ConnLimitWithReject returns 429 (Too Many Requests) for requests beyond the cap
+// instead of serving them. By default, excess requests are served with
+// Connection: close, since the client is expected to reconnect.
+func ConnLimitWithReject() ConnLimitOption {
+	return func(c *connLimitConfig) {
+		c.reject = true
+	}
+}
+
+// MaxRequestsPerConn returns middleware that caps the number of requests served on a
+// single keep-alive connection. The nth request and any after it are sent with
+// Connection: close so net/http closes the connection once the response is written.
+// With ConnLimitWithReject, requests beyond n are rejected with 429.
+//
+// The http.Server must set ConnContext to chikit.ConnContext; without it, requests
+// have no connection counter and pass through unchanged.
+//
+// Example:
+//
+//	r.Use(chikit.MaxRequestsPerConn(1000))
+//	srv := &http.Server{Handler: r, ConnContext: chikit.ConnContext}
+func MaxRequestsPerConn(n int, opts ...ConnLimitOption) func(http.Handler) http.Handler {
+	cfg := &connLimitConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	limit := int64(n)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counter, ok := r.Context().Value(connCounterKey).(*atomic.Int64)
+			if !ok || limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			count := counter.Add(1)
+			if count < limit {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			useWrapper := HasState(r.Context())
+			if useWrapper {
+				SetHeader(r, "Connection", "close")
+			} else {
+				w.Header().Set("Connection", "close")
+			}
+
+			if count > limit && cfg.reject {
+				errMsg := "Too many requests on this connection: limit is " + strconv.FormatInt(limit, 10)
+				respond(w, r, ErrRateLimited.With(errMsg))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
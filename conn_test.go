@@ -0,0 +1,118 @@
+package chikit
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMaxRequestsPerConn_ClosesAtCap(t *testing.T) {
+	handler := Handler()(MaxRequestsPerConn(3)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	connCtx := ConnContext(context.Background(), nil)
+
+	for i := 1; i <= 4; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody).WithContext(connCtx)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("request %d: expected status %d, got %d", i, http.StatusOK, rec.Code)
+		}
+		conn := rec.Header().Get("Connection")
+		if i < 3 && conn != "" {
+			t.Errorf("request %d: expected no Connection header, got %q", i, conn)
+		}
+		if i >= 3 && conn != "close" {
+			t.Errorf("request %d: expected Connection: close, got %q", i, conn)
+		}
+	}
+}
+
+func TestMaxRequestsPerConn_Reject(t *testing.T) {
+	handler := Handler()(MaxRequestsPerConn(2, ConnLimitWithReject())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	connCtx := ConnContext(context.Background(), nil)
+	expected := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+
+	for i, want := range expected {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody).WithContext(connCtx)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != want {
+			t.Errorf("request %d: expected status %d, got %d", i+1, want, rec.Code)
+		}
+	}
+}
+
+func TestMaxRequestsPerConn_SeparateConnections(t *testing.T) {
+	handler := MaxRequestsPerConn(1, ConnLimitWithReject())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := range 3 {
+		connCtx := ConnContext(context.Background(), nil)
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody).WithContext(connCtx)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("connection %d: expected status %d, got %d", i+1, http.StatusOK, rec.Code)
+		}
+	}
+}
+
+func TestMaxRequestsPerConn_WithoutConnContext(t *testing.T) {
+	handler := MaxRequestsPerConn(1, ConnLimitWithReject())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("request %d: expected status %d, got %d", i+1, http.StatusOK, rec.Code)
+		}
+	}
+}
+
+func TestMaxRequestsPerConn_Server(t *testing.T) {
+	var newConns atomic.Int64
+
+	srv := httptest.NewUnstartedServer(MaxRequestsPerConn(2)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	srv.Config.ConnContext = ConnContext
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	client := srv.Client()
+	for i := range 4 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i+1, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if got := newConns.Load(); got != 2 {
+		t.Errorf("expected 2 connections for 4 requests with cap 2, got %d", got)
+	}
+}
@@ -0,0 +1,84 @@
+package chikit
+
+// Request body length verification.
+// Catches aborted or truncated uploads whose body ends before the declared
+// Content-Length, so handlers do not act on partially decoded input.
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+type contentLengthContextKey string
+
+const contentLengthBodyKey contentLengthContextKey = "content_length_body"
+
+// errIncompleteBody is returned from body reads once the body is known not to match
+// its declared Content-Length.
+var errIncompleteBody = errors.New("request body does not match Content-Length")
+
+// contentLengthBody counts bytes read from a request body and records a mismatch
+// with the declared Content-Length.
+type contentLengthBody struct {
+	io.ReadCloser
+	declared   int64
+	read       int64
+	incomplete atomic.Bool
+}
+
+func (b *contentLengthBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	ended := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if b.read > b.declared || (ended && b.read != b.declared) {
+		b.incomplete.Store(true)
+		return n, errIncompleteBody
+	}
+	return n, err
+}
+
+// VerifyContentLength returns middleware that checks request bodies against their
+// declared Content-Length. A body that ends early (an aborted upload) or runs past
+// the declared length fails the read with an error instead of io.EOF, so decoders
+// never see a silently truncated body.
+//
+// When wrapper middleware is present and the handler read far enough to detect the
+// mismatch, the response is replaced with 400 ErrBadRequest "Incomplete request
+// body" after the handler returns. Without it, handlers can check BodyComplete.
+// Requests without a Content-Length (chunked transfers) are not checked.
+//
+// Example:
+//
+//	r.Use(chikit.Handler())
+//	r.Use(chikit.VerifyContentLength())
+func VerifyContentLength() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength < 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body := &contentLengthBody{ReadCloser: r.Body, declared: r.ContentLength}
+			r.Body = body
+			r = r.WithContext(context.WithValue(r.Context(), contentLengthBodyKey, body))
+			next.ServeHTTP(w, r)
+
+			if body.incomplete.Load() && HasState(r.Context()) {
+				SetError(r, ErrBadRequest.With("Incomplete request body"))
+			}
+		})
+	}
+}
+
+// BodyComplete reports whether the request body has matched its declared
+// Content-Length so far. Returns false once VerifyContentLength has seen the body
+// end early or exceed the declared length; returns true otherwise, including when
+// the middleware is not active or the body has not been read to the end.
+func BodyComplete(r *http.Request) bool {
+	body, ok := r.Context().Value(contentLengthBodyKey).(*contentLengthBody)
+	return !ok || !body.incomplete.Load()
+}
@@ -0,0 +1,90 @@
+package chikit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyContentLength(t *testing.T) {
+	h := Handler()(VerifyContentLength()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req CreateUserRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	})))
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		wantStatus    int
+		wantMessage   string
+	}{
+		{"complete", `{"email": "a@example.com", "age": 30}`, 37, http.StatusOK, ""},
+		{"truncated", `{"email": "a@exa`, 37, http.StatusBadRequest, "Incomplete request body"},
+		{"longer than declared", `{"email": "a@example.com", "age": 30}  `, 37, http.StatusBadRequest, "Incomplete request body"},
+		{"chunked", `{"email": "a@example.com", "age": 30}`, -1, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantMessage == "" {
+				return
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Error.Code != ErrorCodeBadRequest || resp.Error.Message != tt.wantMessage {
+				t.Errorf("expected bad_request %q, got %s %q", tt.wantMessage, resp.Error.Code, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestBodyComplete(t *testing.T) {
+	var complete bool
+	var readErr error
+	h := VerifyContentLength()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+		complete = BodyComplete(r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("short"))
+	req.ContentLength = 100
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if complete {
+		t.Error("expected BodyComplete to report a truncated body")
+	}
+	if readErr == nil {
+		t.Error("expected reading a truncated body to fail")
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected handler response without wrapper, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader("exact"))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if !complete || readErr != nil {
+		t.Errorf("expected complete body, got complete=%v err=%v", complete, readErr)
+	}
+
+	if !BodyComplete(httptest.NewRequest("POST", "/", strings.NewReader("x"))) {
+		t.Error("expected BodyComplete to be true without the middleware")
+	}
+}
@@ -0,0 +1,57 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentSecurityPolicy_Nonce(t *testing.T) {
+	var handlerNonce string
+	h := Handler()(ContentSecurityPolicy("script-src 'nonce-{nonce}'; style-src 'nonce-{nonce}'")(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		handlerNonce = CSPNonce(r)
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	serve := func() (policy, nonce string) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		return rec.Header().Get("Content-Security-Policy"), handlerNonce
+	}
+
+	policy, nonce := serve()
+	if len(nonce) < 20 {
+		t.Fatalf("expected a random nonce, got %q", nonce)
+	}
+	if want := "script-src 'nonce-" + nonce + "'; style-src 'nonce-" + nonce + "'"; policy != want {
+		t.Errorf("expected policy %q, got %q", want, policy)
+	}
+
+	_, next := serve()
+	if next == nonce {
+		t.Error("expected a different nonce per request")
+	}
+}
+
+func TestContentSecurityPolicy_WithoutPlaceholder(t *testing.T) {
+	var nonce, again string
+	h := ContentSecurityPolicy("default-src 'self'")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce, again = CSPNonce(r), CSPNonce(r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if got := rec.Header().Get("Content-Security-Policy"); got != "default-src 'self'" {
+		t.Errorf("expected policy unchanged without wrapper, got %q", got)
+	}
+	if nonce == "" || nonce != again {
+		t.Errorf("expected a stable lazily generated nonce, got %q and %q", nonce, again)
+	}
+}
+
+func TestCSPNonce_WithoutMiddleware(t *testing.T) {
+	if nonce := CSPNonce(httptest.NewRequest("GET", "/", nil)); nonce != "" {
+		t.Errorf("expected empty nonce without middleware, got %q", nonce)
+	}
+}
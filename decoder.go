@@ -0,0 +1,101 @@
+package chikit
+
+// Content-Type aware request body binding.
+// Bind selects a decoder registered for the request's media type, so one handler
+// can accept JSON, XML, or any other body format with the same validation.
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// BodyDecoder decodes a request body into dest.
+type BodyDecoder func(body io.Reader, dest any) error
+
+var (
+	bodyDecoders   = map[string]BodyDecoder{}
+	bodyDecodersMu sync.RWMutex
+)
+
+// RegisterBodyDecoder registers fn to decode request bodies with the given media type
+// (e.g., "application/xml") for Bind. Media types are matched case-insensitively and
+// without parameters such as charset. Registering a type again replaces its decoder.
+// Must be called at startup before handling requests.
+//
+// Example:
+//
+//	chikit.RegisterBodyDecoder("application/xml", func(body io.Reader, dest any) error {
+//		return xml.NewDecoder(body).Decode(dest)
+//	})
+func RegisterBodyDecoder(contentType string, fn BodyDecoder) {
+	bodyDecodersMu.Lock()
+	defer bodyDecodersMu.Unlock()
+	bodyDecoders[strings.ToLower(contentType)] = fn
+}
+
+// Bind decodes the request body into dest using the decoder for its Content-Type,
+// then validates it. Returns true if binding and validation succeeded, false otherwise.
+// When binding fails, an error is set in the wrapper context (if available).
+//
+// JSON bodies (application/json, any +json type, or no Content-Type) are bound with
+// JSON, so all BindWith options apply. Other types use decoders added with
+// RegisterBodyDecoder; a registered decoder for a JSON type takes precedence.
+// Unregistered types return 415 (Unsupported Media Type).
+//
+// Example:
+//
+//	var req CreateUserRequest
+//	if !chikit.Bind(r, &req) {
+//		return
+//	}
+func Bind(r *http.Request, dest any) bool {
+	ctx := r.Context()
+
+	mediaType := "application/json"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		parsed, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			if HasState(ctx) {
+				SetError(r, ErrUnsupportedMedia.With("Invalid Content-Type"))
+			}
+			return false
+		}
+		mediaType = parsed
+	}
+
+	bodyDecodersMu.RLock()
+	decode, ok := bodyDecoders[mediaType]
+	bodyDecodersMu.RUnlock()
+
+	if !ok {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return JSON(r, dest)
+		}
+		if HasState(ctx) {
+			SetError(r, ErrUnsupportedMedia.With("Unsupported Content-Type: "+mediaType))
+		}
+		return false
+	}
+
+	var body io.Reader = r.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	if err := decode(body, dest); err != nil {
+		if HasState(ctx) {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				SetError(r, ErrPayloadTooLarge.With("Request body too large"))
+			} else {
+				SetError(r, ErrBadRequest.With("Invalid request body"))
+			}
+		}
+		return false
+	}
+
+	return validateBound(r, getBindConfig(ctx), dest)
+}
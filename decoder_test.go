@@ -0,0 +1,82 @@
+package chikit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBind_SelectsDecoderByContentType(t *testing.T) {
+	RegisterBodyDecoder("application/xml", func(body io.Reader, dest any) error {
+		return xml.NewDecoder(body).Decode(dest)
+	})
+
+	type CreateUser struct {
+		XMLName xml.Name `json:"-" xml:"user"`
+		Email   string   `json:"email" xml:"email" validate:"required,email"`
+		Age     int      `json:"age" xml:"age" validate:"min=18"`
+	}
+
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req CreateUser
+		if !Bind(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, req)
+	}))
+
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		expectedCode int
+		expectedType ErrorType
+	}{
+		{"json", "application/json", `{"email": "a@example.com", "age": 30}`, http.StatusOK, ""},
+		{"json suffix", "application/vnd.api+json", `{"email": "a@example.com", "age": 30}`, http.StatusOK, ""},
+		{"no content type", "", `{"email": "a@example.com", "age": 30}`, http.StatusOK, ""},
+		{"xml", "application/xml; charset=utf-8", `<user><email>a@example.com</email><age>30</age></user>`, http.StatusOK, ""},
+		{"xml validation failure", "Application/XML", `<user><email>a@example.com</email><age>12</age></user>`, http.StatusBadRequest, ErrorTypeValidation},
+		{"malformed xml", "application/xml", `<user><email>`, http.StatusBadRequest, ErrorTypeRequest},
+		{"unregistered type", "text/csv", "email,age\na@example.com,30", http.StatusUnsupportedMediaType, ErrorTypeRequest},
+		{"invalid content type", "application/", `{}`, http.StatusUnsupportedMediaType, ErrorTypeRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body.String())
+			}
+
+			if tt.expectedCode == http.StatusOK {
+				var resp CreateUser
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Email != "a@example.com" || resp.Age != 30 {
+					t.Errorf("expected bound email and age, got %+v", resp)
+				}
+				return
+			}
+
+			var resp map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["error"].Type != tt.expectedType {
+				t.Errorf("expected error type %s, got %s", tt.expectedType, resp["error"].Type)
+			}
+		})
+	}
+}
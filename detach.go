@@ -0,0 +1,94 @@
+package chikit
+
+// Detached contexts for fire-and-forget work that must outlive the request but
+// not the server: audit writes, cache warming, webhook delivery.
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// detachMu guards detachCtx and detachCancel.
+var detachMu sync.Mutex
+
+// detachCtx is the parent of every detached context; Shutdown cancels it.
+var detachCtx, detachCancel = context.WithCancel(context.Background())
+
+// detachedCount tracks goroutines started with GoDetached for WaitForHandlers.
+var detachedCount atomic.Int64
+
+// Detach returns a context that carries r's values (request ID, tenant, canonlog
+// logger, etc.) but is not cancelled when the request ends or its deadline passes.
+// It is cancelled when Shutdown is called, so detached work still stops with the
+// server. After Shutdown, Detach returns an already-cancelled context.
+//
+// Prefer GoDetached, which also tracks the goroutine so shutdown can drain it.
+//
+// Example:
+//
+//	ctx := chikit.Detach(r)
+//	go audit.Record(ctx, event)
+func Detach(r *http.Request) context.Context {
+	detachMu.Lock()
+	parent := detachCtx
+	detachMu.Unlock()
+
+	return detachedContext{Context: context.WithoutCancel(r.Context()), shutdown: parent}
+}
+
+// detachedContext takes its values from the request and its cancellation from the
+// shutdown context. Delegating Done and Err, rather than registering a child with
+// the shutdown context, keeps Detach from retaining anything per request.
+type detachedContext struct {
+	context.Context
+	shutdown context.Context
+}
+
+func (c detachedContext) Done() <-chan struct{} { return c.shutdown.Done() }
+func (c detachedContext) Err() error            { return c.shutdown.Err() }
+
+// GoDetached runs fn in a new goroutine with Detach(r). The goroutine is tracked,
+// so WaitForHandlers and Shutdown wait for it to return. fn should honor ctx
+// cancellation so Shutdown can stop it promptly.
+//
+// Example:
+//
+//	r.Post("/orders", func(w http.ResponseWriter, r *http.Request) {
+//		order := createOrder(r)
+//		chikit.GoDetached(r, func(ctx context.Context) {
+//			webhooks.Deliver(ctx, order)
+//		})
+//		chikit.SetResponse(r, http.StatusCreated, order)
+//	})
+func GoDetached(r *http.Request, fn func(ctx context.Context)) {
+	ctx := Detach(r)
+	detachedCount.Add(1)
+	go func() {
+		defer detachedCount.Add(-1)
+		fn(ctx)
+	}()
+}
+
+// DetachedCount returns the number of goroutines started with GoDetached that are
+// still running.
+func DetachedCount() int {
+	return int(detachedCount.Load())
+}
+
+// Shutdown cancels every context returned by Detach, then waits like
+// WaitForHandlers for handler goroutines and detached goroutines to return.
+// Call it after http.Server.Shutdown(). Returns ctx.Err() if the context
+// deadline is exceeded first.
+//
+// Example:
+//
+//	srv.Shutdown(ctx)    // Wait for in-flight requests
+//	chikit.Shutdown(ctx) // Cancel and drain detached work
+func Shutdown(ctx context.Context) error {
+	detachMu.Lock()
+	detachCancel()
+	detachMu.Unlock()
+	return WaitForHandlers(ctx)
+}
@@ -0,0 +1,108 @@
+package chikit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type detachTestKey struct{}
+
+// resetDetach restores the package-level detach context after a test calls Shutdown.
+func resetDetach(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		detachMu.Lock()
+		detachCtx, detachCancel = context.WithCancel(context.Background())
+		detachMu.Unlock()
+	})
+}
+
+func TestDetach(t *testing.T) {
+	resetDetach(t)
+
+	var detached context.Context
+	h := Handler(WithTimeout(time.Second))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		detached = Detach(r)
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), detachTestKey{}, "tenant-1"))
+	req := httptest.NewRequest("GET", "/", http.NoBody).WithContext(ctx)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	cancel()
+
+	if got := detached.Value(detachTestKey{}); got != "tenant-1" {
+		t.Errorf("expected request values to be retained, got %v", got)
+	}
+	if !HasState(detached) {
+		t.Error("expected handler state to be retained")
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("expected no deadline on detached context")
+	}
+	select {
+	case <-detached.Done():
+		t.Fatal("detached context cancelled when request ended")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case <-detached.Done():
+	case <-time.After(time.Second):
+		t.Fatal("detached context not cancelled on shutdown")
+	}
+
+	after := Detach(httptest.NewRequest("GET", "/", http.NoBody))
+	if after.Err() == nil {
+		t.Error("expected Detach after Shutdown to return a cancelled context")
+	}
+}
+
+func TestGoDetached(t *testing.T) {
+	resetDetach(t)
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	h := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		GoDetached(r, func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			stopped <- ctx.Err()
+		})
+		SetResponse(r, http.StatusAccepted, nil)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/", http.NoBody))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	<-started
+
+	if got := DetachedCount(); got != 1 {
+		t.Errorf("expected 1 detached goroutine, got %d", got)
+	}
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := WaitForHandlers(waitCtx); err != context.DeadlineExceeded {
+		t.Errorf("expected WaitForHandlers to wait for detached goroutine, got %v", err)
+	}
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if got := DetachedCount(); got != 0 {
+		t.Errorf("expected Shutdown to drain detached goroutines, got %d running", got)
+	}
+	if err := <-stopped; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
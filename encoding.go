@@ -0,0 +1,55 @@
+package chikit
+
+// Accept-Encoding negotiation shared by response compression.
+
+import (
+	"sort"
+	"strings"
+)
+
+// Encoding is a content coding from an Accept-Encoding header with its q-value.
+type Encoding struct {
+	Name string
+	Q    float64
+}
+
+// ParseAcceptEncoding parses an Accept-Encoding header into the codings the client
+// accepts, sorted by descending q-value (ties keep header order). Names are
+// lowercased. Codings with q=0 are excluded, so "gzip;q=0" never yields gzip.
+//
+// An empty header returns identity only. Otherwise only listed codings are
+// returned; per RFC 9110, identity remains acceptable unless the header excludes
+// it with "identity;q=0" or "*;q=0".
+//
+// Example:
+//
+//	for _, enc := range chikit.ParseAcceptEncoding(r.Header.Get("Accept-Encoding")) {
+//		if enc.Name == "br" || enc.Name == "gzip" {
+//			// compress with enc.Name
+//			break
+//		}
+//	}
+func ParseAcceptEncoding(header string) []Encoding {
+	if strings.TrimSpace(header) == "" {
+		return []Encoding{{Name: "identity", Q: 1}}
+	}
+
+	var encodings []Encoding
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := parseQValue(params)
+		if q <= 0 || q > 1 {
+			continue
+		}
+		encodings = append(encodings, Encoding{Name: name, Q: q})
+	}
+
+	sort.SliceStable(encodings, func(i, j int) bool {
+		return encodings[i].Q > encodings[j].Q
+	})
+	return encodings
+}
@@ -0,0 +1,30 @@
+package chikit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected []Encoding
+	}{
+		{"empty defaults to identity", "", []Encoding{{"identity", 1}}},
+		{"q=0 disables gzip", "gzip;q=0, deflate", []Encoding{{"deflate", 1}}},
+		{"prefers brotli", "gzip;q=0.5, br;q=1.0", []Encoding{{"br", 1}, {"gzip", 0.5}}},
+		{"ties keep header order", "GZIP, br", []Encoding{{"gzip", 1}, {"br", 1}}},
+		{"wildcard", "*;q=0.1, gzip", []Encoding{{"gzip", 1}, {"*", 0.1}}},
+		{"everything disabled", "*;q=0", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAcceptEncoding(tt.header)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
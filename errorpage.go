@@ -0,0 +1,102 @@
+package chikit
+
+// HTML error pages for browser clients, negotiated from the Accept header.
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrorPageData is the data passed to the WithHTMLErrorFallback template.
+type ErrorPageData struct {
+	Status     int
+	StatusText string
+	Type       ErrorType
+	Code       ErrorCode
+	Message    string
+}
+
+// writeHTMLError renders the error page if state holds an error and the client
+// prefers HTML. Returns false if nothing was written and the caller should fall
+// back to writeResponse.
+func writeHTMLError(w http.ResponseWriter, tmpl *template.Template, state *State) bool {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.err == nil || !prefersHTML(state.accept) {
+		return false
+	}
+
+	buf := new(bytes.Buffer)
+	data := ErrorPageData{
+		Status:     state.err.Status,
+		StatusText: http.StatusText(state.err.Status),
+		Type:       state.err.Type,
+		Code:       state.err.Code,
+		Message:    state.err.Message,
+	}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return false
+	}
+
+	writeStateHeaders(w, state)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(state.err.Status)
+	w.Write(buf.Bytes())
+	return true
+}
+
+// prefersHTML reports whether the Accept header ranks text/html above
+// application/json. Ties (including */*) go to JSON.
+func prefersHTML(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	return acceptQuality(accept, "text/html") > acceptQuality(accept, "application/json")
+}
+
+// acceptQuality returns the q-value the Accept header assigns to mediaType,
+// using the most specific matching range (exact, then type/*, then */*).
+func acceptQuality(accept, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	best, bestSpecificity := 0.0, -1
+
+	for _, part := range strings.Split(accept, ",") {
+		rng, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		rng = strings.ToLower(strings.TrimSpace(rng))
+
+		specificity := -1
+		switch rng {
+		case mediaType:
+			specificity = 2
+		case mainType + "/*":
+			specificity = 1
+		case "*/*":
+			specificity = 0
+		}
+		if specificity <= bestSpecificity {
+			continue
+		}
+
+		best, bestSpecificity = parseQValue(params), specificity
+	}
+	return best
+}
+
+// parseQValue returns the q parameter from a semicolon-separated parameter list,
+// or 1.0 if absent or unparseable.
+func parseQValue(params string) float64 {
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok && strings.EqualFold(name, "q") {
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				q = v
+			}
+		}
+	}
+	return q
+}
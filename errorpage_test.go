@@ -0,0 +1,114 @@
+package chikit
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testErrorPage = template.Must(template.New("error").Parse(
+	`<h1>{{.Status}} {{.StatusText}}</h1><p>{{.Message}}</p>`))
+
+func TestWithHTMLErrorFallback(t *testing.T) {
+	handler := Handler(WithHTMLErrorFallback(testErrorPage))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetHeader(r, "Retry-After", "30")
+		SetError(r, ErrInternal.With("Database <unavailable>"))
+	}))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("expected HTML content type, got %s", ct)
+	}
+	if rec.Header().Get("Retry-After") != "30" {
+		t.Error("expected state headers on HTML error page")
+	}
+	want := "<h1>500 Internal Server Error</h1><p>Database &lt;unavailable&gt;</p>"
+	if rec.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, rec.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type, got %s", ct)
+	}
+	var resp map[string]*APIError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp["error"].Message != "Database <unavailable>" {
+		t.Errorf("expected JSON envelope, got %v", resp["error"])
+	}
+}
+
+func TestWithHTMLErrorFallback_SuccessUnchanged(t *testing.T) {
+	handler := Handler(WithHTMLErrorFallback(testErrorPage))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, map[string]string{"ok": "true"})
+	}))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type for success, got %s", ct)
+	}
+}
+
+func TestWithHTMLErrorFallback_TemplateFailure(t *testing.T) {
+	broken := template.Must(template.New("error").Parse(`{{.Missing}}`))
+	handler := Handler(WithHTMLErrorFallback(broken))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetError(r, ErrNotFound)
+	}))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"resource_not_found"`) {
+		t.Errorf("expected JSON envelope fallback, got %q", rec.Body.String())
+	}
+}
+
+func TestPrefersHTML(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"text/html", true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", true},
+		{"application/json, text/html;q=0.5", false},
+		{"text/*;q=0.9, application/json;q=0.5", true},
+		{"text/html;q=0, */*", false},
+	}
+
+	for _, tt := range tests {
+		if got := prefersHTML(tt.accept); got != tt.expected {
+			t.Errorf("prefersHTML(%q) = %v, want %v", tt.accept, got, tt.expected)
+		}
+	}
+}
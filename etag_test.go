@@ -0,0 +1,163 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithETag(t *testing.T) {
+	handler := Handler(WithETag())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, map[string]string{"id": "123"})
+	}))
+
+	do := func(method, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/users/123", http.NoBody)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := do(http.MethodGet, "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d with ETag %q", first.Code, etag)
+	}
+	if etag != computeETag(first.Body.Bytes()) {
+		t.Errorf("expected ETag to be the hash of the body, got %s", etag)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{"match", http.MethodGet, etag, http.StatusNotModified},
+		{"weak match in list", http.MethodGet, `"other", W/` + etag, http.StatusNotModified},
+		{"wildcard", http.MethodGet, "*", http.StatusNotModified},
+		{"mismatch", http.MethodGet, `"stale"`, http.StatusOK},
+		{"non-GET ignores If-None-Match", http.MethodPost, etag, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.method, tt.ifNoneMatch)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if rec.Header().Get("ETag") != etag {
+				t.Errorf("expected ETag %s, got %s", etag, rec.Header().Get("ETag"))
+			}
+			if tt.expectedStatus == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("expected empty body for 304, got %q", rec.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK && rec.Body.String() != first.Body.String() {
+				t.Errorf("expected full body, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithETag_SkipsErrorsAndEmptyBodies(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(r *http.Request)
+	}{
+		{"error", func(r *http.Request) { SetError(r, ErrNotFound) }},
+		{"nil body", func(r *http.Request) { SetResponse(r, http.StatusNoContent, nil) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(WithETag())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				tt.handler(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("If-None-Match", "*")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Header().Get("ETag") != "" {
+				t.Errorf("expected no ETag, got %s", rec.Header().Get("ETag"))
+			}
+			if rec.Code == http.StatusNotModified {
+				t.Error("expected no 304 without an ETag")
+			}
+		})
+	}
+}
+
+func TestWithETag_HandlerETag(t *testing.T) {
+	handler := Handler(WithETag())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetHeader(r, "ETag", `"v42"`)
+		SetResponse(r, http.StatusOK, map[string]string{"id": "123"})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("If-None-Match", `"v42"`)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for handler-set ETag, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("ETag"); got != `"v42"` {
+		t.Errorf("expected handler ETag to be kept, got %s", got)
+	}
+}
+
+func TestSetWeakETagLazy(t *testing.T) {
+	body := map[string]string{"id": "123"}
+	serve := func(opts []HandlerOption, method, ifNoneMatch string) *httptest.ResponseRecorder {
+		handler := Handler(opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			SetWeakETagLazy(r)
+			SetResponse(r, http.StatusOK, body)
+		}))
+		req := httptest.NewRequest(method, "/users/123", http.NoBody)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	plain := serve(nil, http.MethodGet, "")
+	if plain.Code != http.StatusOK || plain.Header().Get("ETag") != "" {
+		t.Fatalf("expected 200 without ETag when no If-None-Match, got %d with ETag %q", plain.Code, plain.Header().Get("ETag"))
+	}
+	weak := "W/" + computeETag(plain.Body.Bytes())
+
+	tests := []struct {
+		name           string
+		opts           []HandlerOption
+		method         string
+		ifNoneMatch    string
+		expectedStatus int
+		expectedETag   string
+	}{
+		{"stale validator", nil, http.MethodGet, `"stale"`, http.StatusOK, weak},
+		{"weak match", nil, http.MethodGet, weak, http.StatusNotModified, weak},
+		{"strong form matches weakly", nil, http.MethodGet, computeETag(plain.Body.Bytes()), http.StatusNotModified, weak},
+		{"non-GET skips hash", nil, http.MethodPost, weak, http.StatusOK, ""},
+		{"WithETag computes always", []HandlerOption{WithETag()}, http.MethodGet, "", http.StatusOK, weak},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.opts, tt.method, tt.ifNoneMatch)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.expectedETag {
+				t.Errorf("expected ETag %q, got %q", tt.expectedETag, got)
+			}
+			if tt.expectedStatus == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("expected empty body for 304, got %q", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package chikit
+
+// Form and multipart/form-data binding.
+// Maps HTML form fields and file uploads onto a struct with the same conversion and
+// validation as Query, so form handlers read like JSON handlers.
+
+import (
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var (
+	fileHeaderType      = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeaderSliceType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// BindWithMultipartMemory sets how much of a multipart/form-data body Form holds in
+// memory; the rest of the file data is spooled to temporary files. Default is 32MB,
+// matching net/http.
+func BindWithMultipartMemory(maxMemory int64) BindOption {
+	return func(c *bindConfig) {
+		c.multipartMemory = maxMemory
+	}
+}
+
+// Form decodes an application/x-www-form-urlencoded or multipart/form-data body into
+// dest and validates it. Fields are mapped by their form tag and converted like Query
+// fields; *multipart.FileHeader and []*multipart.FileHeader fields receive uploaded
+// files. Returns true if binding and validation succeeded, false otherwise. When
+// binding fails, an error is set in the wrapper context (if available): 415 for other
+// content types, 413 if MaxBodySize is exceeded, 400 for malformed forms, and the
+// usual validation_error when validation fails.
+//
+// Only body fields are bound; use Query for URL query parameters. A form already
+// parsed by MultipartLimits is reused, which also guarantees its temporary files are
+// removed after the handler returns.
+//
+// Example:
+//
+//	type UploadRequest struct {
+//		Title string                `form:"title" validate:"required"`
+//		File  *multipart.FileHeader `form:"file" validate:"required"`
+//	}
+//
+//	r.Post("/uploads", func(w http.ResponseWriter, r *http.Request) {
+//		var req UploadRequest
+//		if !chikit.Form(r, &req) {
+//			return
+//		}
+//		f, err := req.File.Open()
+//		// ...
+//	})
+func Form(r *http.Request, dest any) bool {
+	ctx := r.Context()
+	cfg := getBindConfig(ctx)
+
+	if !parseFormBody(r, cfg) {
+		return false
+	}
+
+	var files map[string][]*multipart.FileHeader
+	if r.MultipartForm != nil {
+		files = r.MultipartForm.File
+	}
+	if err := setFormFiles(dest, files); err != nil {
+		if HasState(ctx) {
+			SetError(r, ErrBadRequest.With("Invalid form data"))
+		}
+		return false
+	}
+	if err := decodeValues(dest, "form", func(name string) []string { return r.PostForm[name] }); err != nil {
+		if HasState(ctx) {
+			SetError(r, ErrBadRequest.With("Invalid form data"))
+		}
+		return false
+	}
+
+	if cfg.utf8Validation {
+		if errs := utf8FieldErrors(dest, "form", false); len(errs) > 0 {
+			if HasState(ctx) {
+				SetError(r, newBindValidationError(cfg, errs))
+			}
+			return false
+		}
+	}
+
+	return validateBound(r, cfg, dest)
+}
+
+// parseFormBody parses a urlencoded or multipart body into r.PostForm and
+// r.MultipartForm. Returns false after setting 415 for other content types, or
+// 413/400 if parsing fails.
+func parseFormBody(r *http.Request, cfg *bindConfig) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var err error
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		err = r.ParseForm()
+	case "multipart/form-data":
+		maxMemory := cfg.multipartMemory
+		if maxMemory <= 0 {
+			maxMemory = multipartMaxMemory
+		}
+		err = r.ParseMultipartForm(maxMemory)
+	default:
+		if HasState(r.Context()) {
+			SetError(r, ErrUnsupportedMedia.With("Expected form data"))
+		}
+		return false
+	}
+	if err != nil {
+		if HasState(r.Context()) {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				SetError(r, ErrPayloadTooLarge.With("Request body too large"))
+			} else {
+				SetError(r, ErrBadRequest.With("Invalid form data"))
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// setFormFiles sets the *multipart.FileHeader and []*multipart.FileHeader fields of
+// dest from the uploaded files with their form tag name. decodeValues leaves these
+// fields alone since file parts are not in PostForm.
+func setFormFiles(dest any, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("dest must be non-nil pointer to struct")
+	}
+	v := rv.Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		name := strings.SplitN(structField.Tag.Get("form"), ",", 2)[0]
+		if name == "" || name == "-" || !v.Field(i).CanSet() {
+			continue
+		}
+		uploaded := files[name]
+		if len(uploaded) == 0 {
+			continue
+		}
+		switch structField.Type {
+		case fileHeaderType:
+			v.Field(i).Set(reflect.ValueOf(uploaded[0]))
+		case fileHeaderSliceType:
+			v.Field(i).Set(reflect.ValueOf(uploaded))
+		}
+	}
+	return nil
+}
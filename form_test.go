@@ -0,0 +1,168 @@
+package chikit
+
+import (
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type signupForm struct {
+	Name  string   `form:"name" validate:"required"`
+	Age   int      `form:"age" validate:"min=18"`
+	Tags  []string `form:"tag"`
+	Agree bool     `form:"agree"`
+}
+
+type uploadForm struct {
+	Title string                  `form:"title" validate:"required"`
+	File  *multipart.FileHeader   `form:"file" validate:"required"`
+	Files []*multipart.FileHeader `form:"file"`
+}
+
+func serveForm[T any](t *testing.T, req *http.Request, opts ...BindOption) (T, *httptest.ResponseRecorder) {
+	t.Helper()
+	var dest T
+	h := Handler()(Binder(opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if !Form(r, &dest) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return dest, rec
+}
+
+func newURLEncodedRequest(values url.Values) *http.Request {
+	req := httptest.NewRequest("POST", "/?name=from-query", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func decodeFormError(t *testing.T, rec *httptest.ResponseRecorder) *APIError {
+	t.Helper()
+	var resp errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	return resp.Error
+}
+
+func TestForm_URLEncoded(t *testing.T) {
+	got, rec := serveForm[signupForm](t, newURLEncodedRequest(url.Values{
+		"name":  {"Ada"},
+		"age":   {"36"},
+		"tag":   {"a", "b"},
+		"agree": {"true"},
+	}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	want := signupForm{Name: "Ada", Age: 36, Tags: []string{"a", "b"}, Agree: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestForm_ValidationFailure(t *testing.T) {
+	_, rec := serveForm[signupForm](t, newURLEncodedRequest(url.Values{"age": {"12"}}))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	apiErr := decodeFormError(t, rec)
+	want := []FieldError{
+		{Param: "name", Code: "required", Message: "required"},
+		{Param: "age", Code: "min", Message: "must be at least 18"},
+	}
+	if apiErr.Type != ErrorTypeValidation || !reflect.DeepEqual(apiErr.Errors, want) {
+		t.Errorf("expected validation errors %+v, got %+v", want, apiErr)
+	}
+}
+
+func TestForm_Multipart(t *testing.T) {
+	got, rec := serveForm[uploadForm](t, newMultipartRequest(t, 10, 20), BindWithMultipartMemory(1))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got.Title != "report" {
+		t.Errorf("expected title report, got %q", got.Title)
+	}
+	if got.File == nil || got.File.Filename != "uploada.bin" || got.File.Size != 10 {
+		t.Fatalf("expected first file bound, got %+v", got.File)
+	}
+	if len(got.Files) != 2 || got.Files[1].Size != 20 {
+		t.Errorf("expected both files bound to slice field, got %d", len(got.Files))
+	}
+
+	f, err := got.File.Open()
+	if err != nil {
+		t.Fatalf("open uploaded file: %v", err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != strings.Repeat("x", 10) {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
+
+func TestForm_MultipartMissingFile(t *testing.T) {
+	_, rec := serveForm[uploadForm](t, newMultipartRequest(t))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	apiErr := decodeFormError(t, rec)
+	if apiErr.Type != ErrorTypeValidation || len(apiErr.Errors) != 1 || apiErr.Errors[0].Param != "file" {
+		t.Errorf("expected required file validation error, got %+v", apiErr)
+	}
+}
+
+func TestForm_Errors(t *testing.T) {
+	jsonReq := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "Ada"}`))
+	jsonReq.Header.Set("Content-Type", "application/json")
+
+	badMultipart := httptest.NewRequest("POST", "/", strings.NewReader("not multipart"))
+	badMultipart.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+
+	tests := []struct {
+		name        string
+		req         *http.Request
+		wantStatus  int
+		wantMessage string
+	}{
+		{"unsupported content type", jsonReq, http.StatusUnsupportedMediaType, "Expected form data"},
+		{"conversion failure", newURLEncodedRequest(url.Values{"name": {"Ada"}, "age": {"old"}}), http.StatusBadRequest, "Invalid form data"},
+		{"malformed multipart", badMultipart, http.StatusBadRequest, "Invalid form data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, rec := serveForm[signupForm](t, tt.req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if msg := decodeFormError(t, rec).Message; msg != tt.wantMessage {
+				t.Errorf("expected message %q, got %q", tt.wantMessage, msg)
+			}
+		})
+	}
+}
+
+func TestForm_MaxBodySize(t *testing.T) {
+	var bound bool
+	h := Handler()(MaxBodySize(16)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var dest signupForm
+		bound = Form(r, &dest)
+	})))
+	req := newURLEncodedRequest(url.Values{"name": {strings.Repeat("a", 64)}, "age": {"30"}})
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if bound || rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d (bound=%v)", rec.Code, bound)
+	}
+}
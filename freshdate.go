@@ -0,0 +1,98 @@
+package chikit
+
+// Request freshness checks for signed APIs.
+// Rejects requests whose Date header is too far from server time, limiting
+// the window in which a captured request can be replayed.
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type freshDateConfig struct {
+	header   string
+	optional bool
+}
+
+// FreshDateOption configures RequireFreshDate middleware.
+type FreshDateOption func(*freshDateConfig)
+
+// FreshDateWithHeader sets the header carrying the request time (e.g., "X-Date"
+// for clients that cannot set Date). Default is "Date".
+func FreshDateWithHeader(name string) FreshDateOption {
+	return func(c *freshDateConfig) {
+		c.header = name
+	}
+}
+
+// RequireFreshDateOptional lets requests without the header through. Requests that
+// include it are still checked.
+func RequireFreshDateOptional() FreshDateOption {
+	return func(c *freshDateConfig) {
+		c.optional = true
+	}
+}
+
+// RequireFreshDate returns middleware that rejects requests whose Date header is
+// more than maxSkew before or after server time. The header is parsed with
+// http.ParseTime, so all three HTTP date formats are accepted.
+//
+// Returns 400 (Bad Request) if the header is missing (unless RequireFreshDateOptional
+// is set) or malformed.
+// Returns 401 (Unauthorized) if the date is outside the allowed skew.
+//
+// Example:
+//
+//	r.Use(chikit.RequireFreshDate(5 * time.Minute))
+func RequireFreshDate(maxSkew time.Duration, opts ...FreshDateOption) func(http.Handler) http.Handler {
+	cfg := &freshDateConfig{header: "Date"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := checkFreshDate(cfg, r.Header.Get(cfg.header), maxSkew); err != nil {
+				respond(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func checkFreshDate(cfg *freshDateConfig, value string, maxSkew time.Duration) *APIError {
+	if value == "" {
+		if cfg.optional {
+			return nil
+		}
+		return &APIError{
+			Type:    ErrorTypeValidation,
+			Code:    ErrorCodeMissingHeader,
+			Message: fmt.Sprintf("Missing required header: %s", cfg.header),
+			Param:   cfg.header,
+			Status:  http.StatusBadRequest,
+		}
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return &APIError{
+			Type:    ErrorTypeValidation,
+			Code:    ErrorCodeInvalidHeader,
+			Message: fmt.Sprintf("Header %s must be an HTTP date", cfg.header),
+			Param:   cfg.header,
+			Status:  http.StatusBadRequest,
+		}
+	}
+
+	skew := time.Since(date)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return ErrUnauthorized.With("Request date outside allowed skew")
+	}
+	return nil
+}
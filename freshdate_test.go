@@ -0,0 +1,48 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequireFreshDate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		opts     []FreshDateOption
+		header   string
+		date     string
+		expected int
+	}{
+		{"fresh date", nil, "Date", now.UTC().Format(http.TimeFormat), http.StatusOK},
+		{"too old", nil, "Date", now.Add(-10 * time.Minute).UTC().Format(http.TimeFormat), http.StatusUnauthorized},
+		{"future skewed", nil, "Date", now.Add(10 * time.Minute).UTC().Format(http.TimeFormat), http.StatusUnauthorized},
+		{"malformed", nil, "Date", "yesterday", http.StatusBadRequest},
+		{"missing", nil, "Date", "", http.StatusBadRequest},
+		{"missing optional", []FreshDateOption{RequireFreshDateOptional()}, "Date", "", http.StatusOK},
+		{"stale optional", []FreshDateOption{RequireFreshDateOptional()}, "Date", now.Add(-time.Hour).UTC().Format(http.TimeFormat), http.StatusUnauthorized},
+		{"custom header", []FreshDateOption{FreshDateWithHeader("X-Date")}, "X-Date", now.UTC().Format(time.RFC850), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(RequireFreshDate(5*time.Minute, tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			if tt.date != "" {
+				req.Header.Set(tt.header, tt.date)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package chikit
+
+// gRPC status translation for gateways fronting gRPC backends.
+//
+// Maps gRPC status codes to APIErrors so clients get the same error envelope
+// whether a request failed in the gateway or in the backend. Codes are plain
+// numbers, so chikit does not depend on google.golang.org/grpc.
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GRPCCode is a gRPC status code. Values match google.golang.org/grpc/codes.Code,
+// so callers using grpc convert with chikit.GRPCCode(st.Code()).
+type GRPCCode uint32
+
+// grpcErrors maps gRPC codes to APIErrors, following the HTTP mapping in
+// google.rpc.Code. OK (0) has no entry; unlisted codes map to ErrInternal.
+var grpcErrors = map[GRPCCode]*APIError{
+	1:  ErrClientClosed,       // CANCELLED
+	2:  ErrInternal,           // UNKNOWN
+	3:  ErrBadRequest,         // INVALID_ARGUMENT
+	4:  ErrGatewayTimeout,     // DEADLINE_EXCEEDED
+	5:  ErrNotFound,           // NOT_FOUND
+	6:  ErrConflict,           // ALREADY_EXISTS
+	7:  ErrForbidden,          // PERMISSION_DENIED
+	8:  ErrRateLimited,        // RESOURCE_EXHAUSTED
+	9:  ErrBadRequest,         // FAILED_PRECONDITION
+	10: ErrConflict,           // ABORTED
+	11: ErrBadRequest,         // OUT_OF_RANGE
+	12: ErrNotImplemented,     // UNIMPLEMENTED
+	13: ErrInternal,           // INTERNAL
+	14: ErrServiceUnavailable, // UNAVAILABLE
+	15: ErrInternal,           // DATA_LOSS
+	16: ErrUnauthorized,       // UNAUTHENTICATED
+}
+
+// FromGRPCCode returns the sentinel APIError for a gRPC status code, e.g.
+// ErrNotFound for NOT_FOUND, ErrGatewayTimeout for DEADLINE_EXCEEDED, and
+// ErrClientClosed (499) for CANCELLED.
+// Returns nil for OK and ErrInternal for unknown codes.
+//
+// Example:
+//
+//	if st, ok := status.FromError(err); ok {
+//		chikit.SetError(r, chikit.FromGRPCCode(chikit.GRPCCode(st.Code())).With(st.Message()))
+//	}
+func FromGRPCCode(code GRPCCode) *APIError {
+	if code == 0 {
+		return nil
+	}
+	if apiErr, ok := grpcErrors[code]; ok {
+		return apiErr
+	}
+	return ErrInternal
+}
+
+// GRPCStatusMapper returns middleware for handlers that proxy to a gRPC backend
+// (e.g., a grpc-gateway mux) and report the backend status in the Grpc-Status
+// response header. A non-zero status replaces the response with the matching
+// APIError (see FromGRPCCode) and the backend body is discarded. For 4xx errors
+// the percent-encoded Grpc-Message header, if present, becomes the error message;
+// 5xx errors keep the generic message so backend internals are not exposed.
+//
+// With the Handler wrapper the error is set via SetError; without it, it is
+// written as plain text.
+//
+// Example:
+//
+//	r.With(chikit.GRPCStatusMapper()).Handle("/v1/*", gatewayMux)
+func GRPCStatusMapper() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&grpcStatusWriter{ResponseWriter: w, r: r}, r)
+		})
+	}
+}
+
+// grpcStatusWriter inspects Grpc-Status as the backend response headers are written.
+type grpcStatusWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	wroteHeader bool
+	discard     bool
+}
+
+func (w *grpcStatusWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	apiErr := grpcStatusError(h.Get("Grpc-Status"), h.Get("Grpc-Message"))
+	if apiErr == nil {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	for _, name := range []string{"Grpc-Status", "Grpc-Message", "Content-Type", "Content-Length", "Content-Encoding"} {
+		h.Del(name)
+	}
+	w.discard = true
+	respond(w.ResponseWriter, w.r, apiErr)
+}
+
+func (w *grpcStatusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discard {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *grpcStatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// grpcStatusError returns the APIError for Grpc-Status and Grpc-Message header
+// values, or nil if the status is absent, invalid, or OK.
+func grpcStatusError(status, message string) *APIError {
+	code, err := strconv.ParseUint(status, 10, 32)
+	if err != nil {
+		return nil
+	}
+	apiErr := FromGRPCCode(GRPCCode(code))
+	if apiErr == nil || apiErr.Status >= 500 || message == "" {
+		return apiErr
+	}
+	if decoded, err := url.PathUnescape(message); err == nil {
+		message = decoded
+	}
+	return apiErr.With(message)
+}
@@ -0,0 +1,129 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromGRPCCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       GRPCCode
+		want       *APIError
+		wantStatus int
+		wantType   ErrorType
+	}{
+		{"CANCELLED", 1, ErrClientClosed, StatusClientClosedRequest, ErrorTypeRequest},
+		{"UNKNOWN", 2, ErrInternal, http.StatusInternalServerError, ErrorTypeInternal},
+		{"INVALID_ARGUMENT", 3, ErrBadRequest, http.StatusBadRequest, ErrorTypeRequest},
+		{"DEADLINE_EXCEEDED", 4, ErrGatewayTimeout, http.StatusGatewayTimeout, ErrorTypeTimeout},
+		{"NOT_FOUND", 5, ErrNotFound, http.StatusNotFound, ErrorTypeNotFound},
+		{"ALREADY_EXISTS", 6, ErrConflict, http.StatusConflict, ErrorTypeRequest},
+		{"PERMISSION_DENIED", 7, ErrForbidden, http.StatusForbidden, ErrorTypeAuth},
+		{"RESOURCE_EXHAUSTED", 8, ErrRateLimited, http.StatusTooManyRequests, ErrorTypeRateLimit},
+		{"FAILED_PRECONDITION", 9, ErrBadRequest, http.StatusBadRequest, ErrorTypeRequest},
+		{"ABORTED", 10, ErrConflict, http.StatusConflict, ErrorTypeRequest},
+		{"OUT_OF_RANGE", 11, ErrBadRequest, http.StatusBadRequest, ErrorTypeRequest},
+		{"UNIMPLEMENTED", 12, ErrNotImplemented, http.StatusNotImplemented, ErrorTypeRequest},
+		{"INTERNAL", 13, ErrInternal, http.StatusInternalServerError, ErrorTypeInternal},
+		{"UNAVAILABLE", 14, ErrServiceUnavailable, http.StatusServiceUnavailable, ErrorTypeRequest},
+		{"DATA_LOSS", 15, ErrInternal, http.StatusInternalServerError, ErrorTypeInternal},
+		{"UNAUTHENTICATED", 16, ErrUnauthorized, http.StatusUnauthorized, ErrorTypeAuth},
+		{"unknown code", 99, ErrInternal, http.StatusInternalServerError, ErrorTypeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromGRPCCode(tt.code)
+			if got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			if got.Status != tt.wantStatus || got.Type != tt.wantType {
+				t.Errorf("expected %d %s, got %d %s", tt.wantStatus, tt.wantType, got.Status, got.Type)
+			}
+		})
+	}
+
+	if got := FromGRPCCode(0); got != nil {
+		t.Errorf("expected nil for OK, got %v", got)
+	}
+}
+
+func grpcBackend(status, message string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc-web+json")
+		if status != "" {
+			w.Header().Set("Grpc-Status", status)
+		}
+		if message != "" {
+			w.Header().Set("Grpc-Message", message)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"backend":true}`))
+	})
+}
+
+func TestGRPCStatusMapper(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		message     string
+		wantStatus  int
+		wantCode    ErrorCode
+		wantMessage string
+	}{
+		{"not found with message", "5", "user%2042%20not%20found", http.StatusNotFound, ErrorCodeNotFound, "user 42 not found"},
+		{"permission denied", "7", "", http.StatusForbidden, ErrorCodeForbidden, "Forbidden"},
+		{"internal hides message", "13", "db%20password%20rejected", http.StatusInternalServerError, ErrorCodeInternal, "Internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handler()(GRPCStatusMapper()(grpcBackend(tt.status, tt.message)))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Header().Get("Grpc-Status") != "" || rec.Header().Get("Grpc-Message") != "" {
+				t.Error("expected gRPC headers to be removed")
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected application/json, got %q", ct)
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Error.Code != tt.wantCode || resp.Error.Message != tt.wantMessage {
+				t.Errorf("expected %s %q, got %s %q", tt.wantCode, tt.wantMessage, resp.Error.Code, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestGRPCStatusMapper_PassThrough(t *testing.T) {
+	for _, status := range []string{"", "0"} {
+		h := Handler()(GRPCStatusMapper()(grpcBackend(status, "")))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"backend":true}` {
+			t.Errorf("status %q: expected backend response, got %d %s", status, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestGRPCStatusMapper_WithoutWrapper(t *testing.T) {
+	h := GRPCStatusMapper()(grpcBackend("16", ""))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != "Unauthorized\n" {
+		t.Errorf("expected plain text error without backend body, got %q", body)
+	}
+}
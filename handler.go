@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
 	"net/http"
 	"sync"
 	"sync/atomic"
@@ -20,21 +24,46 @@ var activeHandlers sync.WaitGroup
 // activeHandlerCount tracks the count for ActiveHandlerCount().
 var activeHandlerCount atomic.Int64
 
+// abandonedHandlerCount tracks handler goroutines still running after their timeout fired.
+var abandonedHandlerCount atomic.Int64
+
 // HandlerOption configures the Handler middleware.
 type HandlerOption func(*config)
 
 type config struct {
 	canonlog         bool
 	canonlogFields   func(*http.Request) map[string]any
+	canonlogSkip     func(*http.Request) bool
 	slosEnabled      bool
 	timeout          time.Duration
+	sloTimeout       float64
 	gracefulShutdown time.Duration
 	onAbandon        func(*http.Request)
+	maxAbandoned     int64
+	nilBodyStatus    int
+	htmlErrorPage    *template.Template
+	hardDeadline     time.Duration
+	redactResponse   bool
+	slowSampling     time.Duration
+	envelope         bool
+	compressMin      int
+	etag             bool
+	headerObserver   func(context.Context, http.Header)
+	negotiate        bool
+	problemDetails   bool
+	checksum         string
+	logLevelFunc     func(status int, route string) LogLevel
+
+	// canonlogSampledOut is set per request when WithCanonlogSkip matched but the
+	// logger is kept so WithSlowRequestSampling can still force the line at flush.
+	canonlogSampledOut bool
 }
 
 // WithCanonlog enables canonical logging for requests.
 // Creates a logger at request start and flushes it after response.
 // Logs method, path, route, status, and duration_ms for each request.
+// Also logs phase timings: queue_ms (before the downstream handler starts),
+// handler_ms (downstream middleware and handler), and write_ms (response encoding and write).
 // Errors set via SetError are automatically logged.
 func WithCanonlog() HandlerOption {
 	return func(c *config) {
@@ -51,6 +80,74 @@ func WithCanonlogFields(fn func(*http.Request) map[string]any) HandlerOption {
 	}
 }
 
+// WithCanonlogSkip skips canonical logging for requests where fn returns true.
+// Matching requests are served normally but no logger is created or flushed,
+// and SLO status is not logged. Use this for health checks and metrics scrapes.
+//
+// Example:
+//
+//	chikit.WithCanonlogSkip(func(r *http.Request) bool {
+//		return r.URL.Path == "/healthz" || r.URL.Path == "/metrics"
+//	})
+func WithCanonlogSkip(fn func(*http.Request) bool) HandlerOption {
+	return func(c *config) {
+		c.canonlogSkip = fn
+	}
+}
+
+// WithSlowRequestSampling force-samples requests that take at least threshold: they are
+// logged even when WithCanonlogSkip would drop them, so sampling the canonical log
+// never loses the slowest requests. The decision is made at flush time from the
+// request duration, and forced lines include force_sampled=true.
+//
+// Skipped requests still get a logger (canonlog.TryGetLogger succeeds) so fields
+// added during the request are available if it turns out to be slow; the line is
+// discarded when it is fast. Requires WithCanonlog.
+//
+// Example with 10% probabilistic sampling:
+//
+//	chikit.Handler(
+//		chikit.WithCanonlog(),
+//		chikit.WithCanonlogSkip(func(*http.Request) bool { return rand.Float64() >= 0.1 }),
+//		chikit.WithSlowRequestSampling(time.Second),
+//	)
+func WithSlowRequestSampling(threshold time.Duration) HandlerOption {
+	return func(c *config) {
+		c.slowSampling = threshold
+	}
+}
+
+// LogLevel is the level of a canonical log line, as chosen by WithLogLevelFunc.
+type LogLevel = slog.Level
+
+// errLogLevelEscalated is recorded when WithLogLevelFunc escalates a request
+// without an error to LevelError, since canonlog only reaches Error through ErrorAdd.
+var errLogLevelEscalated = errors.New("log level escalated to ERROR")
+
+// WithLogLevelFunc chooses the level of each canonical log line from the final
+// status and route. Requires WithCanonlog.
+//
+// Only escalation is honored: returning slog.LevelWarn or slog.LevelError raises
+// the line above the level canonlog would use, and lower levels are ignored.
+// Downgrading (for example logging 404s on a probe path at debug) needs a canonlog
+// release that supports choosing the level at flush time; until then use
+// WithCanonlogSkip to drop those lines instead. Warn escalation adds log_level=WARN;
+// Error escalation of a request without an error adds a marker to errors.
+//
+// Example:
+//
+//	chikit.WithLogLevelFunc(func(status int, route string) chikit.LogLevel {
+//		if status == http.StatusUnauthorized && route == "/admin/*" {
+//			return slog.LevelWarn
+//		}
+//		return slog.LevelInfo
+//	})
+func WithLogLevelFunc(fn func(status int, route string) LogLevel) HandlerOption {
+	return func(c *config) {
+		c.logLevelFunc = fn
+	}
+}
+
 // WithSLOs enables SLO status logging.
 // Requires WithCanonlog() to be enabled.
 // Reads SLO tier and target from context (set via SLO or SLOWithTarget)
@@ -83,6 +180,29 @@ func WithTimeout(d time.Duration) HandlerOption {
 	}
 }
 
+// WithTimeoutFromSLO derives the handler timeout from the request's SLO target:
+// requests with an SLO in context (see SLO, SLOWithTarget, SLOByRoute) time out
+// after target * multiplier, so routes with tighter latency targets are cut off
+// sooner. Requests without an SLO fall back to WithTimeout, or no timeout if it
+// is not set. Timeout handling otherwise behaves as for WithTimeout.
+//
+// The target is read when Handler starts, so register the SLO middleware before
+// Handler:
+//
+//	r.Use(chikit.SLOByRoute(tiers, chikit.SLOLow))
+//	r.Use(chikit.Handler(
+//		chikit.WithTimeoutFromSLO(3),       // 150ms for SLOCritical, 15s for SLOLow
+//		chikit.WithTimeout(30*time.Second), // routes without an SLO
+//	))
+//
+// An SLO set after Handler, such as r.With(chikit.SLO(...)) on a route below a
+// router-level Handler, is not seen and the request uses WithTimeout.
+func WithTimeoutFromSLO(multiplier float64) HandlerOption {
+	return func(c *config) {
+		c.sloTimeout = multiplier
+	}
+}
+
 // WithGracefulShutdown sets how long to wait for a handler goroutine to exit
 // after timeout fires. This grace period allows handlers to complete cleanup
 // (e.g., database rollbacks) after the 504 response is sent to the client.
@@ -105,40 +225,308 @@ func WithAbandonCallback(fn func(*http.Request)) HandlerOption {
 	}
 }
 
-// Handler returns middleware that manages response state and writes responses.
-func Handler(opts ...HandlerOption) func(http.Handler) http.Handler {
+// WithMaxAbandonedHandlers sheds load when too many timed-out handlers are still running.
+// Once n handler goroutines are past their timeout (in the grace period or abandoned),
+// new requests are rejected immediately with 503 Service Unavailable instead of
+// spawning more goroutines. This bounds memory during incidents where a dependency
+// hangs and handlers ignore context cancellation.
+//
+// The count is shared across all Handler instances. Only applies with WithTimeout.
+func WithMaxAbandonedHandlers(n int) HandlerOption {
+	return func(c *config) {
+		c.maxAbandoned = int64(n)
+	}
+}
+
+// WithNilBodyStatus rewrites success responses that have no body to status.
+// With WithNilBodyStatus(http.StatusNoContent), SetResponse(r, http.StatusOK, nil)
+// writes 204 instead of an empty 200. Only 2xx responses with a nil body are
+// rewritten; errors and responses with a body are unchanged.
+// By default the status passed to SetResponse is written as-is.
+func WithNilBodyStatus(status int) HandlerOption {
+	return func(c *config) {
+		c.nilBodyStatus = status
+	}
+}
+
+// WithHTMLErrorFallback renders error responses with tmpl for clients whose Accept
+// header prefers text/html over application/json (e.g., browsers). API clients
+// still receive the JSON error envelope. The template is executed with an
+// ErrorPageData. If the template fails to execute, the JSON envelope is written.
+//
+// Example:
+//
+//	page := template.Must(template.New("error").Parse(
+//		`<h1>{{.Status}} {{.StatusText}}</h1><p>{{.Message}}</p>`))
+//	r.Use(chikit.Handler(chikit.WithHTMLErrorFallback(page)))
+func WithHTMLErrorFallback(tmpl *template.Template) HandlerOption {
+	return func(c *config) {
+		c.htmlErrorPage = tmpl
+	}
+}
+
+// WithHardDeadline sets an absolute deadline for the entire request, measured from
+// when Handler starts. Unlike WithTimeout, which bounds a single handler invocation,
+// the deadline is carried by the request context and shared by everything the
+// request does (retry loops, per-attempt timeouts, streaming), so sub-operations
+// cannot extend it: a per-attempt context.WithTimeout derived from the request
+// context expires no later than the hard deadline.
+//
+// If the deadline passes before the response is written, a 504 Gateway Timeout is
+// returned, even if the handler set a different response after the deadline.
+// Handlers run in a goroutine as with WithTimeout, and the same grace period,
+// abandon callback, and WaitForHandlers requirements apply.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(
+//		chikit.WithTimeout(2*time.Second),      // typical single call
+//		chikit.WithHardDeadline(10*time.Second), // absolute bound
+//	))
+func WithHardDeadline(d time.Duration) HandlerOption {
+	return func(c *config) {
+		c.hardDeadline = d
+	}
+}
+
+// WithResponseRedaction masks fields tagged `log:"redact"` in success bodies before
+// they are written (see Redact), for handlers that echo bound request structs back
+// to the client. Errors and pre-encoded JSON bodies are unchanged.
+func WithResponseRedaction() HandlerOption {
+	return func(c *config) {
+		c.redactResponse = true
+	}
+}
+
+// WithEnvelope wraps success bodies as {"data": <body>}, so clients always parse a
+// stable shape alongside the {"error": ...} envelope used for errors. Responses
+// without a body, bodies from SetResponseWithMeta (already {"data", "meta"}), and
+// non-JSON bodies captured by Adapt are written unchanged.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(chikit.WithEnvelope()))
+//	// SetResponse(r, http.StatusCreated, user) writes {"data":{"id":"123",...}}
+func WithEnvelope() HandlerOption {
+	return func(c *config) {
+		c.envelope = true
+	}
+}
+
+// WithCompression compresses response bodies of at least minBytes with gzip or
+// deflate when the client's Accept-Encoding allows it, setting Content-Encoding and
+// Vary: Accept-Encoding. Applies to success and error bodies alike. Bodies that are
+// already compressed (images, video, audio, archives) or that the handler gave a
+// Content-Encoding are written unchanged. minBytes of 0 or less disables compression.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(chikit.WithCompression(1024)))
+func WithCompression(minBytes int) HandlerOption {
+	return func(c *config) {
+		c.compressMin = minBytes
+	}
+}
+
+// WithETag adds a strong ETag (a SHA-256 of the encoded body) to 2xx responses with a
+// body, unless the handler set one. GET and HEAD requests whose If-None-Match matches
+// the ETag get 304 Not Modified with no body, so clients revalidating cached
+// responses skip the download. The response is still produced by the handler; only
+// the transfer is saved.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(chikit.WithETag()))
+func WithETag() HandlerOption {
+	return func(c *config) {
+		c.etag = true
+	}
+}
+
+// WithHeaderObserver calls fn with the final response headers (including
+// Content-Type and headers set via SetHeader) right before they are written, for
+// auditing or compliance checks such as asserting HSTS is always present. fn
+// receives a copy, so changes to it do not affect the response.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(chikit.WithHeaderObserver(func(ctx context.Context, h http.Header) {
+//		if h.Get("Strict-Transport-Security") == "" {
+//			missingHSTS.Inc()
+//		}
+//	})))
+func WithHeaderObserver(fn func(ctx context.Context, h http.Header)) HandlerOption {
+	return func(c *config) {
+		c.headerObserver = fn
+	}
+}
+
+// WithContentNegotiation encodes response bodies as XML for clients whose Accept
+// header prefers application/xml (or text/xml) over application/json, and adds
+// Vary: Accept. Error responses become an <error> document with the same element
+// names as the JSON envelope. JSON remains the default when Accept is absent, */*,
+// or ties. Bodies encoding/xml cannot represent (maps, pre-encoded json.RawMessage)
+// are still written as JSON, so give response structs xml tags as well as json tags.
+//
+// Example:
+//
+//	type User struct {
+//		XMLName xml.Name `json:"-" xml:"user"`
+//		ID      string   `json:"id" xml:"id"`
+//	}
+//
+//	r.Use(chikit.Handler(chikit.WithContentNegotiation()))
+func WithContentNegotiation() HandlerOption {
+	return func(c *config) {
+		c.negotiate = true
+	}
+}
+
+// WithProblemDetails writes error responses as RFC 9457 problem details
+// (application/problem+json) instead of the {"error": ...} envelope. The error's
+// message becomes detail, its DocsURL becomes type ("about:blank" if unset), and
+// its code is kept as a code extension member. Field errors become an errors
+// extension member whose entries carry detail, code, and a JSON pointer to the
+// field (e.g., "/address/city"). Success responses are unchanged.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(chikit.WithProblemDetails()))
+func WithProblemDetails() HandlerOption {
+	return func(c *config) {
+		c.problemDetails = true
+	}
+}
+
+// WithChecksumTrailer sends a digest of the response body as a Digest trailer
+// (RFC 3230, e.g. "SHA-256=X48E9q...") after the body, for clients verifying the
+// integrity of downloads. The digest is computed as the body is written, so
+// streamed responses (SetStream) are covered without buffering; it is taken over
+// the bytes sent, after any compression. The trailer is declared in the Trailer
+// header up front. Responses without a body (204, 304) carry no trailer.
+//
+// algorithm is "sha-256" or "sha-512" (case-insensitive, "sha256" also accepted);
+// any other value panics.
+//
+// Example:
+//
+//	r.With(chikit.Handler(chikit.WithChecksumTrailer("sha-256"))).Get("/exports/{id}", download)
+func WithChecksumTrailer(algorithm string) HandlerOption {
+	name := normalizeChecksumAlgorithm(algorithm)
+	if name == "" {
+		panic("WithChecksumTrailer: unsupported algorithm " + algorithm)
+	}
+	return func(c *config) {
+		c.checksum = name
+	}
+}
+
+// HandlerConfig describes the effective settings of a Handler, as returned by
+// DescribeHandler. Durations of zero mean the feature is disabled.
+type HandlerConfig struct {
+	Timeout              time.Duration `json:"timeout"`
+	TimeoutSLOMultiplier float64       `json:"timeout_slo_multiplier"`
+	HardDeadline         time.Duration `json:"hard_deadline"`
+	GracefulShutdown     time.Duration `json:"graceful_shutdown"`
+	MaxAbandonedHandlers int           `json:"max_abandoned_handlers"`
+	NilBodyStatus        int           `json:"nil_body_status"`
+	Canonlog             bool          `json:"canonlog"`
+	SLOs                 bool          `json:"slos"`
+	HTMLErrorFallback    bool          `json:"html_error_fallback"`
+	ResponseRedaction    bool          `json:"response_redaction"`
+	SlowRequestSampling  time.Duration `json:"slow_request_sampling"`
+	Envelope             bool          `json:"envelope"`
+	CompressionMinBytes  int           `json:"compression_min_bytes"`
+	ETag                 bool          `json:"etag"`
+	ContentNegotiation   bool          `json:"content_negotiation"`
+	ProblemDetails       bool          `json:"problem_details"`
+	ChecksumTrailer      string        `json:"checksum_trailer"`
+}
+
+// DescribeHandler returns the effective settings a Handler built with opts would use,
+// after defaults are applied (e.g., the 5 second grace period when WithTimeout is set).
+// Use it to expose configuration on an admin endpoint:
+//
+//	opts := []chikit.HandlerOption{chikit.WithCanonlog(), chikit.WithTimeout(30 * time.Second)}
+//	r.Use(chikit.Handler(opts...))
+//	r.Get("/admin/config", func(w http.ResponseWriter, r *http.Request) {
+//		chikit.SetResponse(r, http.StatusOK, chikit.DescribeHandler(opts...))
+//	})
+func DescribeHandler(opts ...HandlerOption) HandlerConfig {
+	cfg := newConfig(opts)
+	return HandlerConfig{
+		Timeout:              cfg.timeout,
+		TimeoutSLOMultiplier: cfg.sloTimeout,
+		HardDeadline:         cfg.hardDeadline,
+		GracefulShutdown:     cfg.gracefulShutdown,
+		MaxAbandonedHandlers: int(cfg.maxAbandoned),
+		NilBodyStatus:        cfg.nilBodyStatus,
+		Canonlog:             cfg.canonlog,
+		SLOs:                 cfg.slosEnabled,
+		HTMLErrorFallback:    cfg.htmlErrorPage != nil,
+		ResponseRedaction:    cfg.redactResponse,
+		SlowRequestSampling:  cfg.slowSampling,
+		Envelope:             cfg.envelope,
+		CompressionMinBytes:  max(0, cfg.compressMin),
+		ETag:                 cfg.etag,
+		ContentNegotiation:   cfg.negotiate,
+		ProblemDetails:       cfg.problemDetails,
+		ChecksumTrailer:      cfg.checksum,
+	}
+}
+
+// newConfig applies opts and fills in defaults.
+func newConfig(opts []HandlerOption) *config {
 	cfg := &config{}
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
 	// Apply defaults and validation
-	if cfg.timeout > 0 && cfg.gracefulShutdown == 0 {
-		cfg.gracefulShutdown = 5 * time.Second
-	}
 	if cfg.timeout < 0 {
 		cfg.timeout = 0 // Treat negative as disabled
 	}
+	if cfg.sloTimeout < 0 {
+		cfg.sloTimeout = 0
+	}
+	if cfg.hardDeadline < 0 {
+		cfg.hardDeadline = 0
+	}
+	if (cfg.timeout > 0 || cfg.sloTimeout > 0 || cfg.hardDeadline > 0) && cfg.gracefulShutdown == 0 {
+		cfg.gracefulShutdown = 5 * time.Second
+	}
 	if cfg.gracefulShutdown < 0 {
 		cfg.gracefulShutdown = 0
 	}
+	return cfg
+}
+
+// Handler returns middleware that manages response state and writes responses.
+func Handler(opts ...HandlerOption) func(http.Handler) http.Handler {
+	cfg := newConfig(opts)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			state := &State{}
+			cfg := requestConfig(r, cfg)
+			state := newState(r, cfg)
 			ctx := context.WithValue(r.Context(), stateKey, state)
 
-			var start time.Time
+			start := state.start
 			if cfg.canonlog {
 				ctx = canonlog.NewContext(ctx)
-				start = time.Now()
 				canonlog.InfoAddMany(ctx, map[string]any{"method": r.Method, "path": r.URL.Path})
 				if cfg.canonlogFields != nil {
 					canonlog.InfoAddMany(ctx, cfg.canonlogFields(r))
 				}
 			}
 
-			if cfg.timeout == 0 {
+			if cfg.hardDeadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, cfg.hardDeadline)
+				defer cancel()
+			}
+
+			if cfg.timeout == 0 && cfg.hardDeadline == 0 {
 				handleSync(ctx, cfg, next, w, r.WithContext(ctx), state, start)
 				return
 			}
@@ -147,26 +535,95 @@ func Handler(opts ...HandlerOption) func(http.Handler) http.Handler {
 	}
 }
 
+// requestConfig returns cfg adjusted for r: canonical logging is disabled or
+// sampled out for requests matched by WithCanonlogSkip, and the timeout is
+// derived from the SLO target when WithTimeoutFromSLO applies. cfg itself is
+// never modified.
+func requestConfig(r *http.Request, cfg *config) *config {
+	if cfg.canonlog && cfg.canonlogSkip != nil && cfg.canonlogSkip(r) {
+		skipped := *cfg
+		if cfg.slowSampling > 0 {
+			skipped.canonlogSampledOut = true
+		} else {
+			skipped.canonlog = false
+		}
+		cfg = &skipped
+	}
+	if timeout, ok := sloTimeout(r, cfg); ok {
+		timed := *cfg
+		timed.timeout = timeout
+		cfg = &timed
+	}
+	return cfg
+}
+
+// newState returns the response state for r, seeded from cfg and the
+// request's negotiation and precondition headers.
+func newState(r *http.Request, cfg *config) *State {
+	state := &State{accept: r.Header.Get("Accept"), routeLookup: newRouteLookup(r), problem: cfg.problemDetails, start: time.Now()}
+	if cfg.compressMin > 0 {
+		state.compressMin = cfg.compressMin
+		state.encoding = negotiateCompression(r.Header.Get("Accept-Encoding"))
+	}
+	state.etag = cfg.etag
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		state.ifNoneMatch = r.Header.Get("If-None-Match")
+	}
+	if cfg.negotiate {
+		state.negotiate = true
+		state.xml = prefersXML(state.accept)
+	}
+	return state
+}
+
+// sloTimeout returns the timeout derived from the request's SLO target when
+// WithTimeoutFromSLO is set and an SLO is in context.
+func sloTimeout(r *http.Request, cfg *config) (time.Duration, bool) {
+	if cfg.sloTimeout == 0 {
+		return 0, false
+	}
+	_, target, ok := GetSLO(r.Context())
+	if !ok || target <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(target) * cfg.sloTimeout), true
+}
+
 func handleSync(ctx context.Context, cfg *config, next http.Handler, w http.ResponseWriter, r *http.Request, state *State, start time.Time) {
 	defer func() {
 		if rec := recover(); rec != nil {
-			state.mu.Lock()
-			state.err = ErrInternal
-			state.mu.Unlock()
-			if cfg.canonlog {
-				canonlog.ErrorAdd(ctx, fmt.Errorf("panic: %v", rec))
-			}
+			recordPanic(ctx, cfg, state, rec)
 		}
-		flushCanonlog(ctx, cfg, state, r, start)
+		state.markHandlerEnd()
 		if state.markWritten() {
-			writeResponse(w, state)
+			writeTimed(ctx, w, cfg, state)
 		}
+		flushCanonlog(ctx, cfg, state, r, start)
 	}()
+	state.markHandlerStart()
 	next.ServeHTTP(w, r)
 }
 
 func handleWithTimeout(parentCtx context.Context, cfg *config, next http.Handler, w http.ResponseWriter, r *http.Request, state *State, start time.Time) {
-	ctx, cancel := context.WithTimeout(parentCtx, cfg.timeout)
+	if cfg.maxAbandoned > 0 && abandonedHandlerCount.Load() >= cfg.maxAbandoned {
+		state.mu.Lock()
+		state.err = ErrServiceUnavailable.With("Server overloaded")
+		state.mu.Unlock()
+		if state.markWritten() {
+			writeTimed(parentCtx, w, cfg, state)
+		}
+		flushCanonlog(parentCtx, cfg, state, r, start)
+		return
+	}
+
+	// parentCtx carries the hard deadline, if any, so it also bounds this context
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if cfg.timeout > 0 {
+		ctx, cancel = context.WithTimeout(parentCtx, cfg.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parentCtx)
+	}
 	defer cancel()
 
 	r = r.WithContext(ctx)
@@ -178,29 +635,42 @@ func handleWithTimeout(parentCtx context.Context, cfg *config, next http.Handler
 	go func() {
 		defer activeHandlers.Done()
 		defer activeHandlerCount.Add(-1)
+		defer state.markHandlerExited()
 		defer close(done)
 		defer func() {
 			if rec := recover(); rec != nil {
 				panicVal <- rec
 			}
 		}()
+		defer state.markHandlerEnd()
+		state.markHandlerStart()
 		next.ServeHTTP(w, r)
 	}()
 
 	select {
 	case <-done:
 		handlePanic(parentCtx, cfg, state, panicVal)
-		flushCanonlog(parentCtx, cfg, state, r, start)
+		if cfg.hardDeadline > 0 && errors.Is(parentCtx.Err(), context.DeadlineExceeded) {
+			state.mu.Lock()
+			state.err = ErrGatewayTimeout
+			state.mu.Unlock()
+		}
 		if state.markWritten() {
-			writeResponse(w, state)
+			writeTimed(parentCtx, w, cfg, state)
 		}
+		flushCanonlog(parentCtx, cfg, state, r, start)
 
 	case <-ctx.Done():
 		state.mu.Lock()
 		state.err = ErrGatewayTimeout
+		state.timedOut = true
+		if !state.handlerExited {
+			abandonedHandlerCount.Add(1)
+		}
 		state.mu.Unlock()
+		state.markHandlerEnd()
 		if state.markWritten() {
-			writeResponse(w, state)
+			writeTimed(parentCtx, w, cfg, state)
 		}
 		waitForGrace(parentCtx, cfg, r, done, panicVal)
 		flushCanonlog(parentCtx, cfg, state, r, start)
@@ -210,16 +680,30 @@ func handleWithTimeout(parentCtx context.Context, cfg *config, next http.Handler
 func handlePanic(ctx context.Context, cfg *config, state *State, panicVal <-chan any) {
 	select {
 	case p := <-panicVal:
-		state.mu.Lock()
-		state.err = ErrInternal
-		state.mu.Unlock()
-		if cfg.canonlog {
-			canonlog.ErrorAdd(ctx, fmt.Errorf("panic: %v", p))
-		}
+		recordPanic(ctx, cfg, state, p)
 	default:
 	}
 }
 
+// recordPanic sets the error response for a recovered panic and logs it. Panics
+// with an error value are classified by apiErrorFrom (e.g., a wrapped
+// context.DeadlineExceeded becomes a 504) and logged with the full wrapped chain;
+// other values become ErrInternal.
+func recordPanic(ctx context.Context, cfg *config, state *State, rec any) {
+	apiErr := ErrInternal
+	logErr := fmt.Errorf("panic: %v", rec)
+	if err, ok := rec.(error); ok {
+		apiErr = apiErrorFrom(err)
+		logErr = fmt.Errorf("panic: %w", err)
+	}
+	state.mu.Lock()
+	state.err = apiErr
+	state.mu.Unlock()
+	if cfg.canonlog {
+		canonlog.ErrorAdd(ctx, logErr)
+	}
+}
+
 func waitForGrace(ctx context.Context, cfg *config, r *http.Request, done <-chan struct{}, panicVal <-chan any) {
 	select {
 	case <-done:
@@ -245,6 +729,13 @@ func flushCanonlog(ctx context.Context, cfg *config, state *State, r *http.Reque
 		return
 	}
 
+	if cfg.canonlogSampledOut {
+		if time.Since(start) < cfg.slowSampling {
+			return
+		}
+		canonlog.InfoAdd(ctx, "force_sampled", true)
+	}
+
 	// Take a snapshot to safely read state (handler may still be running)
 	snap := state.snapshot()
 
@@ -267,6 +758,21 @@ func flushCanonlog(ctx context.Context, cfg *config, state *State, r *http.Reque
 		"duration_ms": time.Since(start).Milliseconds(),
 	})
 
+	if cfg.logLevelFunc != nil {
+		escalateLogLevel(ctx, cfg.logLevelFunc(status, route), snap.err != nil)
+	}
+
+	// Phase breakdown: queue (before handler), handler, and response write
+	if !snap.phases.handlerStart.IsZero() {
+		canonlog.InfoAdd(ctx, "queue_ms", snap.phases.handlerStart.Sub(start).Milliseconds())
+		if !snap.phases.handlerEnd.IsZero() {
+			canonlog.InfoAdd(ctx, "handler_ms", snap.phases.handlerEnd.Sub(snap.phases.handlerStart).Milliseconds())
+		}
+	}
+	if !snap.phases.writeEnd.IsZero() {
+		canonlog.InfoAdd(ctx, "write_ms", snap.phases.writeEnd.Sub(snap.phases.writeStart).Milliseconds())
+	}
+
 	if cfg.slosEnabled {
 		if tier, target, ok := GetSLO(ctx); ok {
 			sloStatus := "PASS"
@@ -281,8 +787,22 @@ func flushCanonlog(ctx context.Context, cfg *config, state *State, r *http.Reque
 	canonlog.Flush(ctx)
 }
 
-// WaitForHandlers waits for all spawned handler goroutines to complete.
-// Call this during graceful shutdown after http.Server.Shutdown().
+// escalateLogLevel raises the canonical log line to level. canonlog v0.3.1 can only
+// escalate, so levels at or below Info are ignored.
+func escalateLogLevel(ctx context.Context, level LogLevel, hasErr bool) {
+	switch {
+	case level >= slog.LevelError:
+		if !hasErr {
+			canonlog.ErrorAdd(ctx, errLogLevelEscalated)
+		}
+	case level >= slog.LevelWarn:
+		canonlog.WarnAdd(ctx, "log_level", slog.LevelWarn.String())
+	}
+}
+
+// WaitForHandlers waits for all spawned handler goroutines, and goroutines started
+// with GoDetached, to complete. Call this during graceful shutdown after
+// http.Server.Shutdown(), or use Shutdown to also cancel detached work.
 // Returns nil if all handlers complete, or ctx.Err() if the context
 // deadline is exceeded.
 //
@@ -300,7 +820,7 @@ func flushCanonlog(ctx context.Context, cfg *config, state *State, r *http.Reque
 // Use ActiveHandlerCount to monitor how many handlers are still running.
 func WaitForHandlers(ctx context.Context) error {
 	for {
-		if activeHandlerCount.Load() == 0 {
+		if activeHandlerCount.Load() == 0 && detachedCount.Load() == 0 {
 			return nil
 		}
 		select {
@@ -320,45 +840,267 @@ func ActiveHandlerCount() int {
 	return int(activeHandlerCount.Load())
 }
 
+// writeTimed writes the response and records the write phase on state.
+func writeTimed(ctx context.Context, w http.ResponseWriter, cfg *config, state *State) {
+	if cfg.redactResponse {
+		state.mu.Lock()
+		state.body = Redact(state.body)
+		state.mu.Unlock()
+	}
+	if cfg.envelope {
+		state.mu.Lock()
+		state.body = withEnvelope(state.body)
+		state.mu.Unlock()
+	}
+	if cfg.nilBodyStatus != 0 {
+		state.mu.Lock()
+		if state.err == nil && state.body == nil && state.stream == nil && state.status >= 200 && state.status < 300 {
+			state.status = cfg.nilBodyStatus
+		}
+		state.mu.Unlock()
+	}
+	var observed *headerObserverWriter
+	if cfg.headerObserver != nil {
+		observed = &headerObserverWriter{ResponseWriter: w, ctx: ctx, fn: cfg.headerObserver}
+		w = observed
+	}
+	w, finishChecksum := wrapChecksum(w, cfg.checksum)
+	state.markWriteStart()
+	if !writeStream(ctx, w, cfg, state) && (cfg.htmlErrorPage == nil || !writeHTMLError(w, cfg.htmlErrorPage, state)) {
+		writeResponse(w, state)
+	}
+	finishChecksum()
+	if observed != nil {
+		// Status-only responses with no status set never call WriteHeader here;
+		// net/http sends the headers after the handler returns.
+		observed.observe()
+	}
+	state.markWriteEnd()
+}
+
+// encodeErrorEnvelope encodes the JSON error envelope for apiErr. A variable so
+// tests can exercise the plain-text fallback.
+var encodeErrorEnvelope = func(w io.Writer, apiErr *APIError) error {
+	return json.NewEncoder(w).Encode(errorResponse{Error: apiErr})
+}
+
 func writeResponse(w http.ResponseWriter, state *State) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 
-	for key, values := range state.headers {
-		for _, value := range values {
-			w.Header().Add(key, value)
-		}
+	writeStateHeaders(w, state)
+	if state.negotiate {
+		w.Header().Add("Vary", "Accept")
 	}
 
 	if state.err != nil {
-		buf := new(bytes.Buffer)
-		if err := json.NewEncoder(buf).Encode(errorResponse{Error: state.err}); err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte("Internal server error"))
+		writeErrorResponse(w, state)
+		return
+	}
+
+	body := withWarnings(state.body, state.warnings)
+
+	// Pre-encoded JSON is written as-is without re-encoding
+	if raw, ok := body.(json.RawMessage); ok {
+		if len(raw) == 0 {
+			w.WriteHeader(state.status)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
+		writeBody(w, state, state.status, "application/json", raw)
+		return
+	}
+
+	// Non-JSON bodies captured from legacy handlers by Adapt
+	if raw, ok := body.(rawResponse); ok {
+		writeBody(w, state, state.status, raw.contentType, raw.body)
+		return
+	}
+
+	if body != nil {
+		writeEncodedBody(w, state, body)
+		return
+	}
+
+	if state.status != 0 {
+		w.WriteHeader(state.status)
+	}
+}
+
+// writeErrorResponse writes state.err as XML when negotiated, as problem details
+// when WithProblemDetails is set, or in the JSON error envelope. Must be called
+// with state.mu held.
+func writeErrorResponse(w http.ResponseWriter, state *State) {
+	if contentType, body, ok := encodeNegotiatedError(state); ok {
+		writeBody(w, state, state.err.Status, contentType, body)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err := encodeErrorEnvelope(buf, state.err); err != nil {
+		// Keep the intended status so a 4xx is not masked as a 5xx
+		message := state.err.Message
+		if message == "" {
+			message = http.StatusText(state.err.Status)
+		}
+		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(state.err.Status)
-		w.Write(buf.Bytes())
+		w.Write([]byte(message))
 		return
 	}
+	writeBody(w, state, state.err.Status, "application/json", buf.Bytes())
+}
 
-	if state.body != nil {
-		buf := new(bytes.Buffer)
-		if err := json.NewEncoder(buf).Encode(state.body); err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte("Internal server error"))
+// encodeNegotiatedError encodes state.err as XML or problem details when either
+// applies. Returns false to fall back to the JSON error envelope.
+func encodeNegotiatedError(state *State) (string, []byte, bool) {
+	if state.xml {
+		if body, err := encodeXMLError(state.err); err == nil {
+			return "application/xml; charset=utf-8", body, true
+		}
+	}
+	if state.problem {
+		if body, err := encodeProblem(state.err); err == nil {
+			return "application/problem+json", body, true
+		}
+	}
+	return "", nil, false
+}
+
+// writeEncodedBody encodes body as XML when negotiated, otherwise as JSON.
+// Must be called with state.mu held.
+func writeEncodedBody(w http.ResponseWriter, state *State, body any) {
+	if state.xml {
+		if encoded, err := encodeXML(body); err == nil {
+			writeBody(w, state, state.status, "application/xml; charset=utf-8", encoded)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(state.status)
-		w.Write(buf.Bytes())
+	}
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(body); err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Internal server error"))
 		return
 	}
+	writeBody(w, state, state.status, "application/json", buf.Bytes())
+}
 
-	if state.status != 0 {
-		w.WriteHeader(state.status)
+// writeStream writes a response set with SetStream: the status and headers, then the
+// stream callback's output directly to w. The state lock is released before the
+// callback runs, so a long stream does not block late setters. Returns false if
+// state holds no stream or holds an error, which takes precedence.
+func writeStream(ctx context.Context, w http.ResponseWriter, cfg *config, state *State) bool {
+	state.mu.Lock()
+	if state.stream == nil || state.err != nil {
+		state.mu.Unlock()
+		return false
+	}
+	stream, status := state.stream, state.status
+	writeStateHeaders(w, state)
+	state.mu.Unlock()
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+
+	defer func() {
+		if rec := recover(); rec != nil && cfg.canonlog {
+			canonlog.ErrorAdd(ctx, fmt.Errorf("panic in stream: %v", rec))
+		}
+	}()
+	if err := stream(w); err != nil && cfg.canonlog {
+		canonlog.ErrorAdd(ctx, fmt.Errorf("stream: %w", err))
+	}
+	return true
+}
+
+// headerObserverWriter calls a WithHeaderObserver callback with a copy of the
+// response headers right before they are sent.
+type headerObserverWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	fn       func(context.Context, http.Header)
+	observed bool
+}
+
+func (w *headerObserverWriter) observe() {
+	if !w.observed {
+		w.observed = true
+		w.fn(w.ctx, w.Header().Clone())
+	}
+}
+
+func (w *headerObserverWriter) WriteHeader(status int) {
+	w.observe()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *headerObserverWriter) Write(b []byte) (int, error) {
+	w.observe()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *headerObserverWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeStateHeaders copies headers and cookies set on state to w.
+// Must be called with state.mu held.
+func writeStateHeaders(w http.ResponseWriter, state *State) {
+	for key, values := range state.headers {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	for _, c := range state.cookies {
+		http.SetCookie(w, c)
+	}
+}
+
+// writeBody writes status and body with contentType, applying WithETag,
+// SetWeakETagLazy, and WithCompression. Must be called with state.mu held.
+func writeBody(w http.ResponseWriter, state *State, status int, contentType string, body []byte) {
+	if writeNotModified(w, state, status, body) {
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if state.compressMin > 0 && len(body) >= state.compressMin &&
+		w.Header().Get("Content-Encoding") == "" && compressibleType(contentType) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if state.encoding != "" {
+			if compressed, err := compressBody(state.encoding, body); err == nil {
+				w.Header().Set("Content-Encoding", state.encoding)
+				w.Header().Del("Content-Length")
+				body = compressed
+			}
+		}
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// writeNotModified sets the ETag for a 2xx body when WithETag or SetWeakETagLazy
+// applies, keeping one set by the handler, and writes 304 (Not Modified) if it
+// matches If-None-Match. Returns true if the 304 was written.
+// Must be called with state.mu held.
+func writeNotModified(w http.ResponseWriter, state *State, status int, body []byte) bool {
+	wantETag := state.etag || (state.lazyETag && state.ifNoneMatch != "")
+	if !wantETag || state.err != nil || status < 200 || status >= 300 {
+		return false
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		etag = computeETag(body)
+		if state.lazyETag {
+			etag = "W/" + etag
+		}
+		w.Header().Set("ETag", etag)
+	}
+	if !etagMatches(state.ifNoneMatch, etag) {
+		return false
 	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
 }
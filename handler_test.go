@@ -1,13 +1,18 @@
 package chikit
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -111,6 +116,47 @@ func TestHandler_PanicRecovery(t *testing.T) {
 	}
 }
 
+func TestHandler_PanicWithError(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          any
+		expectedStatus int
+		expectedCode   ErrorCode
+	}{
+		{"wrapped deadline", fmt.Errorf("db: query users: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, ErrorCodeGatewayTimeout},
+		{"wrapped api error", fmt.Errorf("lookup: %w", ErrNotFound), http.StatusNotFound, ErrorCodeNotFound},
+		{"plain error", errors.New("boom"), http.StatusInternalServerError, ErrorCodeInternal},
+		{"non-error value", 42, http.StatusInternalServerError, ErrorCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureCanonlog(t)
+			handler := Handler(WithCanonlog())(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				panic(tt.value)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			var body map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body["error"].Code != tt.expectedCode {
+				t.Errorf("expected code %s, got %s", tt.expectedCode, body["error"].Code)
+			}
+
+			if tt.name == "wrapped deadline" && !strings.Contains(buf.String(), "panic: db: query users: context deadline exceeded") {
+				t.Errorf("expected log to contain the full error chain, got %s", buf.String())
+			}
+		})
+	}
+}
+
 func TestHandler_CustomHeaders(t *testing.T) {
 	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
 		SetHeader(r, "X-Request-ID", "abc123")
@@ -160,6 +206,52 @@ func TestHandler_StatusOnlyResponse(t *testing.T) {
 	}
 }
 
+func TestHandler_WithNilBodyStatus(t *testing.T) {
+	noContent := []HandlerOption{WithNilBodyStatus(http.StatusNoContent)}
+
+	tests := []struct {
+		name     string
+		opts     []HandlerOption
+		status   int
+		body     any
+		expected int
+	}{
+		{"nil body 200 rewritten", noContent, http.StatusOK, nil, http.StatusNoContent},
+		{"nil body 201 rewritten", noContent, http.StatusCreated, nil, http.StatusNoContent},
+		{"body not rewritten", noContent, http.StatusOK, map[string]string{"a": "b"}, http.StatusOK},
+		{"non-2xx not rewritten", noContent, http.StatusNotModified, nil, http.StatusNotModified},
+		{"default unchanged", nil, http.StatusOK, nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				SetResponse(r, tt.status, tt.body)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", http.NoBody))
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_WithNilBodyStatus_ErrorUnchanged(t *testing.T) {
+	handler := Handler(WithNilBodyStatus(http.StatusNoContent))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetError(r, ErrNotFound)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
 func TestHasState(t *testing.T) {
 	var hasStateInHandler bool
 
@@ -262,9 +354,12 @@ func TestAllSentinelErrors(t *testing.T) {
 		ErrMethodNotAllowed,
 		ErrConflict,
 		ErrGone,
+		ErrPreconditionFailed,
 		ErrPayloadTooLarge,
+		ErrUnsupportedMedia,
 		ErrUnprocessableEntity,
 		ErrRateLimited,
+		ErrQuotaExceeded,
 		ErrInternal,
 		ErrNotImplemented,
 		ErrServiceUnavailable,
@@ -317,6 +412,388 @@ func TestAPIError_WithParamNilReceiver(t *testing.T) {
 	}
 }
 
+func TestAPIError_WithDocsAndActionNilReceiver(t *testing.T) {
+	var nilErr *APIError
+
+	if nilErr.WithDocs("https://docs.example.com") != nil {
+		t.Error("expected WithDocs() on nil receiver to return nil")
+	}
+	if nilErr.WithAction("Retry later") != nil {
+		t.Error("expected WithAction() on nil receiver to return nil")
+	}
+}
+
+func TestAPIError_WithDocsAndAction(t *testing.T) {
+	apiErr := ErrUnauthorized.With("Token expired").
+		WithDocs("https://docs.example.com/errors/token-expired").
+		WithAction("Refresh the access token and retry")
+
+	if ErrUnauthorized.DocsURL != "" || ErrUnauthorized.SuggestedAction != "" {
+		t.Error("expected sentinel to be unmodified")
+	}
+
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetError(r, apiErr)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	var resp map[string]map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp["error"]["docs_url"] != "https://docs.example.com/errors/token-expired" {
+		t.Errorf("expected docs_url, got %v", resp["error"]["docs_url"])
+	}
+	if resp["error"]["suggested_action"] != "Refresh the access token and retry" {
+		t.Errorf("expected suggested_action, got %v", resp["error"]["suggested_action"])
+	}
+	if resp["error"]["message"] != "Token expired" {
+		t.Errorf("expected message to be preserved, got %v", resp["error"]["message"])
+	}
+}
+
+func TestAPIError_DocsAndActionOmittedWhenEmpty(t *testing.T) {
+	data, err := json.Marshal(ErrNotFound)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if strings.Contains(string(data), "docs_url") || strings.Contains(string(data), "suggested_action") {
+		t.Errorf("expected empty fields to be omitted, got %s", data)
+	}
+}
+
+func TestSetJSONResponse_WritesVerbatim(t *testing.T) {
+	raw := json.RawMessage(`{"id": "1",  "name": "cached"}`)
+
+	tests := []struct {
+		name string
+		set  func(r *http.Request)
+	}{
+		{"SetJSONResponse", func(r *http.Request) { SetJSONResponse(r, http.StatusOK, raw) }},
+		{"SetResponse with RawMessage", func(r *http.Request) { SetResponse(r, http.StatusOK, raw) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				tt.set(r)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status 200, got %d", rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected Content-Type application/json, got %s", ct)
+			}
+			if rec.Body.String() != string(raw) {
+				t.Errorf("expected body written verbatim, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetJSONResponse_Empty(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetJSONResponse(r, http.StatusAccepted, nil)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestDeleted(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"nil body", nil, http.StatusNoContent, ""},
+		{"deleted resource", map[string]string{"id": "123"}, http.StatusOK, `{"id":"123"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				Deleted(r, tt.body)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users/123", http.NoBody))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, got)
+			}
+		})
+	}
+}
+
+func TestWithEnvelope(t *testing.T) {
+	type user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name           string
+		handler        func(r *http.Request)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "struct body",
+			handler:        func(r *http.Request) { SetResponse(r, http.StatusCreated, user{ID: "123", Name: "Alice"}) },
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"data":{"id":"123","name":"Alice"}}`,
+		},
+		{
+			name:           "error",
+			handler:        func(r *http.Request) { SetError(r, ErrNotFound.With("User not found")) },
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":{"type":"not_found","code":"resource_not_found","message":"User not found"}}`,
+		},
+		{
+			name:           "nil body",
+			handler:        func(r *http.Request) { SetResponse(r, http.StatusNoContent, nil) },
+			expectedStatus: http.StatusNoContent,
+			expectedBody:   "",
+		},
+		{
+			name: "warnings",
+			handler: func(r *http.Request) {
+				AddWarning(r, "age", "coerced", "rounded to an integer")
+				SetResponse(r, http.StatusOK, user{ID: "1"})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"id":"1","name":""},"warnings":[{"param":"age","code":"coerced","message":"rounded to an integer"}]}`,
+		},
+		{
+			name:           "meta",
+			handler:        func(r *http.Request) { SetResponseWithMeta(r, http.StatusOK, []int{1}, map[string]any{"total": 1}) },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[1],"meta":{"total":1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(WithEnvelope())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				tt.handler(r)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", http.NoBody))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.expectedBody {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, got)
+			}
+		})
+	}
+}
+
+func TestSetCookie(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var wg sync.WaitGroup
+		for _, name := range []string{"session", "csrf"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				SetCookie(r, &http.Cookie{Name: name, Value: name + "-value", Path: "/", HttpOnly: true})
+			}()
+		}
+		wg.Wait()
+		SetHeader(r, "X-Request-ID", "abc123")
+		SetResponse(r, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", http.NoBody))
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("expected 2 cookies, got %d: %v", len(cookies), rec.Header().Values("Set-Cookie"))
+	}
+	for _, c := range cookies {
+		if c.Value != c.Name+"-value" || !c.HttpOnly || c.Path != "/" {
+			t.Errorf("unexpected cookie %+v", c)
+		}
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "abc123" {
+		t.Errorf("expected X-Request-ID abc123, got %q", got)
+	}
+}
+
+func TestSetCookie_ErrorResponse(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetCookie(r, &http.Cookie{Name: "session", Value: "", MaxAge: -1})
+		SetError(r, ErrUnauthorized)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if got := rec.Header().Get("Set-Cookie"); !strings.Contains(got, "session=") || !strings.Contains(got, "Max-Age=0") {
+		t.Errorf("expected session cookie to be cleared on error response, got %q", got)
+	}
+}
+
+func TestWithHeaderObserver(t *testing.T) {
+	tests := []struct {
+		name                string
+		handler             func(r *http.Request)
+		expectedContentType string
+	}{
+		{
+			name: "success",
+			handler: func(r *http.Request) {
+				SetHeader(r, "Strict-Transport-Security", "max-age=63072000")
+				SetResponse(r, http.StatusOK, map[string]string{"status": "ok"})
+			},
+			expectedContentType: "application/json",
+		},
+		{
+			name: "error",
+			handler: func(r *http.Request) {
+				SetHeader(r, "Strict-Transport-Security", "max-age=63072000")
+				SetError(r, ErrNotFound)
+			},
+			expectedContentType: "application/json",
+		},
+		{
+			name: "no body",
+			handler: func(r *http.Request) {
+				SetHeader(r, "Strict-Transport-Security", "max-age=63072000")
+				SetResponse(r, http.StatusNoContent, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var observed []http.Header
+			handler := Handler(WithHeaderObserver(func(_ context.Context, h http.Header) {
+				observed = append(observed, h)
+				h.Set("X-Injected", "1")
+			}))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				tt.handler(r)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			if len(observed) != 1 {
+				t.Fatalf("expected observer to be called once, got %d", len(observed))
+			}
+			if got := observed[0].Get("Content-Type"); got != tt.expectedContentType {
+				t.Errorf("expected observed Content-Type %q, got %q", tt.expectedContentType, got)
+			}
+			if got := observed[0].Get("Strict-Transport-Security"); got != "max-age=63072000" {
+				t.Errorf("expected observed HSTS header, got %q", got)
+			}
+			if rec.Header().Get("X-Injected") != "" {
+				t.Error("expected observer changes not to affect the response")
+			}
+		})
+	}
+}
+
+func TestAddWarning(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		AddWarning(r, "legacy_name", "deprecated", "use name instead")
+		AddWarning(r, "age", "coerced", "rounded to an integer")
+		SetResponse(r, http.StatusOK, map[string]string{"id": "1"})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp struct {
+		Data     map[string]string `json:"data"`
+		Warnings []FieldError      `json:"warnings"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Data["id"] != "1" {
+		t.Errorf("expected data to be preserved, got %v", resp.Data)
+	}
+	if len(resp.Warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %d", len(resp.Warnings))
+	}
+	if resp.Warnings[0] != (FieldError{Param: "legacy_name", Code: "deprecated", Message: "use name instead"}) {
+		t.Errorf("unexpected first warning: %+v", resp.Warnings[0])
+	}
+}
+
+func TestAddWarning_AbsentWhenNone(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, map[string]string{"id": "1"})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if strings.TrimSpace(rec.Body.String()) != `{"id":"1"}` {
+		t.Errorf("expected unwrapped body, got %q", rec.Body.String())
+	}
+}
+
+func TestAddWarning_WithMeta(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		AddWarning(r, "page_size", "clamped", "reduced to 100")
+		SetResponseWithMeta(r, http.StatusOK, []string{"a"}, map[string]any{"total": 1})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	var resp map[string]json.RawMessage
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, key := range []string{"data", "meta", "warnings"} {
+		if _, ok := resp[key]; !ok {
+			t.Errorf("expected %s in body, got %v", key, resp)
+		}
+	}
+}
+
+func TestAddWarning_IgnoredForErrors(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		AddWarning(r, "legacy_name", "deprecated", "use name instead")
+		SetError(r, ErrNotFound)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if strings.Contains(rec.Body.String(), "warnings") {
+		t.Errorf("expected no warnings in error body, got %q", rec.Body.String())
+	}
+}
+
 func TestHandler_JSONEncodingFailureBody(t *testing.T) {
 	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
 		unencodable := make(chan int)
@@ -492,6 +969,176 @@ func TestHandler_ConcurrentMixedOperations(t *testing.T) {
 	}
 }
 
+func TestHandler_ConcurrentSetStream(t *testing.T) {
+	const goroutines = 50
+
+	var streamCalls atomic.Int32
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var wg sync.WaitGroup
+		wg.Add(goroutines * 2)
+
+		for i := 0; i < goroutines; i++ {
+			go func(idx int) {
+				defer wg.Done()
+				SetStream(r, http.StatusOK, func(w io.Writer) error {
+					streamCalls.Add(1)
+					_, err := fmt.Fprintf(w, "stream %d", idx)
+					return err
+				})
+			}(i)
+
+			go func(_ int) {
+				defer wg.Done()
+				SetHeader(r, "X-Test", "value")
+			}(i)
+		}
+
+		wg.Wait()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if streamCalls.Load() != 1 || !strings.HasPrefix(rec.Body.String(), "stream ") {
+		t.Errorf("expected exactly one stream to be written, got %d calls and body %q", streamCalls.Load(), rec.Body.String())
+	}
+	if rec.Header().Get("X-Test") != "value" {
+		t.Errorf("expected X-Test header, got %q", rec.Header().Get("X-Test"))
+	}
+}
+
+func TestSetStream_FirstWins(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetStream(r, http.StatusOK, func(w io.Writer) error {
+			_, err := io.WriteString(w, "first")
+			return err
+		})
+		SetStream(r, http.StatusAccepted, func(w io.Writer) error {
+			_, err := io.WriteString(w, "second")
+			return err
+		})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "first" {
+		t.Errorf("expected 200 first, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSetStream(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetHeader(r, "Content-Type", "text/event-stream")
+		SetHeader(r, "Cache-Control", "no-cache")
+		SetStream(r, http.StatusOK, func(w io.Writer) error {
+			for i := range 3 {
+				if _, err := fmt.Fprintf(w, "data: %d\n\n", i); err != nil {
+					return err
+				}
+				if err := http.NewResponseController(w.(http.ResponseWriter)).Flush(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", got)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("expected Cache-Control no-cache, got %q", got)
+	}
+	if !rec.Flushed {
+		t.Error("expected stream to flush")
+	}
+	if got := rec.Body.String(); got != "data: 0\n\ndata: 1\n\ndata: 2\n\n" {
+		t.Errorf("unexpected body %q", got)
+	}
+}
+
+func TestSetStream_ErrorTakesPrecedence(t *testing.T) {
+	called := false
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetStream(r, http.StatusOK, func(io.Writer) error {
+			called = true
+			return nil
+		})
+		SetError(r, ErrForbidden)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusForbidden || called {
+		t.Errorf("expected 403 without streaming, got %d (stream called=%v)", rec.Code, called)
+	}
+}
+
+func TestSetStream_WithTimeout(t *testing.T) {
+	handler := Handler(WithTimeout(time.Second))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetStream(r, http.StatusAccepted, func(w io.Writer) error {
+			_, err := io.WriteString(w, "chunk")
+			return err
+		})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "chunk" {
+		t.Errorf("expected 202 with streamed body, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSetStream_LogsPanicsAndErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(io.Writer) error
+		expected string
+	}{
+		{"panic", func(w io.Writer) error {
+			io.WriteString(w, "partial")
+			panic("stream exploded")
+		}, "panic in stream: stream exploded"},
+		{"error", func(w io.Writer) error {
+			io.WriteString(w, "partial")
+			return errors.New("client went away")
+		}, "stream: client went away"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureCanonlog(t)
+			handler := Handler(WithCanonlog())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				SetStream(r, http.StatusOK, tt.write)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+				t.Errorf("expected the already-sent 200 and partial body, got %d %q", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("expected log to contain %q, got %s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
 func TestWithCanonlog_CreatesLogger(t *testing.T) {
 	var loggerFound bool
 
@@ -864,16 +1511,210 @@ func TestHandler_Timeout_Abandoned(t *testing.T) {
 	}
 }
 
-func TestHandler_Timeout_NoTimeoutConfigured(t *testing.T) {
-	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
-		time.Sleep(10 * time.Millisecond)
-		SetResponse(r, http.StatusOK, map[string]string{"status": "ok"})
-	}))
+func TestHandler_MaxAbandonedHandlers(t *testing.T) {
+	waitForAbandonedHandlers(t)
 
-	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
-	rec := httptest.NewRecorder()
+	release := make(chan struct{})
+	var calls atomic.Int32
 
-	handler.ServeHTTP(rec, req)
+	handler := Handler(
+		WithTimeout(10*time.Millisecond),
+		WithGracefulShutdown(10*time.Millisecond),
+		WithMaxAbandonedHandlers(1),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status %d, got %d", http.StatusGatewayTimeout, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d once cap is reached, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected handler to be called once, got %d", got)
+	}
+
+	close(release)
+	waitForAbandonedHandlers(t)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d after abandoned handler exits, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+// waitForAbandonedHandlers waits for handlers abandoned by earlier tests to exit.
+func waitForAbandonedHandlers(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for abandonedHandlerCount.Load() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("abandoned handlers did not exit: %d", abandonedHandlerCount.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandler_HardDeadline_CutsOffRetries(t *testing.T) {
+	var attempts atomic.Int32
+
+	handler := Handler(
+		WithTimeout(time.Second),
+		WithHardDeadline(100*time.Millisecond),
+		WithGracefulShutdown(time.Second),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		// Each attempt is short, but the retry loop would run for ~1s
+		for range 50 {
+			attemptCtx, cancel := context.WithTimeout(r.Context(), 20*time.Millisecond)
+			<-attemptCtx.Done()
+			cancel()
+			if r.Context().Err() != nil {
+				SetError(r, ErrServiceUnavailable)
+				return
+			}
+			attempts.Add(1)
+		}
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	elapsed := time.Since(start)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, rec.Code)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("expected cutoff near hard deadline, took %v", elapsed)
+	}
+	if n := attempts.Load(); n == 0 || n >= 10 {
+		t.Errorf("expected a few attempts before the deadline, got %d", n)
+	}
+}
+
+func TestHandler_HardDeadline_WithoutTimeout(t *testing.T) {
+	handler := Handler(
+		WithHardDeadline(30*time.Millisecond),
+		WithGracefulShutdown(time.Second),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, rec.Code)
+	}
+}
+
+func TestHandler_HardDeadline_NotExceeded(t *testing.T) {
+	handler := Handler(WithHardDeadline(time.Second))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestHandler_TimeoutFromSLO(t *testing.T) {
+	var remaining time.Duration
+	handler := Handler(
+		WithTimeoutFromSLO(2),
+		WithTimeout(time.Hour),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		deadline, _ := r.Context().Deadline()
+		remaining = time.Until(deadline)
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	effective := func(mw func(http.Handler) http.Handler) time.Duration {
+		rec := httptest.NewRecorder()
+		mw(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+		return remaining
+	}
+	noSLO := func(next http.Handler) http.Handler { return next }
+
+	tight := effective(SLO(SLOCritical))
+	relaxed := effective(SLO(SLOLow))
+	fallback := effective(noSLO)
+
+	if tight > 100*time.Millisecond || tight < 50*time.Millisecond {
+		t.Errorf("expected ~100ms for SLOCritical, got %v", tight)
+	}
+	if relaxed > 10*time.Second || relaxed < 9*time.Second {
+		t.Errorf("expected ~10s for SLOLow, got %v", relaxed)
+	}
+	if fallback < 59*time.Minute {
+		t.Errorf("expected WithTimeout fallback without an SLO, got %v", fallback)
+	}
+}
+
+func TestHandler_TimeoutFromSLO_Fires(t *testing.T) {
+	handler := SLOWithTarget(10 * time.Millisecond)(Handler(
+		WithTimeoutFromSLO(2),
+		WithGracefulShutdown(time.Second),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})))
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected cutoff near 20ms, took %v", elapsed)
+	}
+}
+
+func TestHandler_TimeoutFromSLO_SLOAfterHandler(t *testing.T) {
+	var remaining time.Duration
+	handler := Handler(
+		WithTimeoutFromSLO(2),
+		WithTimeout(time.Hour),
+	)(SLO(SLOCritical)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		deadline, _ := r.Context().Deadline()
+		remaining = time.Until(deadline)
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if remaining < 59*time.Minute {
+		t.Errorf("expected WithTimeout when the SLO is set after Handler, got %v", remaining)
+	}
+}
+
+func TestHandler_Timeout_NoTimeoutConfigured(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		SetResponse(r, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
 
 	if rec.Code != http.StatusOK {
 		t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
@@ -1065,3 +1906,533 @@ func TestHandler_Timeout_Concurrent(t *testing.T) {
 		t.Errorf("expected %d successes, got %d", numRequests, successes)
 	}
 }
+
+// captureCanonlog redirects the default slog logger to a buffer for the duration of the test.
+func captureCanonlog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return buf
+}
+
+func decodeCanonlog(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to decode log line %q: %v", buf.String(), err)
+	}
+	return entry
+}
+
+func TestWithCanonlog_LogsTimingPhases(t *testing.T) {
+	buf := captureCanonlog(t)
+
+	handler := Handler(WithCanonlog())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		SetResponse(r, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := decodeCanonlog(t, buf)
+	for _, field := range []string{"queue_ms", "handler_ms", "write_ms", "duration_ms"} {
+		if _, ok := entry[field]; !ok {
+			t.Errorf("expected %s in log entry, got %v", field, entry)
+		}
+	}
+
+	handlerMs := entry["handler_ms"].(float64)
+	if handlerMs < 30 {
+		t.Errorf("expected handler_ms >= 30, got %v", handlerMs)
+	}
+
+	sum := entry["queue_ms"].(float64) + handlerMs + entry["write_ms"].(float64)
+	duration := entry["duration_ms"].(float64)
+	if sum > duration || duration-sum > 5 {
+		t.Errorf("expected phases (%v) to sum to roughly duration_ms (%v)", sum, duration)
+	}
+}
+
+func TestWithCanonlog_TimingPhasesWithTimeout(t *testing.T) {
+	buf := captureCanonlog(t)
+
+	handler := Handler(WithCanonlog(), WithTimeout(time.Second))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := decodeCanonlog(t, buf)
+	for _, field := range []string{"queue_ms", "handler_ms", "write_ms"} {
+		if _, ok := entry[field]; !ok {
+			t.Errorf("expected %s in log entry, got %v", field, entry)
+		}
+	}
+}
+
+func TestSetResponseWithMeta(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponseWithMeta(r, http.StatusOK, []string{"a", "b"}, map[string]any{
+			"total":               2,
+			"ratelimit_remaining": 99,
+		})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var body struct {
+		Data []string       `json:"data"`
+		Meta map[string]any `json:"meta"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(body.Data) != 2 || body.Data[0] != "a" {
+		t.Errorf("expected data [a b], got %v", body.Data)
+	}
+	if body.Meta["total"] != float64(2) {
+		t.Errorf("expected meta.total=2, got %v", body.Meta["total"])
+	}
+	if body.Meta["ratelimit_remaining"] != float64(99) {
+		t.Errorf("expected meta.ratelimit_remaining=99, got %v", body.Meta["ratelimit_remaining"])
+	}
+}
+
+func TestSetResponseWithMeta_NilMeta(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponseWithMeta(r, http.StatusOK, map[string]string{"id": "1"}, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	expected := `{"data":{"id":"1"},"meta":{}}` + "\n"
+	if rec.Body.String() != expected {
+		t.Errorf("expected body %q, got %q", expected, rec.Body.String())
+	}
+}
+
+func TestStrictMode_PanicsOnPostCommitMutation(t *testing.T) {
+	SetStrictMode(true)
+	defer SetStrictMode(false)
+
+	var captured *http.Request
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		captured = r
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	mutations := map[string]func(){
+		"SetError":        func() { SetError(captured, ErrInternal) },
+		"SetResponse":     func() { SetResponse(captured, http.StatusCreated, nil) },
+		"SetHeader":       func() { SetHeader(captured, "X-Late", "1") },
+		"AddHeader":       func() { AddHeader(captured, "X-Late", "1") },
+		"SetCookie":       func() { SetCookie(captured, &http.Cookie{Name: "late", Value: "1"}) },
+		"SetStream":       func() { SetStream(captured, http.StatusOK, func(io.Writer) error { return nil }) },
+		"SetWeakETagLazy": func() { SetWeakETagLazy(captured) },
+	}
+
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected %s to panic after response was written", name)
+				}
+			}()
+			mutate()
+		})
+	}
+}
+
+func TestStrictMode_DisabledIgnoresPostCommitMutation(t *testing.T) {
+	var captured *http.Request
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		captured = r
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	SetError(captured, ErrInternal)
+	SetResponse(captured, http.StatusCreated, nil)
+	SetHeader(captured, "X-Late", "1")
+	AddHeader(captured, "X-Late", "1")
+	SetCookie(captured, &http.Cookie{Name: "late", Value: "1"})
+	SetStream(captured, http.StatusOK, func(io.Writer) error { return nil })
+	SetWeakETagLazy(captured)
+}
+
+func TestStrictMode_IgnoresMutationAfterTimeout(t *testing.T) {
+	SetStrictMode(true)
+	defer SetStrictMode(false)
+
+	finished := make(chan struct{})
+	var panicked bool
+
+	handler := Handler(WithTimeout(20*time.Millisecond), WithGracefulShutdown(time.Second))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		defer close(finished)
+		defer func() {
+			if recover() != nil {
+				panicked = true
+			}
+		}()
+		<-r.Context().Done()
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	<-finished
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, rec.Code)
+	}
+	if panicked {
+		t.Error("expected mutation after timeout not to panic in strict mode")
+	}
+}
+
+func TestWithCanonlogSkip(t *testing.T) {
+	handler := Handler(
+		WithCanonlog(),
+		WithSLOs(),
+		WithCanonlogSkip(func(r *http.Request) bool {
+			return r.URL.Path == "/healthz"
+		}),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_, loggerFound := canonlog.TryGetLogger(r.Context())
+		SetResponse(r, http.StatusOK, map[string]bool{"logger": loggerFound})
+	}))
+
+	tests := []struct {
+		path         string
+		expectLogger bool
+	}{
+		{"/healthz", false},
+		{"/users", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			buf := captureCanonlog(t)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+			}
+
+			var body map[string]bool
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body["logger"] != tt.expectLogger {
+				t.Errorf("expected logger in context = %v, got %v", tt.expectLogger, body["logger"])
+			}
+			if logged := buf.Len() > 0; logged != tt.expectLogger {
+				t.Errorf("expected log output = %v, got %q", tt.expectLogger, buf.String())
+			}
+		})
+	}
+}
+
+func TestWithLogLevelFunc(t *testing.T) {
+	handler := Handler(
+		WithCanonlog(),
+		WithLogLevelFunc(func(status int, route string) LogLevel {
+			switch {
+			case route == "/probe":
+				return slog.LevelDebug
+			case status >= http.StatusInternalServerError:
+				return slog.LevelError
+			case status == http.StatusUnauthorized:
+				return slog.LevelWarn
+			}
+			return slog.LevelInfo
+		}),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unauthorized":
+			SetResponse(r, http.StatusUnauthorized, nil)
+		case "/unavailable":
+			SetResponse(r, http.StatusServiceUnavailable, nil)
+		case "/internal":
+			SetError(r, ErrInternal)
+		default:
+			SetResponse(r, http.StatusOK, nil)
+		}
+	}))
+
+	tests := []struct {
+		name         string
+		path         string
+		expectLevel  string
+		expectErrors int
+	}{
+		{"info unchanged", "/ok", "INFO", 0},
+		{"warn escalation", "/unauthorized", "WARN", 0},
+		{"error escalation without error", "/unavailable", "ERROR", 1},
+		{"error escalation with error", "/internal", "ERROR", 1},
+		{"downgrade ignored", "/probe", "INFO", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureCanonlog(t)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entry := decodeCanonlog(t, buf)
+			if entry["level"] != tt.expectLevel {
+				t.Errorf("expected level %s, got %v", tt.expectLevel, entry["level"])
+			}
+			errs, _ := entry["errors"].([]any)
+			if len(errs) != tt.expectErrors {
+				t.Errorf("expected %d errors, got %v", tt.expectErrors, entry["errors"])
+			}
+			if tt.expectLevel == "WARN" && entry["log_level"] != "WARN" {
+				t.Errorf("expected log_level=WARN, got %v", entry["log_level"])
+			}
+		})
+	}
+}
+
+func TestWithSlowRequestSampling(t *testing.T) {
+	handler := Handler(
+		WithCanonlog(),
+		WithCanonlogSkip(func(r *http.Request) bool {
+			return r.URL.Query().Get("sampled") != "true"
+		}),
+		WithSlowRequestSampling(30*time.Millisecond),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "true" {
+			time.Sleep(40 * time.Millisecond)
+		}
+		SetResponse(r, http.StatusOK, nil)
+	}))
+
+	tests := []struct {
+		name         string
+		query        string
+		expectLogged bool
+		expectForced bool
+	}{
+		{"fast sampled out", "", false, false},
+		{"slow sampled out", "slow=true", true, true},
+		{"fast sampled in", "sampled=true", true, false},
+		{"slow sampled in", "sampled=true&slow=true", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureCanonlog(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, http.NoBody)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if logged := buf.Len() > 0; logged != tt.expectLogged {
+				t.Fatalf("expected log output = %v, got %q", tt.expectLogged, buf.String())
+			}
+			if !tt.expectLogged {
+				return
+			}
+			entry := decodeCanonlog(t, buf)
+			if _, forced := entry["force_sampled"]; forced != tt.expectForced {
+				t.Errorf("expected force_sampled = %v, got %v", tt.expectForced, entry)
+			}
+		})
+	}
+}
+
+func TestBuiltinMiddleware_DualModeErrors(t *testing.T) {
+	reject := func(string) bool { return false }
+
+	tests := []struct {
+		name       string
+		middleware func(http.Handler) http.Handler
+		request    func() *http.Request
+		status     int
+		message    string
+	}{
+		{
+			"APIKey", APIKey(reject),
+			func() *http.Request { return httptest.NewRequest("GET", "/", http.NoBody) },
+			http.StatusUnauthorized, "Missing API key",
+		},
+		{
+			"BearerToken", BearerToken(reject),
+			func() *http.Request {
+				req := httptest.NewRequest("GET", "/", http.NoBody)
+				req.Header.Set("Authorization", "Bearer nope")
+				return req
+			},
+			http.StatusUnauthorized, "Invalid bearer token",
+		},
+		{
+			"RateLimiter", NewRateLimiter(&errorStore{}, 10, time.Minute, RateLimitWithHeaderRequired("X-API-Key")).Handler,
+			func() *http.Request { return httptest.NewRequest("GET", "/", http.NoBody) },
+			http.StatusBadRequest, "Missing required header X-API-Key",
+		},
+		{
+			"MaxBodySize", MaxBodySize(4),
+			func() *http.Request { return httptest.NewRequest("POST", "/", strings.NewReader("too large")) },
+			http.StatusRequestEntityTooLarge, "Request body too large",
+		},
+		{
+			"ExtractHeader", ExtractHeader("X-Tenant-ID", "tenant", ExtractRequired()),
+			func() *http.Request { return httptest.NewRequest("GET", "/", http.NoBody) },
+			http.StatusBadRequest, "Missing required header: X-Tenant-ID",
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Handler()(tt.middleware(next)).ServeHTTP(rec, tt.request())
+
+			if rec.Code != tt.status {
+				t.Errorf("wrapped: expected status %d, got %d", tt.status, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("wrapped: expected Content-Type application/json, got %s", ct)
+			}
+			var resp map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("wrapped: failed to decode response: %v", err)
+			}
+			if resp["error"].Message != tt.message {
+				t.Errorf("wrapped: expected message %q, got %q", tt.message, resp["error"].Message)
+			}
+
+			rec = httptest.NewRecorder()
+			tt.middleware(next).ServeHTTP(rec, tt.request())
+
+			if rec.Code != tt.status {
+				t.Errorf("plain: expected status %d, got %d", tt.status, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+				t.Errorf("plain: expected text/plain, got %s", ct)
+			}
+			if rec.Body.String() != tt.message+"\n" {
+				t.Errorf("plain: expected body %q, got %q", tt.message+"\n", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSentinelErrors_UseTypedConstants(t *testing.T) {
+	tests := []struct {
+		err      *APIError
+		wantType ErrorType
+		wantCode ErrorCode
+	}{
+		{ErrBadRequest, ErrorTypeRequest, ErrorCodeBadRequest},
+		{ErrUnauthorized, ErrorTypeAuth, ErrorCodeUnauthorized},
+		{ErrForbidden, ErrorTypeAuth, ErrorCodeForbidden},
+		{ErrNotFound, ErrorTypeNotFound, ErrorCodeNotFound},
+		{ErrPreconditionFailed, ErrorTypeRequest, ErrorCodePreconditionFailed},
+		{ErrUnsupportedMedia, ErrorTypeRequest, ErrorCodeUnsupportedMedia},
+		{ErrUnprocessableEntity, ErrorTypeValidation, ErrorCodeUnprocessable},
+		{ErrRateLimited, ErrorTypeRateLimit, ErrorCodeLimitExceeded},
+		{ErrQuotaExceeded, ErrorTypeRateLimit, ErrorCodeQuotaExceeded},
+		{ErrInternal, ErrorTypeInternal, ErrorCodeInternal},
+		{ErrGatewayTimeout, ErrorTypeTimeout, ErrorCodeGatewayTimeout},
+		{ErrClientClosed, ErrorTypeRequest, ErrorCodeClientClosed},
+		{NewValidationError(nil), ErrorTypeValidation, ErrorCodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.wantCode), func(t *testing.T) {
+			if tt.err.Type != tt.wantType {
+				t.Errorf("expected type %s, got %s", tt.wantType, tt.err.Type)
+			}
+			if tt.err.Code != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, tt.err.Code)
+			}
+		})
+	}
+}
+
+func TestIsErrorType(t *testing.T) {
+	if !IsErrorType(ErrNotFound.With("User not found"), ErrorTypeNotFound) {
+		t.Error("expected ErrNotFound copy to match ErrorTypeNotFound")
+	}
+	if IsErrorType(ErrNotFound, ErrorTypeValidation) {
+		t.Error("expected ErrNotFound not to match ErrorTypeValidation")
+	}
+	if IsErrorType(nil, ErrorTypeInternal) {
+		t.Error("expected nil error not to match")
+	}
+}
+
+func TestDescribeHandler(t *testing.T) {
+	cfg := DescribeHandler(WithCanonlog(), WithSLOs(), WithTimeout(30*time.Second), WithMaxAbandonedHandlers(10))
+
+	expected := HandlerConfig{
+		Timeout:              30 * time.Second,
+		GracefulShutdown:     5 * time.Second,
+		MaxAbandonedHandlers: 10,
+		Canonlog:             true,
+		SLOs:                 true,
+	}
+	if cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, cfg)
+	}
+
+	cfg = DescribeHandler(WithTimeoutFromSLO(3))
+	if cfg.TimeoutSLOMultiplier != 3 || cfg.GracefulShutdown != 5*time.Second {
+		t.Errorf("expected SLO multiplier 3 with default grace period, got %+v", cfg)
+	}
+
+	if cfg := DescribeHandler(); cfg != (HandlerConfig{}) {
+		t.Errorf("expected zero config without options, got %+v", cfg)
+	}
+}
+
+func TestHandler_ErrorEnvelopeEncodingFailurePreservesStatus(t *testing.T) {
+	prev := encodeErrorEnvelope
+	encodeErrorEnvelope = func(io.Writer, *APIError) error { return errors.New("unsupported value") }
+	t.Cleanup(func() { encodeErrorEnvelope = prev })
+
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetError(r, ErrNotFound.With("User not found"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("expected Content-Type text/plain, got %s", ct)
+	}
+	if body := rec.Body.String(); body != "User not found" {
+		t.Errorf("expected body 'User not found', got %s", body)
+	}
+}
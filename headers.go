@@ -114,11 +114,7 @@ func ExtractHeader(header, ctxKey string, opts ...HeaderExtractorOption) func(ht
 				case h.defaultVal != "":
 					val = h.defaultVal
 				case h.required:
-					if HasState(r.Context()) {
-						SetError(r, ErrBadRequest.With("Missing required header: "+h.header))
-					} else {
-						http.Error(w, "Missing required header: "+h.header, http.StatusBadRequest)
-					}
+					respond(w, r, ErrBadRequest.With("Missing required header: "+h.header))
 					return
 				default:
 					next.ServeHTTP(w, r)
@@ -131,11 +127,7 @@ func ExtractHeader(header, ctxKey string, opts ...HeaderExtractorOption) func(ht
 				var err error
 				contextVal, err = h.validator(val)
 				if err != nil {
-					if HasState(r.Context()) {
-						SetError(r, ErrBadRequest.With("Invalid "+h.header+" header: "+err.Error()))
-					} else {
-						http.Error(w, "Invalid "+h.header+" header: "+err.Error(), http.StatusBadRequest)
-					}
+					respond(w, r, ErrBadRequest.With("Invalid "+h.header+" header: "+err.Error()))
 					return
 				}
 			}
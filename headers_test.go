@@ -418,7 +418,7 @@ func TestWrapperIntegration_RequiredHeader(t *testing.T) {
 		name           string
 		headerVal      string
 		wantStatus     int
-		wantErrCode    string
+		wantErrCode    ErrorCode
 		wantErrMessage string
 	}{
 		{
@@ -482,7 +482,7 @@ func TestWrapperIntegration_Validation(t *testing.T) {
 		name           string
 		headerVal      string
 		wantStatus     int
-		wantErrCode    string
+		wantErrCode    ErrorCode
 		wantErrMessage string
 	}{
 		{
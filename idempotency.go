@@ -0,0 +1,160 @@
+package chikit
+
+// Idempotency-Key header validation for unsafe methods.
+// Checks presence and format of the key and stores it in context; replaying
+// responses for repeated keys is left to the application.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type idempotencyContextKey string
+
+const idempotencyKeyKey idempotencyContextKey = "idempotency_key"
+
+// IdempotencyKeyHeader is the header carrying the client-generated idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultIdempotencyKeyMaxLength is the default maximum idempotency key length.
+const DefaultIdempotencyKeyMaxLength = 255
+
+type idempotencyConfig struct {
+	maxLength   int
+	requireUUID bool
+}
+
+// IdempotencyKeyOption configures RequireIdempotencyKey middleware.
+type IdempotencyKeyOption func(*idempotencyConfig)
+
+// IdempotencyKeyWithMaxLength sets the maximum key length.
+// Default is DefaultIdempotencyKeyMaxLength.
+func IdempotencyKeyWithMaxLength(n int) IdempotencyKeyOption {
+	return func(c *idempotencyConfig) {
+		c.maxLength = n
+	}
+}
+
+// IdempotencyKeyWithUUID requires keys to be UUIDs in canonical
+// 8-4-4-4-12 hex form instead of opaque strings.
+func IdempotencyKeyWithUUID() IdempotencyKeyOption {
+	return func(c *idempotencyConfig) {
+		c.requireUUID = true
+	}
+}
+
+// RequireIdempotencyKey returns middleware that requires a well-formed Idempotency-Key
+// header on POST and PATCH requests. Other methods pass through unchanged.
+// The validated key is stored in context (see IdempotencyKeyFromContext).
+//
+// By default a key is any opaque string of printable ASCII characters (no spaces)
+// up to DefaultIdempotencyKeyMaxLength long. Use IdempotencyKeyWithUUID to require UUIDs.
+//
+// Returns 400 (Bad Request) if the header is missing, too long, or malformed.
+//
+// Example:
+//
+//	r.With(chikit.RequireIdempotencyKey()).Post("/payments", func(w http.ResponseWriter, r *http.Request) {
+//		key, _ := chikit.IdempotencyKeyFromContext(r.Context())
+//		if resp, ok := payments.Lookup(key); ok {
+//			chikit.SetResponse(r, http.StatusOK, resp)
+//			return
+//		}
+//		// ...
+//	})
+func RequireIdempotencyKey(opts ...IdempotencyKeyOption) func(http.Handler) http.Handler {
+	cfg := &idempotencyConfig{maxLength: DefaultIdempotencyKeyMaxLength}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost && r.Method != http.MethodPatch {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if err := validateIdempotencyKey(cfg, key); err != nil {
+				respond(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), idempotencyKeyKey, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func validateIdempotencyKey(cfg *idempotencyConfig, key string) *APIError {
+	if key == "" {
+		return &APIError{
+			Type:    ErrorTypeValidation,
+			Code:    ErrorCodeMissingHeader,
+			Message: fmt.Sprintf("Missing required header: %s", IdempotencyKeyHeader),
+			Param:   IdempotencyKeyHeader,
+			Status:  http.StatusBadRequest,
+		}
+	}
+
+	var message string
+	switch {
+	case len(key) > cfg.maxLength:
+		message = fmt.Sprintf("Header %s exceeds maximum length of %d", IdempotencyKeyHeader, cfg.maxLength)
+	case cfg.requireUUID && !isUUID(key):
+		message = fmt.Sprintf("Header %s must be a UUID", IdempotencyKeyHeader)
+	case !isOpaqueToken(key):
+		message = fmt.Sprintf("Header %s contains invalid characters", IdempotencyKeyHeader)
+	default:
+		return nil
+	}
+
+	return &APIError{
+		Type:    ErrorTypeValidation,
+		Code:    ErrorCodeInvalidHeader,
+		Message: message,
+		Param:   IdempotencyKeyHeader,
+		Status:  http.StatusBadRequest,
+	}
+}
+
+// isOpaqueToken reports whether s consists only of printable ASCII without spaces.
+func isOpaqueToken(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether s is a UUID in canonical 8-4-4-4-12 hex form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IdempotencyKeyFromContext retrieves the key validated by RequireIdempotencyKey.
+// Returns the key and true if present, or "" and false if not present
+// (including methods other than POST and PATCH).
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyKey).(string)
+	return key, ok
+}
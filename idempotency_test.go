@@ -0,0 +1,115 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireIdempotencyKey_ValidKey(t *testing.T) {
+	var key string
+	var found bool
+
+	handler := Handler()(RequireIdempotencyKey()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		key, found = IdempotencyKeyFromContext(r.Context())
+		SetResponse(r, http.StatusCreated, nil)
+	})))
+
+	req := httptest.NewRequest("POST", "/payments", http.NoBody)
+	req.Header.Set("Idempotency-Key", "order-1234_retry.1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rec.Code)
+	}
+	if !found || key != "order-1234_retry.1" {
+		t.Errorf("expected key in context, got %q (found=%v)", key, found)
+	}
+}
+
+func TestRequireIdempotencyKey_Rejects(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []IdempotencyKeyOption
+		key          string
+		expectedCode ErrorCode
+	}{
+		{"missing key", nil, "", ErrorCodeMissingHeader},
+		{"over-long key", nil, strings.Repeat("a", 256), ErrorCodeInvalidHeader},
+		{"custom max length", []IdempotencyKeyOption{IdempotencyKeyWithMaxLength(8)}, "123456789", ErrorCodeInvalidHeader},
+		{"invalid characters", nil, "key with spaces", ErrorCodeInvalidHeader},
+		{"not a uuid", []IdempotencyKeyOption{IdempotencyKeyWithUUID()}, "order-1234", ErrorCodeInvalidHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled := false
+			handler := Handler()(RequireIdempotencyKey(tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				handlerCalled = true
+			})))
+
+			req := httptest.NewRequest("POST", "/payments", http.NoBody)
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if handlerCalled {
+				t.Error("handler should not be called")
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+
+			var resp map[string]*APIError
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["error"].Code != tt.expectedCode {
+				t.Errorf("expected code %s, got %s", tt.expectedCode, resp["error"].Code)
+			}
+			if resp["error"].Param != "Idempotency-Key" {
+				t.Errorf("expected param Idempotency-Key, got %s", resp["error"].Param)
+			}
+		})
+	}
+}
+
+func TestRequireIdempotencyKey_UUID(t *testing.T) {
+	handler := RequireIdempotencyKey(IdempotencyKeyWithUUID())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("PATCH", "/payments/1", http.NoBody)
+	req.Header.Set("Idempotency-Key", "3F2504E0-4F89-11D3-9A0C-0305E82C3301")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestRequireIdempotencyKey_SafeMethodsPassThrough(t *testing.T) {
+	var found bool
+	handler := RequireIdempotencyKey()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, found = IdempotencyKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/payments", http.NoBody))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", method, rec.Code)
+		}
+		if found {
+			t.Errorf("%s: expected no key in context", method)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package chikit
+
+// JWT bearer token authentication.
+// Verifies the token and keeps its parsed claims in context, so handlers
+// don't re-parse a token BearerToken has already validated.
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const jwtClaimsKey authContextKey = "jwt_claims"
+
+// jwtConfig configures the JWT middleware.
+type jwtConfig struct {
+	// Optional determines whether the bearer token is required (default: false)
+	// When true, requests without an Authorization header are allowed through
+	Optional bool
+
+	// ParserOptions are passed to jwt.NewParser
+	ParserOptions []jwt.ParserOption
+}
+
+// JWTOption configures JWT middleware.
+type JWTOption func(*jwtConfig)
+
+// WithJWTIssuer requires the iss claim to equal issuer.
+func WithJWTIssuer(issuer string) JWTOption {
+	return func(c *jwtConfig) {
+		c.ParserOptions = append(c.ParserOptions, jwt.WithIssuer(issuer))
+	}
+}
+
+// WithJWTAudience requires the aud claim to contain audience.
+func WithJWTAudience(audience string) JWTOption {
+	return func(c *jwtConfig) {
+		c.ParserOptions = append(c.ParserOptions, jwt.WithAudience(audience))
+	}
+}
+
+// WithJWTValidMethods restricts the accepted signing algorithms (e.g., "RS256").
+// Recommended whenever the keyfunc does not check the token's alg header itself,
+// so a token cannot choose a weaker algorithm than the key is meant for.
+func WithJWTValidMethods(methods ...string) JWTOption {
+	return func(c *jwtConfig) {
+		c.ParserOptions = append(c.ParserOptions, jwt.WithValidMethods(methods))
+	}
+}
+
+// WithJWTLeeway allows for clock skew when checking the exp, nbf, and iat claims.
+func WithJWTLeeway(d time.Duration) JWTOption {
+	return func(c *jwtConfig) {
+		c.ParserOptions = append(c.ParserOptions, jwt.WithLeeway(d))
+	}
+}
+
+// WithOptionalJWT makes the bearer token optional.
+// When set, requests without an Authorization header are allowed through without validation.
+// No claims will be present in the context for these requests. A token that is present
+// is still verified.
+func WithOptionalJWT() JWTOption {
+	return func(c *jwtConfig) {
+		c.Optional = true
+	}
+}
+
+// JWT returns middleware that authenticates JWT bearer tokens from the Authorization
+// header. The token's signature is verified with the key returned by keyfunc, and its
+// exp and nbf claims are checked, along with iss and aud when configured. Returns 401
+// (Unauthorized) with "Token expired" for expired tokens and "Invalid token" for any
+// other verification failure, and the same messages as BearerToken for missing or
+// malformed headers.
+//
+// The parsed claims are stored in the request context and can be retrieved using
+// ClaimsFromContext; the raw token is available from BearerTokenFromContext.
+//
+// Example:
+//
+//	keyfunc := func(*jwt.Token) (any, error) { return signingKey, nil }
+//	r.Use(chikit.JWT(keyfunc,
+//		chikit.WithJWTValidMethods("HS256"),
+//		chikit.WithJWTIssuer("https://auth.example.com"),
+//		chikit.WithJWTAudience("api"),
+//	))
+func JWT(keyfunc jwt.Keyfunc, opts ...JWTOption) func(http.Handler) http.Handler {
+	config := jwtConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	parser := jwt.NewParser(config.ParserOptions...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+
+			if auth == "" {
+				if config.Optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				respond(w, r, ErrUnauthorized.With("Missing authorization header"))
+				return
+			}
+
+			token, ok := authCredentials(auth, "Bearer")
+			if !ok {
+				respond(w, r, ErrUnauthorized.With("Invalid authorization format"))
+				return
+			}
+
+			if token == "" {
+				respond(w, r, ErrUnauthorized.With("Empty bearer token"))
+				return
+			}
+
+			claims := jwt.MapClaims{}
+			if _, err := parser.ParseWithClaims(token, claims, keyfunc); err != nil {
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					respond(w, r, ErrUnauthorized.With("Token expired"))
+				} else {
+					respond(w, r, ErrUnauthorized.With("Invalid token"))
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), bearerTokenKey, token)
+			ctx = context.WithValue(ctx, jwtClaimsKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClaimsFromContext retrieves the claims of the token verified by JWT from the request context.
+// Returns the claims and true if present, or nil and false if not present.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		if claims, ok := chikit.ClaimsFromContext(r.Context()); ok {
+//			sub, _ := claims.GetSubject()
+//			log.Printf("User: %s", sub)
+//		}
+//	}
+func ClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
+	claims, ok := ctx.Value(jwtClaimsKey).(jwt.MapClaims)
+	return claims, ok
+}
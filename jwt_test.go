@@ -0,0 +1,128 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var jwtTestKey = []byte("test-signing-key")
+
+func signTestJWT(t *testing.T, method jwt.SigningMethod, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(jwtTestKey)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func jwtTestKeyfunc(*jwt.Token) (any, error) {
+	return jwtTestKey, nil
+}
+
+func TestJWT(t *testing.T) {
+	now := time.Now()
+	valid := jwt.MapClaims{"sub": "user-1", "iss": "auth", "aud": "api", "exp": now.Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name        string
+		auth        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{"valid", "Bearer " + signTestJWT(t, jwt.SigningMethodHS256, valid), http.StatusOK, ""},
+		{"expired", "Bearer " + signTestJWT(t, jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "iss": "auth", "aud": "api", "exp": now.Add(-time.Hour).Unix()}), http.StatusUnauthorized, "Token expired"},
+		{"not yet valid", "Bearer " + signTestJWT(t, jwt.SigningMethodHS256, jwt.MapClaims{"iss": "auth", "aud": "api", "nbf": now.Add(time.Hour).Unix()}), http.StatusUnauthorized, "Invalid token"},
+		{"wrong issuer", "Bearer " + signTestJWT(t, jwt.SigningMethodHS256, jwt.MapClaims{"iss": "other", "aud": "api"}), http.StatusUnauthorized, "Invalid token"},
+		{"wrong audience", "Bearer " + signTestJWT(t, jwt.SigningMethodHS256, jwt.MapClaims{"iss": "auth", "aud": "web"}), http.StatusUnauthorized, "Invalid token"},
+		{"disallowed algorithm", "Bearer " + signTestJWT(t, jwt.SigningMethodHS512, valid), http.StatusUnauthorized, "Invalid token"},
+		{"bad signature", "Bearer " + signTestJWT(t, jwt.SigningMethodHS256, valid) + "x", http.StatusUnauthorized, "Invalid token"},
+		{"garbage", "Bearer not-a-jwt", http.StatusUnauthorized, "Invalid token"},
+		{"missing", "", http.StatusUnauthorized, "Missing authorization header"},
+		{"wrong scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "Invalid authorization format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject string
+			h := Handler()(JWT(jwtTestKeyfunc,
+				WithJWTValidMethods("HS256"),
+				WithJWTIssuer("auth"),
+				WithJWTAudience("api"),
+			)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				claims, ok := ClaimsFromContext(r.Context())
+				if !ok {
+					t.Error("claims not found in context")
+				}
+				subject, _ = claims.GetSubject()
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if subject != "user-1" {
+					t.Errorf("expected subject user-1, got %q", subject)
+				}
+				return
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Error.Code != ErrorCodeUnauthorized || resp.Error.Message != tt.wantMessage {
+				t.Errorf("expected unauthorized %q, got %s %q", tt.wantMessage, resp.Error.Code, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestJWT_Optional(t *testing.T) {
+	var hasClaims bool
+	h := JWT(jwtTestKeyfunc, WithOptionalJWT())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasClaims = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK || hasClaims {
+		t.Errorf("expected anonymous request through without claims, got %d (claims=%v)", rec.Code, hasClaims)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected present invalid token to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestJWT_TokenInContext(t *testing.T) {
+	token := signTestJWT(t, jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1"})
+	var got string
+	h := JWT(jwtTestKeyfunc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = BearerTokenFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got != token {
+		t.Errorf("expected raw token in context, got %q", got)
+	}
+}
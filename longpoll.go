@@ -0,0 +1,58 @@
+package chikit
+
+// Long-polling helper for endpoints that wait for an event before responding.
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// LongPoll runs fn with a context that expires after wait and sets the response
+// from its result:
+//   - Data (non-nil) responds 200 with the data
+//   - No data before the deadline responds 204 so the client polls again; fn may
+//     either return (nil, nil) or return the context's error
+//   - Any other error responds with the *APIError in its chain, or 500 otherwise
+//
+// If the client disconnects (or the request context ends for another reason, such
+// as WithTimeout), no response is set. Requires the Handler wrapper.
+//
+// Example:
+//
+//	r.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+//		chikit.LongPoll(r, 30*time.Second, func(ctx context.Context) (any, error) {
+//			select {
+//			case ev := <-broker.Subscribe(ctx):
+//				return ev, nil
+//			case <-ctx.Done():
+//				return nil, ctx.Err()
+//			}
+//		})
+//	})
+func LongPoll(r *http.Request, wait time.Duration, fn func(ctx context.Context) (any, error)) {
+	ctx, cancel := context.WithTimeout(r.Context(), wait)
+	defer cancel()
+
+	data, err := fn(ctx)
+	if r.Context().Err() != nil {
+		return
+	}
+
+	switch {
+	case err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil:
+		SetResponse(r, http.StatusNoContent, nil)
+	case err != nil:
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			SetError(r, apiErr)
+		} else {
+			SetError(r, ErrInternal)
+		}
+	case data == nil:
+		SetResponse(r, http.StatusNoContent, nil)
+	default:
+		SetResponse(r, http.StatusOK, data)
+	}
+}
@@ -0,0 +1,91 @@
+package chikit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLongPoll(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(ctx context.Context) (any, error)
+		expected int
+	}{
+		{
+			name: "data before timeout",
+			fn: func(context.Context) (any, error) {
+				return map[string]string{"event": "created"}, nil
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "no data returns context error",
+			fn: func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			expected: http.StatusNoContent,
+		},
+		{
+			name: "no data returns nil",
+			fn: func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, nil
+			},
+			expected: http.StatusNoContent,
+		},
+		{
+			name: "api error",
+			fn: func(context.Context) (any, error) {
+				return nil, fmt.Errorf("lookup: %w", ErrNotFound.With("Channel not found"))
+			},
+			expected: http.StatusNotFound,
+		},
+		{
+			name: "other error",
+			fn: func(context.Context) (any, error) {
+				return nil, errors.New("broker unavailable")
+			},
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				LongPoll(r, 20*time.Millisecond, tt.fn)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/events", http.NoBody))
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestLongPoll_ClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		LongPoll(r, time.Minute, func(ctx context.Context) (any, error) {
+			cancel()
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	}))
+
+	req := httptest.NewRequest("GET", "/events", http.NoBody).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusNoContent {
+		t.Error("expected no 204 after client disconnect")
+	}
+}
@@ -0,0 +1,84 @@
+package chikit
+
+// Upload limits for multipart/form-data requests.
+// Parses the form before the handler runs and rejects abusive uploads,
+// leaving the parsed form on the request for the handler to reuse.
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+)
+
+// multipartMaxMemory is the portion of a multipart form held in memory;
+// larger files are spooled to temporary files. Matches net/http's default.
+const multipartMaxMemory = 32 << 20
+
+// MultipartLimits returns middleware that parses multipart/form-data bodies with
+// upload bounds before the handler runs. A limit <= 0 disables that check.
+//
+// Returns 413 (Payload Too Large) if:
+//   - Any single file exceeds maxFileSize bytes
+//   - The form contains more than maxFiles files
+//   - The body exceeds maxTotal bytes
+//
+// Returns 400 (Bad Request) if the form is malformed. Non-multipart requests pass
+// through unchanged. The parsed form is set on the request (r.MultipartForm), so
+// r.FormFile and r.FormValue in the handler reuse it without reparsing, and its
+// temporary files are removed after the handler returns.
+//
+// Example:
+//
+//	r.With(chikit.MultipartLimits(10<<20, 5, 25<<20)).Post("/uploads", handleUpload)
+func MultipartLimits(maxFileSize int64, maxFiles int, maxTotal int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "multipart/form-data" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if maxTotal > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, maxTotal)
+			}
+
+			if err := r.ParseMultipartForm(multipartMaxMemory); err != nil {
+				if r.MultipartForm != nil {
+					r.MultipartForm.RemoveAll()
+				}
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					respond(w, r, ErrPayloadTooLarge.With("Request body too large"))
+				} else {
+					respond(w, r, ErrBadRequest.With("Invalid multipart form"))
+				}
+				return
+			}
+			defer r.MultipartForm.RemoveAll()
+
+			if apiErr := checkMultipartFiles(r, maxFileSize, maxFiles); apiErr != nil {
+				respond(w, r, apiErr)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func checkMultipartFiles(r *http.Request, maxFileSize int64, maxFiles int) *APIError {
+	count := 0
+	for _, files := range r.MultipartForm.File {
+		for _, fh := range files {
+			count++
+			if maxFileSize > 0 && fh.Size > maxFileSize {
+				return ErrPayloadTooLarge.With("File " + fh.Filename + " too large")
+			}
+		}
+	}
+	if maxFiles > 0 && count > maxFiles {
+		return ErrPayloadTooLarge.With("Too many files")
+	}
+	return nil
+}
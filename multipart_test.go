@@ -0,0 +1,81 @@
+package chikit
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newMultipartRequest(t *testing.T, fileSizes ...int) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("title", "report"); err != nil {
+		t.Fatal(err)
+	}
+	for i, size := range fileSizes {
+		fw, err := mw.CreateFormFile("file", "upload"+string(rune('a'+i))+".bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(bytes.Repeat([]byte("x"), size))
+	}
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/uploads", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestMultipartLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileSizes []int
+		expected  int
+	}{
+		{"within limits", []int{100, 200}, http.StatusOK},
+		{"oversized single file", []int{100, 2000}, http.StatusRequestEntityTooLarge},
+		{"too many files", []int{10, 10, 10, 10}, http.StatusRequestEntityTooLarge},
+		{"total size exceeded", []int{900, 900, 900}, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files int
+			var title string
+			handler := Handler()(MultipartLimits(1000, 3, 2000)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				files = len(r.MultipartForm.File["file"])
+				title = r.FormValue("title")
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, newMultipartRequest(t, tt.fileSizes...))
+
+			if rec.Code != tt.expected {
+				t.Fatalf("expected status %d, got %d: %s", tt.expected, rec.Code, rec.Body.String())
+			}
+			if tt.expected == http.StatusOK && (files != len(tt.fileSizes) || title != "report") {
+				t.Errorf("expected parsed form in handler, got %d files and title %q", files, title)
+			}
+		})
+	}
+}
+
+func TestMultipartLimits_NonMultipart(t *testing.T) {
+	handler := MultipartLimits(10, 1, 10)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/uploads", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package chikit
+
+// Partial success responses for bulk endpoints.
+// Reports each item's outcome in a 207 (Multi-Status) body, so a bulk request
+// that partly fails does not have to be reported as a single success or error.
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// StatusResult is the outcome of one item in a bulk operation.
+// Set Body for an item that succeeded, or Error for one that failed; Error is
+// written in the same shape as the {"error": ...} envelope.
+type StatusResult struct {
+	// ID identifies the item, e.g. its index or client-supplied reference.
+	ID string `json:"id" xml:"id"`
+
+	// Status is the item's HTTP status. Defaults to Error.Status for failed
+	// items and 200 for the rest.
+	Status int `json:"status" xml:"status"`
+
+	// Body is the item's result, omitted when nil.
+	Body any `json:"body,omitempty" xml:"body,omitempty"`
+
+	// Error is the item's error, omitted when nil.
+	Error *APIError `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// multiStatusResponse is the body written by SetMultiStatus.
+type multiStatusResponse struct {
+	XMLName xml.Name       `json:"-" xml:"multistatus"`
+	Results []StatusResult `json:"results" xml:"result"`
+}
+
+// SetMultiStatus sets a 207 (Multi-Status) response for a bulk operation whose
+// items succeeded or failed independently. The body is written as
+// {"results": [...]} with one entry per item, in order. WithEnvelope does not
+// wrap it, as the results already separate data from errors.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	results := make([]chikit.StatusResult, len(users))
+//	for i, u := range users {
+//		created, err := db.CreateUser(ctx, u)
+//		if err != nil {
+//			results[i] = chikit.StatusResult{ID: u.Email, Error: chikit.ErrConflict.With("Email already registered")}
+//			continue
+//		}
+//		results[i] = chikit.StatusResult{ID: u.Email, Status: http.StatusCreated, Body: created}
+//	}
+//	chikit.SetMultiStatus(r, results)
+func SetMultiStatus(r *http.Request, results []StatusResult) {
+	normalized := make([]StatusResult, len(results))
+	for i, result := range results {
+		if result.Status == 0 {
+			result.Status = http.StatusOK
+			if result.Error != nil {
+				result.Status = result.Error.Status
+			}
+		}
+		normalized[i] = result
+	}
+	SetResponse(r, http.StatusMultiStatus, multiStatusResponse{Results: normalized})
+}
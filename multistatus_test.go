@@ -0,0 +1,88 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type multiStatusResult struct {
+	ID     string            `json:"id"`
+	Status int               `json:"status"`
+	Body   map[string]string `json:"body"`
+	Error  *APIError         `json:"error"`
+}
+
+func TestSetMultiStatus(t *testing.T) {
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetMultiStatus(r, []StatusResult{
+			{ID: "a", Status: http.StatusCreated, Body: map[string]string{"name": "Alice"}},
+			{ID: "b", Error: ErrConflict.With("Email already registered")},
+			{ID: "c", Body: map[string]string{"name": "Carol"}},
+		})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users/bulk", http.NoBody))
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("expected status %d, got %d", http.StatusMultiStatus, rec.Code)
+	}
+
+	var body struct {
+		Results []multiStatusResult `json:"results"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(body.Results))
+	}
+
+	tests := []struct {
+		name     string
+		want     multiStatusResult
+		errCode  ErrorCode
+		errorMsg string
+	}{
+		{name: "success", want: multiStatusResult{ID: "a", Status: http.StatusCreated, Body: map[string]string{"name": "Alice"}}},
+		{name: "error", want: multiStatusResult{ID: "b", Status: http.StatusConflict}, errCode: ErrorCodeConflict, errorMsg: "Email already registered"},
+		{name: "default status", want: multiStatusResult{ID: "c", Status: http.StatusOK, Body: map[string]string{"name": "Carol"}}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := body.Results[i]
+			if got.ID != tt.want.ID || got.Status != tt.want.Status || got.Body["name"] != tt.want.Body["name"] {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+			if tt.errCode == "" {
+				if got.Error != nil {
+					t.Errorf("expected no error, got %+v", got.Error)
+				}
+				return
+			}
+			if got.Body != nil {
+				t.Errorf("expected no body, got %+v", got.Body)
+			}
+			if got.Error == nil || got.Error.Type != ErrorTypeRequest || got.Error.Code != tt.errCode || got.Error.Message != tt.errorMsg {
+				t.Errorf("expected %s error envelope, got %+v", tt.errCode, got.Error)
+			}
+		})
+	}
+}
+
+func TestSetMultiStatus_NotEnveloped(t *testing.T) {
+	handler := Handler(WithEnvelope())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetMultiStatus(r, []StatusResult{{ID: "a"}})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", http.NoBody))
+
+	if body := rec.Body.String(); !strings.HasPrefix(body, `{"results":`) {
+		t.Errorf("expected results without data envelope, got %s", body)
+	}
+}
@@ -0,0 +1,73 @@
+package chikit
+
+// XML responses for WithContentNegotiation, negotiated from the Accept header.
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+)
+
+// xmlError is the XML form of an APIError, rooted at <error> with the same
+// snake_case names as the JSON envelope. ErrorsByCode is a map, which encoding/xml
+// cannot marshal, so it is written as a list of groups.
+type xmlError struct {
+	XMLName         xml.Name       `xml:"error"`
+	Type            ErrorType      `xml:"type"`
+	Code            ErrorCode      `xml:"code,omitempty"`
+	Message         string         `xml:"message"`
+	Param           string         `xml:"param,omitempty"`
+	Errors          []FieldError   `xml:"errors>field,omitempty"`
+	ErrorsByCode    []xmlCodeGroup `xml:"errors_by_code>group,omitempty"`
+	DocsURL         string         `xml:"docs_url,omitempty"`
+	SuggestedAction string         `xml:"suggested_action,omitempty"`
+}
+
+// xmlCodeGroup lists the field params that failed with one validation code.
+type xmlCodeGroup struct {
+	Code   string   `xml:"code,attr"`
+	Params []string `xml:"param"`
+}
+
+// prefersXML reports whether the Accept header ranks application/xml (or text/xml)
+// above application/json. Ties (including */*) and a missing header go to JSON.
+func prefersXML(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	xmlQuality := max(acceptQuality(accept, "application/xml"), acceptQuality(accept, "text/xml"))
+	return xmlQuality > acceptQuality(accept, "application/json")
+}
+
+// encodeXML encodes v as an XML document. Returns an error for values encoding/xml
+// cannot represent, such as maps.
+func encodeXML(v any) ([]byte, error) {
+	buf := bytes.NewBufferString(xml.Header)
+	if err := xml.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// encodeXMLError encodes apiErr as an XML <error> document.
+func encodeXMLError(apiErr *APIError) ([]byte, error) {
+	doc := xmlError{
+		Type:            apiErr.Type,
+		Code:            apiErr.Code,
+		Message:         apiErr.Message,
+		Param:           apiErr.Param,
+		Errors:          apiErr.Errors,
+		DocsURL:         apiErr.DocsURL,
+		SuggestedAction: apiErr.SuggestedAction,
+	}
+	codes := make([]string, 0, len(apiErr.ErrorsByCode))
+	for code := range apiErr.ErrorsByCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		doc.ErrorsByCode = append(doc.ErrorsByCode, xmlCodeGroup{Code: code, Params: apiErr.ErrorsByCode[code]})
+	}
+	return encodeXML(doc)
+}
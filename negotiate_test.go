@@ -0,0 +1,169 @@
+package chikit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type negotiateUser struct {
+	XMLName xml.Name `json:"-" xml:"user"`
+	ID      string   `json:"id" xml:"id"`
+	Name    string   `json:"name" xml:"name"`
+}
+
+func serveNegotiated(t *testing.T, accept string, fn func(r *http.Request)) *httptest.ResponseRecorder {
+	t.Helper()
+	h := Handler(WithContentNegotiation())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		fn(r)
+	}))
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWithContentNegotiation_Success(t *testing.T) {
+	want := negotiateUser{ID: "u_1", Name: "Ada & Co"}
+	setUser := func(r *http.Request) { SetResponse(r, http.StatusOK, want) }
+
+	rec := serveNegotiated(t, "application/xml", setUser)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("expected XML Content-Type, got %q", ct)
+	}
+	if vary := rec.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("expected Vary: Accept, got %q", vary)
+	}
+	var got negotiateUser
+	if err := xml.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("body is not valid XML: %v\n%s", err, rec.Body.String())
+	}
+	if got.ID != want.ID || got.Name != want.Name {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	for _, accept := range []string{"", "*/*", "application/json", "application/xml;q=0.5, application/json"} {
+		rec := serveNegotiated(t, accept, setUser)
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Accept %q: expected JSON Content-Type, got %q", accept, ct)
+		}
+		var got negotiateUser
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.ID != want.ID {
+			t.Errorf("Accept %q: expected JSON body, got %s", accept, rec.Body.String())
+		}
+	}
+}
+
+func TestWithContentNegotiation_Error(t *testing.T) {
+	apiErr := NewValidationError([]FieldError{
+		{Param: "email", Code: "required", Message: "email is required"},
+		{Param: "age", Code: "min", Message: "age must be at least 18"},
+	}).WithDocs("https://docs.example.com/errors")
+	apiErr.ErrorsByCode = map[string][]string{"required": {"email"}, "min": {"age"}}
+
+	rec := serveNegotiated(t, "text/html;q=0.5, application/xml", func(r *http.Request) { SetError(r, apiErr) })
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("expected XML Content-Type, got %q", ct)
+	}
+
+	var got xmlError
+	if err := xml.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("body is not valid XML: %v\n%s", err, rec.Body.String())
+	}
+	want := xmlError{
+		XMLName: xml.Name{Local: "error"},
+		Type:    ErrorTypeValidation,
+		Code:    ErrorCodeInvalidRequest,
+		Message: "Validation failed",
+		Errors:  apiErr.Errors,
+		ErrorsByCode: []xmlCodeGroup{
+			{Code: "min", Params: []string{"age"}},
+			{Code: "required", Params: []string{"email"}},
+		},
+		DocsURL: "https://docs.example.com/errors",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	rec = serveNegotiated(t, "", func(r *http.Request) { SetError(r, ErrNotFound) })
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON error without Accept, got %q", ct)
+	}
+}
+
+func TestWithContentNegotiation_Fallbacks(t *testing.T) {
+	rec := serveNegotiated(t, "application/xml", func(r *http.Request) {
+		SetResponse(r, http.StatusOK, map[string]string{"id": "u_1"})
+	})
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected map body to fall back to JSON, got %q", ct)
+	}
+	if body := rec.Body.String(); body != "{\"id\":\"u_1\"}\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	rec = serveNegotiated(t, "application/xml", func(r *http.Request) {
+		SetResponse(r, http.StatusOK, json.RawMessage(`{"id":"u_1"}`))
+	})
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected pre-encoded JSON to be written as-is, got %q", ct)
+	}
+
+	rec = serveNegotiated(t, "application/xml", func(r *http.Request) {
+		SetResponse(r, http.StatusNoContent, nil)
+	})
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Errorf("expected empty 204, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWithContentNegotiation_Disabled(t *testing.T) {
+	h := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, negotiateUser{ID: "u_1"})
+	}))
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Accept", "application/xml")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON without WithContentNegotiation, got %q", ct)
+	}
+	if vary := rec.Header().Get("Vary"); vary != "" {
+		t.Errorf("expected no Vary header, got %q", vary)
+	}
+}
+
+func TestPrefersXML(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/xml", true},
+		{"text/xml", true},
+		{"application/json, application/xml", false},
+		{"application/json;q=0.5, application/xml", true},
+		{"application/xml;q=0.1, */*", false},
+	}
+	for _, tt := range tests {
+		if got := prefersXML(tt.accept); got != tt.want {
+			t.Errorf("prefersXML(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
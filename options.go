@@ -0,0 +1,81 @@
+package chikit
+
+// Automatic OPTIONS responses.
+// Answers OPTIONS requests from the methods registered on the chi router, so
+// routes are discoverable without writing an OPTIONS handler for each one.
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// optionsMethods are the methods AutoOptions checks, in the order they are listed
+// in the Allow header.
+var optionsMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
+// AutoOptions returns middleware that answers OPTIONS requests with 204 (No Content)
+// and an Allow header listing the methods router has registered for the request
+// path (e.g., "Allow: GET, POST"), without calling the route's handlers. Register it
+// on router itself with Use, so it sees the path routed by that router.
+//
+// Requests pass through unchanged when they are CORS preflights (they carry Origin
+// and Access-Control-Request-Method headers) so the CORS middleware answers them,
+// when router has an explicit OPTIONS handler for the path, or when no route
+// matches the path.
+//
+// Example:
+//
+//	r := chi.NewRouter()
+//	r.Use(cors.Handler(corsOptions))
+//	r.Use(chikit.AutoOptions(r))
+//	r.Get("/users", listUsers)
+//	r.Post("/users", createUser)
+//	// OPTIONS /users -> 204, Allow: GET, POST
+func AutoOptions(router chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodOptions ||
+				(r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != "") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			path := routingPath(r, chi.RouteContext(r.Context()))
+			if router.Match(chi.NewRouteContext(), http.MethodOptions, path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var allowed []string
+			for _, method := range optionsMethods {
+				if router.Match(chi.NewRouteContext(), method, path) {
+					allowed = append(allowed, method)
+				}
+			}
+			if len(allowed) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allow := strings.Join(allowed, ", ")
+			if HasState(r.Context()) {
+				SetHeader(r, "Allow", allow)
+				SetResponse(r, http.StatusNoContent, nil)
+				return
+			}
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newOptionsRouter(handlerCalled *bool) chi.Router {
+	r := chi.NewRouter()
+	r.Use(Handler())
+	r.Use(AutoOptions(r))
+	called := func(_ http.ResponseWriter, r *http.Request) {
+		*handlerCalled = true
+		SetResponse(r, http.StatusOK, nil)
+	}
+	r.Get("/users", called)
+	r.Post("/users", called)
+	r.Delete("/users/{id}", called)
+	r.Get("/custom", called)
+	r.Options("/custom", called)
+	r.Route("/api", func(r chi.Router) {
+		r.Put("/items/{id}", called)
+		r.Patch("/items/{id}", called)
+	})
+	return r
+}
+
+func TestAutoOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		wantAllow string
+	}{
+		{"top-level route", "/users", "GET, POST"},
+		{"route with params", "/users/42", "DELETE"},
+		{"subrouter", "/api/items/7", "PUT, PATCH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handlerCalled bool
+			rec := httptest.NewRecorder()
+			newOptionsRouter(&handlerCalled).ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, tt.path, http.NoBody))
+
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("expected 204, got %d", rec.Code)
+			}
+			if allow := rec.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("expected Allow %q, got %q", tt.wantAllow, allow)
+			}
+			if handlerCalled {
+				t.Error("handler should not be called")
+			}
+		})
+	}
+}
+
+func TestAutoOptions_PassThrough(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantCalled bool
+	}{
+		{"other method", http.MethodGet, "/users", nil, http.StatusOK, true},
+		{"explicit OPTIONS handler", http.MethodOptions, "/custom", nil, http.StatusOK, true},
+		{"CORS preflight", http.MethodOptions, "/users", map[string]string{
+			"Origin":                        "https://app.example.com",
+			"Access-Control-Request-Method": "POST",
+		}, http.StatusMethodNotAllowed, false},
+		{"unknown path", http.MethodOptions, "/missing", nil, http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handlerCalled bool
+			req := httptest.NewRequest(tt.method, tt.path, http.NoBody)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			newOptionsRouter(&handlerCalled).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if handlerCalled != tt.wantCalled {
+				t.Errorf("expected handler called=%v, got %v", tt.wantCalled, handlerCalled)
+			}
+		})
+	}
+}
+
+func TestAutoOptions_WithoutWrapper(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(AutoOptions(r))
+	r.Get("/users", func(http.ResponseWriter, *http.Request) {})
+	r.Post("/users", func(http.ResponseWriter, *http.Request) {})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", http.NoBody))
+
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Allow") != "GET, POST" {
+		t.Errorf("expected 204 with Allow: GET, POST, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
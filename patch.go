@@ -0,0 +1,127 @@
+package chikit
+
+// JSON Merge Patch (RFC 7396) support for PATCH endpoints.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MergePatch applies the request body as an RFC 7396 JSON Merge Patch to current
+// and returns the merged, validated result. current is not modified.
+//
+// Patch semantics:
+//   - A field set to null removes it (the result field is its zero value)
+//   - An absent field is preserved from current
+//   - A provided field overwrites current; nested objects are merged recursively
+//   - A non-object patch replaces the whole resource
+//
+// The merged result is validated with struct tags like JSON. Unlike JSON, MergePatch
+// does not set the error on the request; the caller decides how to respond:
+//
+//	r.Patch("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+//		user := loadUser(chi.URLParam(r, "id"))
+//		merged, apiErr := chikit.MergePatch(r, user)
+//		if apiErr != nil {
+//			chikit.SetError(r, apiErr)
+//			return
+//		}
+//		saveUser(merged)
+//		chikit.SetResponse(r, http.StatusOK, merged)
+//	})
+//
+// Returns ErrBadRequest for malformed JSON, ErrPayloadTooLarge if MaxBodySize is
+// exceeded, ErrInternal if current cannot be encoded, and a validation_error if the
+// merged result fails validation.
+func MergePatch[T any](r *http.Request, current T) (T, *APIError) {
+	var zero T
+
+	patchBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return zero, ErrPayloadTooLarge.With("Request body too large")
+		}
+		return zero, ErrBadRequest.With("Failed to read request body")
+	}
+
+	patch, err := decodeJSONValue(patchBytes)
+	if err != nil {
+		return zero, ErrBadRequest.With("Invalid JSON merge patch")
+	}
+
+	currentBytes, err := json.Marshal(current)
+	if err != nil {
+		return zero, ErrInternal.With("Failed to encode current resource")
+	}
+	target, err := decodeJSONValue(currentBytes)
+	if err != nil {
+		return zero, ErrInternal.With("Failed to encode current resource")
+	}
+
+	mergedBytes, err := json.Marshal(applyMergePatch(target, patch))
+	if err != nil {
+		return zero, ErrInternal.With("Failed to encode merged resource")
+	}
+
+	var result T
+	if err := json.Unmarshal(mergedBytes, &result); err != nil {
+		return zero, ErrBadRequest.With("Merge patch produces an invalid resource")
+	}
+
+	validateMu.RLock()
+	err = validate.Struct(result)
+	validateMu.RUnlock()
+
+	if err != nil {
+		var invalid *validator.InvalidValidationError
+		if errors.As(err, &invalid) {
+			// Non-struct resources have no struct tags to validate
+			return result, nil
+		}
+		cfg := getBindConfig(r.Context())
+		return zero, newBindValidationError(cfg, translateErrors(err, cfg))
+	}
+
+	return result, nil
+}
+
+// decodeJSONValue decodes a JSON document into generic values, keeping numbers
+// as json.Number so large integers survive the round trip.
+func decodeJSONValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// applyMergePatch implements the MergePatch algorithm from RFC 7396 section 2.
+func applyMergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = applyMergePatch(targetObj[name], value)
+	}
+	return targetObj
+}
@@ -0,0 +1,92 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type patchAddress struct {
+	City string `json:"city,omitempty"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type patchUser struct {
+	Name     string        `json:"name" validate:"required"`
+	Nickname *string       `json:"nickname,omitempty"`
+	Age      int           `json:"age,omitempty" validate:"omitempty,min=18"`
+	Address  *patchAddress `json:"address,omitempty"`
+}
+
+func newPatchUser() patchUser {
+	nick := "al"
+	return patchUser{
+		Name:     "Alice",
+		Nickname: &nick,
+		Age:      30,
+		Address:  &patchAddress{City: "Berlin", Zip: "10115"},
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	current := newPatchUser()
+
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"nickname": null, "age": 31, "address": {"zip": null}}`))
+	merged, apiErr := MergePatch(req, current)
+	if apiErr != nil {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+
+	if merged.Nickname != nil {
+		t.Errorf("expected null to remove nickname, got %q", *merged.Nickname)
+	}
+	if merged.Name != "Alice" {
+		t.Errorf("expected absent name to be preserved, got %q", merged.Name)
+	}
+	if merged.Age != 31 {
+		t.Errorf("expected provided age to overwrite, got %d", merged.Age)
+	}
+	if merged.Address == nil || merged.Address.City != "Berlin" || merged.Address.Zip != "" {
+		t.Errorf("expected nested merge to keep city and remove zip, got %+v", merged.Address)
+	}
+
+	if current.Nickname == nil || current.Age != 30 || current.Address.Zip != "10115" {
+		t.Errorf("expected current to be unmodified, got %+v", current)
+	}
+}
+
+func TestMergePatch_ValidationFailure(t *testing.T) {
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"name": null, "age": 10}`))
+	_, apiErr := MergePatch(req, newPatchUser())
+	if apiErr == nil {
+		t.Fatal("expected validation error")
+	}
+	if apiErr.Type != "validation_error" {
+		t.Errorf("expected validation_error, got %s", apiErr.Type)
+	}
+
+	params := make(map[string]string)
+	for _, fe := range apiErr.Errors {
+		params[fe.Param] = fe.Code
+	}
+	if params["name"] != "required" || params["age"] != "min" {
+		t.Errorf("expected required on name and min on age, got %v", apiErr.Errors)
+	}
+}
+
+func TestMergePatch_MalformedJSON(t *testing.T) {
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"name":`))
+	_, apiErr := MergePatch(req, newPatchUser())
+	if apiErr == nil || apiErr.Status != http.StatusBadRequest {
+		t.Errorf("expected 400 error, got %v", apiErr)
+	}
+}
+
+func TestMergePatch_TypeMismatch(t *testing.T) {
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"age": "thirty"}`))
+	_, apiErr := MergePatch(req, newPatchUser())
+	if apiErr == nil || apiErr.Status != http.StatusBadRequest {
+		t.Errorf("expected 400 error, got %v", apiErr)
+	}
+}
@@ -0,0 +1,121 @@
+package chikit
+
+// Conditional request evaluation (RFC 9110 section 13, formerly RFC 7232).
+// Lets handlers answer revalidation with 304 and reject stale updates with 412
+// before doing any work.
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CheckPreconditions evaluates the request's If-Match, If-Unmodified-Since,
+// If-None-Match, and If-Modified-Since headers against the current state of the
+// resource, in the order RFC 9110 specifies. etag is the resource's current entity
+// tag including quotes (e.g., `"v42"` or `W/"v42"`), or "" if it has none; lastMod is
+// its last modification time, or the zero time if unknown.
+//
+// Returns (0, true) if the handler should proceed. Otherwise returns the status to
+// short-circuit with and false:
+//   - 304 (Not Modified) for GET and HEAD when the client's cached copy is current
+//   - 412 (Precondition Failed) when If-Match or If-Unmodified-Since fails, or when
+//     If-None-Match matches on other methods
+//
+// When the Handler wrapper is active, the short-circuit response is also set: a 304
+// carrying the ETag and Last-Modified headers, or ErrPreconditionFailed.
+//
+// Example (optimistic concurrency):
+//
+//	r.Put("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+//		user := loadUser(r)
+//		if _, ok := chikit.CheckPreconditions(r, user.ETag(), user.UpdatedAt); !ok {
+//			return
+//		}
+//		// apply the update
+//	})
+func CheckPreconditions(r *http.Request, etag string, lastMod time.Time) (int, bool) {
+	status := evaluatePreconditions(r, etag, lastMod)
+	if status == 0 {
+		return 0, true
+	}
+
+	if HasState(r.Context()) {
+		if status == http.StatusPreconditionFailed {
+			SetError(r, ErrPreconditionFailed)
+		} else {
+			if etag != "" {
+				SetHeader(r, "ETag", etag)
+			}
+			if !lastMod.IsZero() {
+				SetHeader(r, "Last-Modified", lastMod.UTC().Format(http.TimeFormat))
+			}
+			SetResponse(r, http.StatusNotModified, nil)
+		}
+	}
+	return status, false
+}
+
+// evaluatePreconditions returns the short-circuit status for r, or 0 to proceed.
+func evaluatePreconditions(r *http.Request, etag string, lastMod time.Time) int {
+	lastMod = lastMod.Truncate(time.Second)
+	safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	// Steps 1-2: If-Match takes precedence over If-Unmodified-Since.
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !etagStrongMatches(ifMatch, etag) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseHTTPDate(r.Header.Get("If-Unmodified-Since")); ok && !lastMod.IsZero() {
+		if lastMod.After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	// Steps 3-4: If-None-Match takes precedence over If-Modified-Since.
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etag != "" && etagMatches(ifNoneMatch, etag) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseHTTPDate(r.Header.Get("If-Modified-Since")); ok && safe && !lastMod.IsZero() {
+		if !lastMod.After(since) {
+			return http.StatusNotModified
+		}
+	}
+
+	return 0
+}
+
+// etagStrongMatches reports whether an If-Match header matches etag, using the
+// strong comparison RFC 9110 requires for If-Match: weak tags never match, and "*"
+// matches any current representation, including one with a weak or no ETag.
+func etagStrongMatches(ifMatch, etag string) bool {
+	if strings.TrimSpace(ifMatch) == "*" {
+		return true
+	}
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHTTPDate parses an HTTP-date header value. Returns false if the value is
+// empty or invalid, in which case the header is ignored per RFC 9110.
+func parseHTTPDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
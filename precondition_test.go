@@ -0,0 +1,129 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckPreconditions(t *testing.T) {
+	etag := `"v2"`
+	lastMod := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	before := lastMod.Add(-time.Hour).Format(http.TimeFormat)
+	after := lastMod.Add(time.Hour).Format(http.TimeFormat)
+
+	tests := []struct {
+		name           string
+		method         string
+		headers        map[string]string
+		expectedStatus int
+		expectedOK     bool
+	}{
+		{"no preconditions", http.MethodGet, nil, 0, true},
+		{"If-Match matches", http.MethodPut, map[string]string{"If-Match": `"v1", "v2"`}, 0, true},
+		{"If-Match stale", http.MethodPut, map[string]string{"If-Match": `"v1"`}, http.StatusPreconditionFailed, false},
+		{"If-Match weak never matches", http.MethodPut, map[string]string{"If-Match": `W/"v2"`}, http.StatusPreconditionFailed, false},
+		{"If-Match wildcard", http.MethodPut, map[string]string{"If-Match": "*"}, 0, true},
+		{"If-Unmodified-Since passes", http.MethodPut, map[string]string{"If-Unmodified-Since": after}, 0, true},
+		{"If-Unmodified-Since fails", http.MethodPut, map[string]string{"If-Unmodified-Since": before}, http.StatusPreconditionFailed, false},
+		{"If-Match overrides If-Unmodified-Since", http.MethodPut, map[string]string{"If-Match": etag, "If-Unmodified-Since": before}, 0, true},
+		{"If-None-Match matches GET", http.MethodGet, map[string]string{"If-None-Match": `W/"v2"`}, http.StatusNotModified, false},
+		{"If-None-Match matches PUT", http.MethodPut, map[string]string{"If-None-Match": "*"}, http.StatusPreconditionFailed, false},
+		{"If-None-Match mismatch", http.MethodGet, map[string]string{"If-None-Match": `"v1"`}, 0, true},
+		{"If-Modified-Since not modified", http.MethodGet, map[string]string{"If-Modified-Since": lastMod.Format(http.TimeFormat)}, http.StatusNotModified, false},
+		{"If-Modified-Since modified", http.MethodGet, map[string]string{"If-Modified-Since": before}, 0, true},
+		{"If-Modified-Since ignored for POST", http.MethodPost, map[string]string{"If-Modified-Since": after}, 0, true},
+		{"If-None-Match overrides If-Modified-Since", http.MethodGet, map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": after}, 0, true},
+		{"invalid date ignored", http.MethodPut, map[string]string{"If-Unmodified-Since": "yesterday"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/users/1", http.NoBody)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			status, ok := CheckPreconditions(req, etag, lastMod)
+			if status != tt.expectedStatus || ok != tt.expectedOK {
+				t.Errorf("expected (%d, %v), got (%d, %v)", tt.expectedStatus, tt.expectedOK, status, ok)
+			}
+		})
+	}
+}
+
+func TestCheckPreconditions_IfMatchWildcard(t *testing.T) {
+	for _, etag := range []string{`"v2"`, `W/"v2"`, ""} {
+		req := httptest.NewRequest(http.MethodPut, "/users/1", http.NoBody)
+		req.Header.Set("If-Match", "*")
+
+		if status, ok := CheckPreconditions(req, etag, time.Time{}); status != 0 || !ok {
+			t.Errorf("expected If-Match: * to match etag %q, got (%d, %v)", etag, status, ok)
+		}
+	}
+}
+
+func TestCheckPreconditions_WithHandler(t *testing.T) {
+	lastMod := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	updated := false
+	handler := Handler()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if _, ok := CheckPreconditions(r, `"v2"`, lastMod); !ok {
+			return
+		}
+		updated = true
+		SetResponse(r, http.StatusOK, map[string]string{"id": "1"})
+	}))
+
+	t.Run("stale If-Match on PUT", func(t *testing.T) {
+		updated = false
+		req := httptest.NewRequest(http.MethodPut, "/users/1", http.NoBody)
+		req.Header.Set("If-Match", `"v1"`)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusPreconditionFailed {
+			t.Fatalf("expected status 412, got %d", rec.Code)
+		}
+		var body map[string]*APIError
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if body["error"].Code != ErrorCodePreconditionFailed {
+			t.Errorf("expected code %s, got %s", ErrorCodePreconditionFailed, body["error"].Code)
+		}
+		if updated {
+			t.Error("expected stale update to be rejected before the handler proceeds")
+		}
+	})
+
+	t.Run("matching If-Match on PUT", func(t *testing.T) {
+		updated = false
+		req := httptest.NewRequest(http.MethodPut, "/users/1", http.NoBody)
+		req.Header.Set("If-Match", `"v2"`)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK || !updated {
+			t.Errorf("expected update to proceed with 200, got %d (updated=%v)", rec.Code, updated)
+		}
+	})
+
+	t.Run("revalidating GET", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", http.NoBody)
+		req.Header.Set("If-None-Match", `"v2"`)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotModified {
+			t.Fatalf("expected status 304, got %d", rec.Code)
+		}
+		if rec.Header().Get("ETag") != `"v2"` || rec.Header().Get("Last-Modified") != lastMod.Format(http.TimeFormat) {
+			t.Errorf("expected ETag and Last-Modified on 304, got %v", rec.Header())
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("expected empty body, got %q", rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package chikit
+
+// RFC 9457 (formerly RFC 7807) problem details for WithProblemDetails.
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// problemDetails is the application/problem+json form of an APIError. code and
+// errors are extension members carrying the chikit error code and field errors.
+type problemDetails struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail,omitempty"`
+	Code   ErrorCode           `json:"code,omitempty"`
+	Errors []problemFieldError `json:"errors,omitempty"`
+}
+
+// problemFieldError is one entry of the errors extension member. Pointer is a JSON
+// pointer (RFC 6901) to the offending field in the request body.
+type problemFieldError struct {
+	Detail  string `json:"detail"`
+	Pointer string `json:"pointer"`
+	Code    string `json:"code"`
+}
+
+// encodeProblem encodes apiErr as a problem details document. The type member is
+// the error's DocsURL, or "about:blank" if it has none.
+func encodeProblem(apiErr *APIError) ([]byte, error) {
+	problem := problemDetails{
+		Type:   apiErr.DocsURL,
+		Title:  http.StatusText(apiErr.Status),
+		Status: apiErr.Status,
+		Detail: apiErr.Message,
+		Code:   apiErr.Code,
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	for _, fe := range apiErr.Errors {
+		problem.Errors = append(problem.Errors, problemFieldError{
+			Detail:  fe.Message,
+			Pointer: jsonPointer(fe.pointerPath()),
+			Code:    fe.Code,
+		})
+	}
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
+
+// pointerPath returns the dotted path to fe's field, or Param if fe was not built
+// from a nested validation error.
+func (fe FieldError) pointerPath() string {
+	if fe.path != "" {
+		return fe.path
+	}
+	return fe.Param
+}
+
+// jsonPointer converts a dotted field path (e.g., "address.city" or
+// "items.0.sku") to a JSON pointer ("/address/city"). An empty path points at the
+// whole document.
+func jsonPointer(path string) string {
+	if path == "" {
+		return ""
+	}
+	escape := strings.NewReplacer("~", "~0", "/", "~1")
+	var b strings.Builder
+	for _, segment := range strings.Split(path, ".") {
+		b.WriteByte('/')
+		b.WriteString(escape.Replace(segment))
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type problemOrder struct {
+	Email   string `json:"email" validate:"required,email"`
+	Address struct {
+		City string `json:"city" validate:"required"`
+	} `json:"address"`
+	Items []struct {
+		SKU string `json:"sku" validate:"required"`
+	} `json:"items" validate:"dive"`
+}
+
+func TestWithProblemDetails_ValidationErrors(t *testing.T) {
+	h := Handler(WithProblemDetails())(Binder()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var order problemOrder
+		if !JSON(r, &order) {
+			return
+		}
+		SetResponse(r, http.StatusOK, order)
+	})))
+
+	body := `{"email": "not-an-email", "address": {}, "items": [{"sku": "a"}, {}]}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected application/problem+json, got %q", ct)
+	}
+
+	var got problemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	want := problemDetails{
+		Type:   "about:blank",
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: "Validation failed",
+		Code:   ErrorCodeInvalidRequest,
+		Errors: []problemFieldError{
+			{Detail: "must be a valid email", Pointer: "/email", Code: "email"},
+			{Detail: "required", Pointer: "/address/city", Code: "required"},
+			{Detail: "required", Pointer: "/items/1/sku", Code: "required"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestWithProblemDetails_Error(t *testing.T) {
+	h := Handler(WithProblemDetails())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetError(r, ErrNotFound.With("User not found").WithDocs("https://docs.example.com/errors/not-found"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+	want := `{"type":"https://docs.example.com/errors/not-found","title":"Not Found","status":404,"detail":"User not found","code":"resource_not_found"}` + "\n"
+	if rec.Code != http.StatusNotFound || rec.Body.String() != want {
+		t.Errorf("expected 404 %s, got %d %s", want, rec.Code, rec.Body.String())
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{"email", "/email"},
+		{"address.city", "/address/city"},
+		{"items.0.sku", "/items/0/sku"},
+		{"a/b.c~d", "/a~1b/c~0d"},
+	}
+	for _, tt := range tests {
+		if got := jsonPointer(tt.path); got != tt.want {
+			t.Errorf("jsonPointer(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package chikit
+
+// Upstream rate limit passthrough for gateways fronting third-party APIs.
+//
+// Surfaces the upstream's RateLimit-* and Retry-After headers to clients and can
+// fail fast locally while the upstream limit is exhausted.
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// proxyRateLimitHeaders are the upstream headers surfaced to clients.
+var proxyRateLimitHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"}
+
+type proxyRateLimitConfig struct {
+	shortCircuit bool
+}
+
+// ProxyRateLimitOption configures ProxyRateLimitPassthrough middleware.
+type ProxyRateLimitOption func(*proxyRateLimitConfig)
+
+// ProxyRateLimitWithShortCircuit rejects requests locally with 429 while the upstream
+// limit is exhausted, without calling the upstream. The block is tripped by an upstream
+// 429 (until Retry-After) or by RateLimit-Remaining: 0 (until RateLimit-Reset).
+func ProxyRateLimitWithShortCircuit() ProxyRateLimitOption {
+	return func(c *proxyRateLimitConfig) {
+		c.shortCircuit = true
+	}
+}
+
+// ProxyRateLimitPassthrough returns middleware for handlers that proxy to an upstream
+// with its own rate limits (e.g., httputil.ReverseProxy).
+//
+// Upstream RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, and Retry-After
+// headers pass through to the client. When the Handler wrapper is active, an upstream
+// 429 is converted into a structured ErrRateLimited response carrying those headers;
+// the upstream body is discarded.
+//
+// RateLimit-Reset is interpreted as a Unix timestamp when it looks like one, otherwise
+// as delta seconds. Retry-After accepts delta seconds or an HTTP date.
+//
+// The short-circuit state is shared by all requests through this middleware instance,
+// so create one instance per upstream.
+//
+// Example:
+//
+//	proxy := httputil.NewSingleHostReverseProxy(upstreamURL)
+//	r.With(chikit.ProxyRateLimitPassthrough(chikit.ProxyRateLimitWithShortCircuit())).
+//		Handle("/partner/*", proxy)
+func ProxyRateLimitPassthrough(opts ...ProxyRateLimitOption) func(http.Handler) http.Handler {
+	cfg := &proxyRateLimitConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// blockedUntil holds the Unix nanoseconds until which requests fail fast.
+	var blockedUntil atomic.Int64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			useWrapper := HasState(r.Context())
+
+			if cfg.shortCircuit {
+				if wait := time.Until(time.Unix(0, blockedUntil.Load())); wait > 0 {
+					retryAfter := strconv.Itoa(int(math.Ceil(wait.Seconds())))
+					if useWrapper {
+						SetHeader(r, "Retry-After", retryAfter)
+					} else {
+						w.Header().Set("Retry-After", retryAfter)
+					}
+					respond(w, r, ErrRateLimited.With("Upstream rate limit exceeded"))
+					return
+				}
+			}
+
+			pw := &proxyRateLimitWriter{
+				ResponseWriter: w,
+				r:              r,
+				useWrapper:     useWrapper,
+				trip: func(until time.Time) {
+					if cfg.shortCircuit {
+						blockedUntil.Store(until.UnixNano())
+					}
+				},
+			}
+			next.ServeHTTP(pw, r)
+		})
+	}
+}
+
+// proxyRateLimitWriter inspects the upstream status and headers as they are written.
+type proxyRateLimitWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	useWrapper  bool
+	trip        func(until time.Time)
+	wroteHeader bool
+	discard     bool
+}
+
+func (w *proxyRateLimitWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	now := time.Now()
+	if code == http.StatusTooManyRequests {
+		until := now.Add(time.Second)
+		if t, ok := parseRetryAfter(h.Get("Retry-After"), now); ok {
+			until = t
+		} else if t, ok := parseRateLimitReset(h.Get("RateLimit-Reset"), now); ok {
+			until = t
+		}
+		w.trip(until)
+	} else if h.Get("RateLimit-Remaining") == "0" {
+		if t, ok := parseRateLimitReset(h.Get("RateLimit-Reset"), now); ok {
+			w.trip(t)
+		}
+	}
+
+	if code == http.StatusTooManyRequests && w.useWrapper {
+		for _, name := range proxyRateLimitHeaders {
+			if v := h.Get(name); v != "" {
+				SetHeader(w.r, name, v)
+			}
+			h.Del(name)
+		}
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		h.Del("Content-Encoding")
+		SetError(w.r, ErrRateLimited.With("Upstream rate limit exceeded"))
+		w.discard = true
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *proxyRateLimitWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discard {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *proxyRateLimitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// parseRetryAfter parses a Retry-After value as delta seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+		return now.Add(time.Duration(secs) * time.Second), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// unixTimestampThreshold separates Unix timestamps from delta seconds in
+// RateLimit-Reset (any value above ~2001-09-09 is treated as a timestamp).
+const unixTimestampThreshold = 1_000_000_000
+
+// parseRateLimitReset parses a RateLimit-Reset value as a Unix timestamp or delta seconds.
+func parseRateLimitReset(v string, now time.Time) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	if n > unixTimestampThreshold {
+		return time.Unix(n, 0), true
+	}
+	return now.Add(time.Duration(n) * time.Second), true
+}
@@ -0,0 +1,145 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestProxyRateLimitPassthrough_Upstream429(t *testing.T) {
+	upstreamCalls := 0
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		upstreamCalls++
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("RateLimit-Limit", "100")
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("slow down"))
+	})
+
+	handler := Handler()(ProxyRateLimitPassthrough(ProxyRateLimitWithShortCircuit())(upstream))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("expected Retry-After 30, got %q", got)
+	}
+	if got := rec.Header().Values("RateLimit-Limit"); len(got) != 1 || got[0] != "100" {
+		t.Errorf("expected single RateLimit-Limit 100, got %v", got)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %s", ct)
+	}
+
+	var resp map[string]*APIError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp["error"].Type != "rate_limit_error" {
+		t.Errorf("expected rate_limit_error, got %s", resp["error"].Type)
+	}
+
+	// Subsequent requests fail fast without reaching the upstream
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected short-circuit 429, got %d", rec.Code)
+	}
+	if upstreamCalls != 1 {
+		t.Errorf("expected upstream to be called once, got %d", upstreamCalls)
+	}
+	retryAfter, _ := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if retryAfter < 29 || retryAfter > 30 {
+		t.Errorf("expected short-circuit Retry-After near 30, got %d", retryAfter)
+	}
+}
+
+func TestProxyRateLimitPassthrough_SuccessHeadersPassThrough(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("RateLimit-Limit", "100")
+		w.Header().Set("RateLimit-Remaining", "42")
+		w.Header().Set("RateLimit-Reset", reset)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+
+	handler := Handler()(ProxyRateLimitPassthrough(ProxyRateLimitWithShortCircuit())(upstream))
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != "42" {
+			t.Errorf("expected RateLimit-Remaining 42, got %q", got)
+		}
+		if rec.Body.String() != "ok" {
+			t.Errorf("expected upstream body, got %q", rec.Body.String())
+		}
+	}
+}
+
+func TestProxyRateLimitPassthrough_RemainingZeroTrips(t *testing.T) {
+	upstreamCalls := 0
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		upstreamCalls++
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", "60")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := ProxyRateLimitPassthrough(ProxyRateLimitWithShortCircuit())(upstream)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected short-circuit 429, got %d", rec.Code)
+	}
+	if upstreamCalls != 1 {
+		t.Errorf("expected upstream to be called once, got %d", upstreamCalls)
+	}
+}
+
+func TestProxyRateLimitPassthrough_NoShortCircuitByDefault(t *testing.T) {
+	upstreamCalls := 0
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		upstreamCalls++
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	handler := ProxyRateLimitPassthrough()(upstream)
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+		if rec.Code != http.StatusTooManyRequests {
+			t.Errorf("expected status 429, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "30" {
+			t.Errorf("expected Retry-After 30, got %q", got)
+		}
+	}
+	if upstreamCalls != 2 {
+		t.Errorf("expected upstream to be called twice, got %d", upstreamCalls)
+	}
+}
@@ -0,0 +1,142 @@
+package chikit
+
+// Long-window request quotas aligned to calendar periods.
+// Unlike RateLimiter's rolling windows, a quota resets at the start of each
+// day or month, and is reported in separate Quota-* headers.
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nhalm/chikit/store"
+)
+
+// QuotaPeriod is the calendar period a quota counts over.
+type QuotaPeriod int
+
+const (
+	// QuotaDaily resets at midnight.
+	QuotaDaily QuotaPeriod = iota
+
+	// QuotaMonthly resets at midnight on the first day of each month.
+	QuotaMonthly
+)
+
+// String returns the period name used in error messages ("day" or "month").
+func (p QuotaPeriod) String() string {
+	if p == QuotaMonthly {
+		return "month"
+	}
+	return "day"
+}
+
+// bounds returns the start of the period containing t and the start of the next period.
+func (p QuotaPeriod) bounds(t time.Time) (time.Time, time.Time) {
+	if p == QuotaMonthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+type quotaConfig struct {
+	location *time.Location
+	now      func() time.Time
+}
+
+// QuotaOption configures Quota middleware.
+type QuotaOption func(*quotaConfig)
+
+// QuotaWithLocation sets the time zone that period boundaries align to. Default is UTC.
+func QuotaWithLocation(loc *time.Location) QuotaOption {
+	return func(c *quotaConfig) {
+		c.location = loc
+	}
+}
+
+// QuotaWithClock sets the function used to read the current time. Default is time.Now.
+// Intended for tests that need to cross period boundaries.
+func QuotaWithClock(now func() time.Time) QuotaOption {
+	return func(c *quotaConfig) {
+		c.now = now
+	}
+}
+
+// Quota returns middleware that enforces limit requests per calendar period for the
+// key returned by keyFn (e.g., an API key). Requests where keyFn returns "" are not
+// counted. The store key includes the period start, so counts reset at the boundary
+// regardless of store TTL precision.
+//
+// Sets the following headers on every counted response:
+//   - Quota-Limit: The quota for the period
+//   - Quota-Remaining: Requests remaining in the current period
+//   - Quota-Reset: Unix timestamp when the next period starts
+//   - Retry-After: (only when exceeded) Seconds until the next period
+//
+// Returns 429 (Too Many Requests) with ErrQuotaExceeded when the quota is used up.
+// Returns 500 (Internal Server Error) if the store operation fails.
+//
+// Quotas compose with RateLimiter: use a rate limit for bursts and a quota for
+// billing-style monthly allowances.
+//
+// Example:
+//
+//	r.Use(chikit.Quota(st, 10000, chikit.QuotaMonthly, func(r *http.Request) string {
+//		return r.Header.Get("X-API-Key")
+//	}))
+func Quota(st store.Store, limit int, period QuotaPeriod, keyFn func(*http.Request) string, opts ...QuotaOption) func(http.Handler) http.Handler {
+	cfg := &quotaConfig{location: time.UTC, now: time.Now}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := keyFn(r)
+			if id == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(id) > maxKeyComponentSize {
+				id = id[:maxKeyComponentSize]
+			}
+
+			useWrapper := HasState(r.Context())
+			setHeader := func(key, value string) {
+				if useWrapper {
+					SetHeader(r, key, value)
+				} else {
+					w.Header().Set(key, value)
+				}
+			}
+
+			now := cfg.now().In(cfg.location)
+			start, end := period.bounds(now)
+			key := "quota:" + period.String() + ":" + strconv.FormatInt(start.Unix(), 10) + ":" + id
+			untilReset := end.Sub(now)
+
+			count, _, err := st.Increment(r.Context(), key, untilReset)
+			if err != nil {
+				respond(w, r, ErrInternal.With("Quota check failed"))
+				return
+			}
+
+			limit := int64(limit)
+			setHeader("Quota-Limit", strconv.FormatInt(limit, 10))
+			setHeader("Quota-Remaining", strconv.FormatInt(max(0, limit-count), 10))
+			setHeader("Quota-Reset", strconv.FormatInt(end.Unix(), 10))
+
+			if count > limit {
+				setHeader("Retry-After", strconv.Itoa(int(untilReset.Seconds())))
+				errMsg := fmt.Sprintf("Quota exceeded: %d requests per %s", limit, period)
+				respond(w, r, ErrQuotaExceeded.With(errMsg))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nhalm/chikit/store"
+)
+
+func TestQuota_CountsWithinPeriodAndResets(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	now := time.Date(2025, time.January, 31, 23, 59, 0, 0, time.UTC)
+	handler := Handler()(Quota(st, 2, QuotaMonthly, func(r *http.Request) string {
+		return r.Header.Get("X-API-Key")
+	}, QuotaWithClock(func() time.Time { return now }))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.Header.Set("X-API-Key", "key-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	february := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+	for i, expectedRemaining := range []string{"1", "0"} {
+		rec := do()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i+1, rec.Code)
+		}
+		if rec.Header().Get("Quota-Limit") != "2" || rec.Header().Get("Quota-Remaining") != expectedRemaining {
+			t.Errorf("request %d: expected limit 2 remaining %s, got %s/%s", i+1, expectedRemaining,
+				rec.Header().Get("Quota-Limit"), rec.Header().Get("Quota-Remaining"))
+		}
+		if reset := rec.Header().Get("Quota-Reset"); reset != strconv.FormatInt(february.Unix(), 10) {
+			t.Errorf("request %d: expected reset at start of February, got %s", i+1, reset)
+		}
+	}
+
+	rec := do()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 after quota used, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected Retry-After 60, got %s", rec.Header().Get("Retry-After"))
+	}
+
+	now = february.Add(time.Hour)
+	rec = do()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 in new period, got %d", rec.Code)
+	}
+	if rec.Header().Get("Quota-Remaining") != "1" {
+		t.Errorf("expected quota to reset at period boundary, got remaining %s", rec.Header().Get("Quota-Remaining"))
+	}
+}
+
+func TestQuota_DailyBoundsInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	start, end := QuotaDaily.bounds(time.Date(2025, time.March, 10, 2, 0, 0, 0, time.UTC).In(loc))
+
+	if expected := time.Date(2025, time.March, 9, 0, 0, 0, 0, loc); !start.Equal(expected) {
+		t.Errorf("expected start %v, got %v", expected, start)
+	}
+	if expected := time.Date(2025, time.March, 10, 0, 0, 0, 0, loc); !end.Equal(expected) {
+		t.Errorf("expected end %v, got %v", expected, end)
+	}
+}
+
+func TestQuota_SkipsEmptyKey(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	handler := Quota(st, 1, QuotaDaily, func(*http.Request) string { return "" })(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+		if rec.Code != http.StatusOK || rec.Header().Get("Quota-Limit") != "" {
+			t.Fatalf("expected uncounted request, got status %d with Quota-Limit %q", rec.Code, rec.Header().Get("Quota-Limit"))
+		}
+	}
+}
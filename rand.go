@@ -0,0 +1,71 @@
+package chikit
+
+// Randomness sources shared by features that generate identifiers, nonces, or jitter.
+//
+// Jitter (RateLimitWithRetryAfterJitter) draws from a replaceable math/rand source so
+// tests can inject a fixed seed. Values that must be unpredictable always draw from
+// crypto/rand and are not affected by SetRandSource: CSP nonces (CSP) and generated
+// trace and span IDs (TraceContext).
+
+import (
+	crand "crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+)
+
+var (
+	randMu  sync.Mutex
+	randGen = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+)
+
+// SetRandSource replaces the source used for non-security randomness, such as the
+// RateLimitWithRetryAfterJitter jitter. Pass nil to restore the default randomly
+// seeded source.
+// Intended for tests that need reproducible output:
+//
+//	chikit.SetRandSource(rand.NewPCG(1, 2))
+//	defer chikit.SetRandSource(nil)
+//
+// CSP nonces and TraceContext IDs always use crypto/rand and are unaffected by
+// this setting.
+func SetRandSource(src rand.Source) {
+	randMu.Lock()
+	defer randMu.Unlock()
+	if src == nil {
+		src = rand.NewPCG(rand.Uint64(), rand.Uint64())
+	}
+	randGen = rand.New(src)
+}
+
+// randFloat64 returns a pseudo-random number in [0.0, 1.0) from the configured source.
+// Not suitable for security-sensitive values.
+func randFloat64() float64 {
+	randMu.Lock()
+	defer randMu.Unlock()
+	return randGen.Float64()
+}
+
+// randJitter returns d increased by a random amount in [0, fraction*d).
+func randJitter(d float64, fraction float64) float64 {
+	return d * (1 + fraction*randFloat64())
+}
+
+// randomToken returns n bytes from crypto/rand encoded as unpadded base64url.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// randomHex returns n bytes from crypto/rand encoded as lowercase hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
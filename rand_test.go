@@ -0,0 +1,46 @@
+package chikit
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSetRandSource_DeterministicJitter(t *testing.T) {
+	defer SetRandSource(nil)
+
+	SetRandSource(rand.NewPCG(1, 2))
+	first := []float64{randJitter(1000, 0.2), randJitter(1000, 0.2), randJitter(1000, 0.2)}
+
+	SetRandSource(rand.NewPCG(1, 2))
+	second := []float64{randJitter(1000, 0.2), randJitter(1000, 0.2), randJitter(1000, 0.2)}
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("expected deterministic jitter at %d, got %v and %v", i, first[i], second[i])
+		}
+		if first[i] < 1000 || first[i] >= 1200 {
+			t.Errorf("expected jitter within [1000, 1200), got %v", first[i])
+		}
+	}
+}
+
+func TestRandomToken_Unique(t *testing.T) {
+	// Fixed math/rand seed must not make tokens predictable
+	SetRandSource(rand.NewPCG(1, 2))
+	defer SetRandSource(nil)
+
+	seen := make(map[string]bool)
+	for range 100 {
+		tok, err := randomToken(16)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(tok) != 22 {
+			t.Errorf("expected 22 character token, got %d", len(tok))
+		}
+		if seen[tok] {
+			t.Fatalf("duplicate token generated: %s", tok)
+		}
+		seen[tok] = true
+	}
+}
@@ -31,11 +31,15 @@
 package chikit
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/nhalm/chikit/store"
@@ -59,10 +63,57 @@ const (
 	RateLimitHeadersNever
 )
 
+// RateLimitAlgorithm selects how a RateLimiter counts requests.
+type RateLimitAlgorithm int
+
+const (
+	// RateLimitFixedWindow counts requests in fixed windows that reset every window
+	// duration (default). Simple and cheap, but allows bursts of up to 2x the limit
+	// across a window boundary.
+	RateLimitFixedWindow RateLimitAlgorithm = iota
+
+	// RateLimitTokenBucket allows bursts of up to limit requests and then refills one
+	// request every window/limit, smoothing traffic without boundary bursts.
+	// Requires a store implementing store.TokenBucketStore (Memory and Redis do).
+	RateLimitTokenBucket
+
+	// RateLimitSlidingWindow logs request timestamps and counts only those within the
+	// trailing window, enforcing the limit exactly for any window-length interval.
+	// Stores up to limit timestamps per key. Requires a store implementing
+	// store.SlidingWindowStore (Memory and Redis do).
+	RateLimitSlidingWindow
+
+	// RateLimitGCRA applies the generic cell rate algorithm (leaky bucket as a meter):
+	// requests are spaced window/limit apart, with up to a burst of requests allowed
+	// at once. Stores one timestamp per key. Requires a store implementing
+	// store.GCRAStore (Memory and Redis do). Select with RateLimitWithGCRA to set
+	// the burst; otherwise the burst is 1.
+	RateLimitGCRA
+)
+
 // rateLimitKeyFunc extracts a rate limiting key component from an HTTP request.
 // Returning an empty string indicates the value is missing.
 type rateLimitKeyFunc func(*http.Request) string
 
+// KeyStrategy builds the rate limit key for a request. The built-in key dimensions
+// (RateLimitWithIP, RateLimitWithHeader, ...) are one strategy; implement
+// KeyStrategy and pass it to RateLimitWithKeyStrategy for keys the dimensions cannot
+// express, such as one derived from parsed JWT claims.
+type KeyStrategy interface {
+	// Key returns the key r is counted under. A non-empty missing names a required
+	// input absent from r (e.g., "header X-API-Key"), and the request is rejected
+	// with 400. An empty key with an empty missing skips rate limiting for r.
+	Key(r *http.Request) (key string, missing string)
+}
+
+// KeyStrategyFunc adapts a function to KeyStrategy.
+type KeyStrategyFunc func(r *http.Request) (key string, missing string)
+
+// Key calls f(r).
+func (f KeyStrategyFunc) Key(r *http.Request) (string, string) {
+	return f(r)
+}
+
 // rateLimitDimension holds a key function with validation metadata.
 type rateLimitDimension struct {
 	fn       rateLimitKeyFunc
@@ -73,11 +124,24 @@ type rateLimitDimension struct {
 // RateLimiter implements rate limiting middleware.
 type RateLimiter struct {
 	store      store.Store
-	limit      int64
-	window     time.Duration
+	current    atomic.Pointer[rateLimitSettings]
 	name       string
 	keyDims    []rateLimitDimension
+	strategy   KeyStrategy
+	keys       KeyStrategy
 	headerMode RateLimitHeaderMode
+	algorithm  RateLimitAlgorithm
+	burst      int64
+	onExceeded func(r *http.Request, retryAfter time.Duration) (int, any)
+	exempt     func(*http.Request) bool
+	tierFn     func(*http.Request) (name string, limit int, window time.Duration)
+	jitter     float64
+}
+
+// rateLimitSettings is the limit and window pair that SetLimit replaces atomically.
+type rateLimitSettings struct {
+	limit  int64
+	window time.Duration
 }
 
 // RateLimitOption configures a RateLimiter.
@@ -90,6 +154,104 @@ func RateLimitWithHeaderMode(mode RateLimitHeaderMode) RateLimitOption {
 	}
 }
 
+// RateLimitWithAlgorithm selects the rate limiting algorithm.
+// Default is RateLimitFixedWindow.
+func RateLimitWithAlgorithm(algo RateLimitAlgorithm) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.algorithm = algo
+	}
+}
+
+// RateLimitWithGCRA selects RateLimitGCRA, allowing up to burst requests at once
+// while enforcing a steady rate of one request every window/limit. Burst values
+// below 1 are treated as 1, which allows no requests faster than the steady rate.
+//
+// Example:
+//
+//	// 10 requests per second on average, up to 20 at once.
+//	chikit.NewRateLimiter(st, 10, time.Second,
+//		chikit.RateLimitWithIP(),
+//		chikit.RateLimitWithGCRA(20),
+//	)
+func RateLimitWithGCRA(burst int) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.algorithm = RateLimitGCRA
+		l.burst = int64(burst)
+	}
+}
+
+// RateLimitWithExceededResponse replaces the response written when the limit is
+// exceeded. fn receives the request and the time until the client may retry, and
+// returns the status and body to write (e.g., 503 for gateways that expect it).
+// With Handler, the result is set via SetResponse (or SetError if body is an
+// *APIError, using the returned status), so it is formatted like other responses;
+// without it, body is encoded as JSON directly. Rate limit headers are unaffected.
+//
+// Example:
+//
+//	chikit.RateLimitWithExceededResponse(func(r *http.Request, retryAfter time.Duration) (int, any) {
+//		return http.StatusTooManyRequests, map[string]any{
+//			"message":     "Slow down",
+//			"retry_after": int(retryAfter.Seconds()),
+//		}
+//	})
+func RateLimitWithExceededResponse(fn func(r *http.Request, retryAfter time.Duration) (int, any)) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.onExceeded = fn
+	}
+}
+
+// RateLimitWithRetryAfterJitter adds up to fraction of Retry-After on denied requests
+// (e.g., 0.2 turns 60 seconds into 60-72), so clients limited at the same moment do
+// not all retry at once. Retry-After is only ever increased, never shortened below
+// the actual reset. The jitter draws from the source set by SetRandSource.
+// Default is 0 (no jitter).
+func RateLimitWithRetryAfterJitter(fraction float64) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.jitter = fraction
+	}
+}
+
+// RateLimitWithExempt skips rate limiting for requests where fn returns true, e.g.
+// health checks, trusted IPs, or requests authenticated with an admin token.
+// Exempt requests are not counted and get no RateLimit-* headers. fn is evaluated
+// before the key is built, so it can depend on anything in the request or context
+// without affecting key construction, and exempt requests are never rejected for a
+// missing required dimension.
+//
+// Example:
+//
+//	chikit.RateLimitWithExempt(func(r *http.Request) bool {
+//		return r.URL.Path == "/healthz" || isAdmin(r.Context())
+//	})
+func RateLimitWithExempt(fn func(*http.Request) bool) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.exempt = fn
+	}
+}
+
+// RateLimitWithTierFunc classifies each request into a tier (e.g., "free" or "paid")
+// with its own limit and window, so one limiter can enforce different quotas per plan.
+// The tier name is folded into the key, so counters for different tiers never collide.
+// When fn returns an empty name, the limit and window passed to NewRateLimiter apply.
+//
+// Example:
+//
+//	chikit.RateLimitWithTierFunc(func(r *http.Request) (string, int, time.Duration) {
+//		switch planFromContext(r.Context()) {
+//		case "enterprise":
+//			return "enterprise", 10000, time.Minute
+//		case "pro":
+//			return "pro", 1000, time.Minute
+//		}
+//		return "", 0, 0 // base limit
+//	})
+func RateLimitWithTierFunc(fn func(*http.Request) (name string, limit int, window time.Duration)) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.tierFn = fn
+	}
+}
+
 // RateLimitWithName sets a prefix for rate limit keys.
 // Use to prevent key collisions when layering multiple rate limiters.
 func RateLimitWithName(name string) RateLimitOption {
@@ -98,6 +260,24 @@ func RateLimitWithName(name string) RateLimitOption {
 	}
 }
 
+// RateLimitWithKeyStrategy builds keys with s instead of key dimension options, which
+// cannot be combined with it. Keys are prefixed with RateLimitWithName, if set.
+//
+// Example keying on the authenticated subject:
+//
+//	chikit.RateLimitWithKeyStrategy(chikit.KeyStrategyFunc(func(r *http.Request) (string, string) {
+//		claims, ok := auth.ClaimsFrom(r.Context())
+//		if !ok {
+//			return "", "bearer token"
+//		}
+//		return claims.Subject, ""
+//	}))
+func RateLimitWithKeyStrategy(s KeyStrategy) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.strategy = s
+	}
+}
+
 // RateLimitWithIP adds the client IP address (from RemoteAddr) to the rate limiting key.
 // Use this for direct connections without a proxy. RemoteAddr is always present.
 func RateLimitWithIP() RateLimitOption {
@@ -231,8 +411,8 @@ func rateLimitWithQueryParam(param string, required bool) RateLimitOption {
 // Returns 400 (Bad Request) if a *Required dimension is missing.
 // Returns 500 (Internal Server Error) if the store operation fails.
 //
-// At least one key dimension option must be provided.
-// Panics if no key dimensions are configured.
+// At least one key dimension option, or RateLimitWithKeyStrategy, must be provided.
+// Panics if neither or both are configured.
 //
 // Key dimension options:
 //   - RateLimitWithIP: Add RemoteAddr IP to key (direct connections)
@@ -240,49 +420,181 @@ func rateLimitWithQueryParam(param string, required bool) RateLimitOption {
 //   - RateLimitWithEndpoint: Add method:path to key
 //   - RateLimitWithHeader / RateLimitWithHeaderRequired: Add header value to key
 //   - RateLimitWithQueryParam / RateLimitWithQueryParamRequired: Add query parameter to key
+//   - RateLimitWithKeyStrategy: Build keys with a custom KeyStrategy instead
 //
 // Other options:
 //   - RateLimitWithName: Set key prefix for collision prevention
 //   - RateLimitWithHeaderMode: Configure header visibility (default: RateLimitHeadersAlways)
+//   - RateLimitWithAlgorithm: Select fixed window (default), token bucket, or sliding window
+//   - RateLimitWithGCRA: Select GCRA with a burst allowance
+//
+// Panics if RateLimitTokenBucket, RateLimitSlidingWindow, or RateLimitGCRA is selected
+// and st does not support store.TokenBucketStore, store.SlidingWindowStore, or
+// store.GCRAStore respectively (see store.Supports; a store.Instrumented store
+// supports what the store it wraps supports).
 func NewRateLimiter(st store.Store, limit int, window time.Duration, opts ...RateLimitOption) *RateLimiter {
 	l := &RateLimiter{
 		store:      st,
-		limit:      int64(limit),
-		window:     window,
 		keyDims:    make([]rateLimitDimension, 0),
 		headerMode: RateLimitHeadersAlways,
 	}
+	l.current.Store(&rateLimitSettings{limit: int64(limit), window: window})
 	for _, opt := range opts {
 		opt(l)
 	}
-	if len(l.keyDims) == 0 {
-		panic("ratelimit: must configure at least one key dimension option (RateLimitWithIP, RateLimitWithRealIP, RateLimitWithEndpoint, RateLimitWithHeader, or RateLimitWithQueryParam)")
+	switch {
+	case l.strategy != nil && len(l.keyDims) > 0:
+		panic("ratelimit: RateLimitWithKeyStrategy cannot be combined with key dimension options")
+	case l.strategy != nil:
+		l.keys = l.strategy
+		if l.name != "" {
+			l.keys = prefixedKeyStrategy{prefix: l.name, strategy: l.strategy}
+		}
+	case len(l.keyDims) == 0:
+		panic("ratelimit: must configure at least one key dimension option (RateLimitWithIP, RateLimitWithRealIP, RateLimitWithEndpoint, RateLimitWithHeader, or RateLimitWithQueryParam) or RateLimitWithKeyStrategy")
+	default:
+		l.keys = dimensionKeyStrategy{name: l.name, dims: l.keyDims}
+	}
+	if l.algorithm == RateLimitTokenBucket {
+		if !store.Supports[store.TokenBucketStore](st) {
+			panic("ratelimit: RateLimitTokenBucket requires a store implementing store.TokenBucketStore")
+		}
+	}
+	if l.algorithm == RateLimitSlidingWindow {
+		if !store.Supports[store.SlidingWindowStore](st) {
+			panic("ratelimit: RateLimitSlidingWindow requires a store implementing store.SlidingWindowStore")
+		}
+	}
+	if l.algorithm == RateLimitGCRA {
+		if !store.Supports[store.GCRAStore](st) {
+			panic("ratelimit: RateLimitGCRA requires a store implementing store.GCRAStore")
+		}
+		l.burst = max(1, l.burst)
 	}
 	return l
 }
 
+// RateLimitConfig describes the effective settings of a RateLimiter, as returned by Config.
+type RateLimitConfig struct {
+	Limit      int                 `json:"limit"`
+	Window     time.Duration       `json:"window"`
+	Name       string              `json:"name,omitempty"`
+	HeaderMode RateLimitHeaderMode `json:"header_mode"`
+	Algorithm  RateLimitAlgorithm  `json:"algorithm"`
+	// Burst is the GCRA burst allowance (zero for other algorithms).
+	Burst int `json:"burst,omitempty"`
+	// Dimensions describes each key dimension in order (e.g., "IP",
+	// "header X-API-Key (required)").
+	Dimensions []string `json:"dimensions"`
+}
+
+// SetLimit replaces the limiter's limit and window at runtime, e.g., from a config
+// reload, without recreating the middleware. The pair is swapped atomically, so each
+// request sees either the old or the new settings, never a mix. Tiers returned by
+// RateLimitWithTierFunc are unaffected.
+//
+// Counters already in the store are kept: with the fixed window algorithm, the new
+// window applies once the current one expires.
+//
+// Example:
+//
+//	watcher.OnChange(func(cfg Config) {
+//		limiter.SetLimit(cfg.RequestsPerMinute, time.Minute)
+//	})
+func (l *RateLimiter) SetLimit(limit int, window time.Duration) {
+	l.current.Store(&rateLimitSettings{limit: int64(limit), window: window})
+}
+
+// Config returns the limiter's effective settings, for exposing on admin or
+// config endpoints to verify what is deployed.
+func (l *RateLimiter) Config() RateLimitConfig {
+	dims := make([]string, len(l.keyDims))
+	for i, dim := range l.keyDims {
+		dims[i] = dim.name
+		if dim.required {
+			dims[i] += " (required)"
+		}
+	}
+	if l.strategy != nil {
+		dims = []string{"custom key strategy"}
+	}
+	current := l.current.Load()
+	return RateLimitConfig{
+		Limit:      int(current.limit),
+		Window:     current.window,
+		Name:       l.name,
+		HeaderMode: l.headerMode,
+		Algorithm:  l.algorithm,
+		Burst:      int(l.burst),
+		Dimensions: dims,
+	}
+}
+
+// ResetFor clears the rate limit state for the key r would be counted under, e.g. to
+// unblock a client after a false positive. Build r with the same headers, query
+// parameters, and remote address the client sends (only the dimensions the limiter
+// uses matter). Returns an error if a required dimension is missing from r; requests
+// that would not be rate limited have nothing to reset and return nil.
+//
+// Example admin endpoint unblocking an API key:
+//
+//	r.Post("/admin/unblock", func(w http.ResponseWriter, r *http.Request) {
+//		probe, _ := http.NewRequestWithContext(r.Context(), "GET", "/", http.NoBody)
+//		probe.Header.Set("X-API-Key", r.URL.Query().Get("key"))
+//		if err := limiter.ResetFor(probe); err != nil {
+//			chikit.SetError(r, chikit.ErrBadRequest.With(err.Error()))
+//		}
+//	})
+func (l *RateLimiter) ResetFor(r *http.Request) error {
+	key, missingDim, _, _ := l.resolve(r)
+	if missingDim != "" {
+		return fmt.Errorf("ratelimit: missing required %s", missingDim)
+	}
+	if key == "" {
+		return nil
+	}
+	return l.ResetKey(r.Context(), key)
+}
+
+// ResetKey clears the rate limit state for a key as built by the limiter: the
+// RateLimitWithName prefix (if any) followed by the non-empty dimension values
+// joined with ":" (e.g., "api:192.0.2.1:/users") or the RateLimitWithKeyStrategy
+// key, prefixed with "tier:<name>:" for requests classified by
+// RateLimitWithTierFunc. Prefer ResetFor unless the key is already known.
+func (l *RateLimiter) ResetKey(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, key)
+}
+
 // Handler returns the rate limiting middleware.
 // Sets the following headers based on header mode:
 //   - RateLimit-Limit: The rate limit ceiling for the current window
 //   - RateLimit-Remaining: Number of requests remaining in the current window
 //   - RateLimit-Reset: Unix timestamp when the current window resets
-//   - Retry-After: (only when limited) Seconds until the window resets
+//   - Retry-After: (only when limited) Seconds until the window resets, until the
+//     next token refills with RateLimitTokenBucket, until the oldest logged request
+//     leaves the window with RateLimitSlidingWindow, or until the next request
+//     conforms with RateLimitGCRA
 //
 // These headers follow the IETF draft-ietf-httpapi-ratelimit-headers specification.
+//
+// When the Handler wrapper is active, layered limiters report the most restrictive
+// status: the headers reflect the limiter with the fewest remaining requests,
+// regardless of the order the limiters ran in.
 func (l *RateLimiter) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.exempt != nil && l.exempt(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		ctx := r.Context()
 		useWrapper := HasState(ctx)
 
-		key, missingDim := l.buildKey(r)
+		key, missingDim, limit, window := l.resolve(r)
 
 		if missingDim != "" {
 			errMsg := fmt.Sprintf("Missing required %s", missingDim)
-			if useWrapper {
-				SetError(r, ErrBadRequest.With(errMsg))
-			} else {
-				http.Error(w, errMsg, http.StatusBadRequest)
-			}
+			respond(w, r, ErrBadRequest.With(errMsg))
 			return
 		}
 
@@ -291,73 +603,249 @@ func (l *RateLimiter) Handler(next http.Handler) http.Handler {
 			return
 		}
 
-		count, ttl, err := l.store.Increment(ctx, key, l.window)
+		remaining, resetTime, retryAfter, exceeded, err := l.check(r, key, limit, window)
 		if err != nil {
-			if useWrapper {
-				SetError(r, ErrInternal.With("Rate limit check failed"))
-			} else {
-				http.Error(w, "Rate limit check failed", http.StatusInternalServerError)
-			}
+			respond(w, r, ErrInternal.With("Rate limit check failed"))
 			return
 		}
 
-		remaining := max(0, l.limit-count)
-		resetTime := time.Now().Add(ttl).Unix()
-		exceeded := count > l.limit
-
-		shouldSetHeaders := l.headerMode == RateLimitHeadersAlways || (l.headerMode == RateLimitHeadersOnLimitExceeded && exceeded)
-
-		if shouldSetHeaders {
-			if useWrapper {
-				SetHeader(r, "RateLimit-Limit", strconv.FormatInt(l.limit, 10))
-				SetHeader(r, "RateLimit-Remaining", strconv.FormatInt(remaining, 10))
-				SetHeader(r, "RateLimit-Reset", strconv.FormatInt(resetTime, 10))
-			} else {
-				w.Header().Set("RateLimit-Limit", strconv.FormatInt(l.limit, 10))
-				w.Header().Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
-				w.Header().Set("RateLimit-Reset", strconv.FormatInt(resetTime, 10))
-			}
-		}
-
 		if exceeded {
-			if shouldSetHeaders {
-				if useWrapper {
-					SetHeader(r, "Retry-After", strconv.Itoa(int(ttl.Seconds())))
-				} else {
-					w.Header().Set("Retry-After", strconv.Itoa(int(ttl.Seconds())))
-				}
-			}
-			errMsg := fmt.Sprintf("Rate limit exceeded: %d requests per %s", l.limit, l.window)
-			if useWrapper {
-				SetError(r, ErrRateLimited.With(errMsg))
-			} else {
-				http.Error(w, errMsg, http.StatusTooManyRequests)
-			}
+			l.writeExceeded(w, r, useWrapper, limit, window, remaining, resetTime, retryAfter)
 			return
 		}
+		if l.headerMode == RateLimitHeadersAlways {
+			setRateLimitHeaders(w, r, useWrapper, limit, remaining, resetTime)
+		}
 
 		next.ServeHTTP(w, r)
 	})
 }
 
+// writeExceeded sets the rate limit and Retry-After headers per the header mode
+// and writes the 429 response, or the RateLimitWithExceededResponse result.
+func (l *RateLimiter) writeExceeded(w http.ResponseWriter, r *http.Request, useWrapper bool, limit int64, window time.Duration, remaining, resetTime int64, retryAfter int) {
+	if l.jitter > 0 {
+		retryAfter = int(math.Ceil(randJitter(float64(retryAfter), l.jitter)))
+	}
+	if l.headerMode != RateLimitHeadersNever {
+		setRateLimitHeaders(w, r, useWrapper, limit, remaining, resetTime)
+		setRetryAfter(w, r, useWrapper, retryAfter)
+	}
+	if l.onExceeded != nil {
+		status, body := l.onExceeded(r, time.Duration(retryAfter)*time.Second)
+		writeExceededResponse(w, r, useWrapper, status, body)
+		return
+	}
+	errMsg := fmt.Sprintf("Rate limit exceeded: %d requests per %s", limit, window)
+	respond(w, r, ErrRateLimited.With(errMsg))
+}
+
+// writeExceededResponse writes a RateLimitWithExceededResponse result through the
+// wrapper state when present, or as JSON directly. *APIError bodies are written as
+// errors in the standard envelope with their status replaced by status.
+func writeExceededResponse(w http.ResponseWriter, r *http.Request, useWrapper bool, status int, body any) {
+	apiErr, isAPIError := body.(*APIError)
+	if isAPIError && apiErr != nil {
+		dup := *apiErr
+		dup.Status = status
+		apiErr = &dup
+	}
+
+	if useWrapper {
+		if isAPIError && apiErr != nil {
+			SetError(r, apiErr)
+		} else {
+			SetResponse(r, status, body)
+		}
+		return
+	}
+
+	if isAPIError && apiErr != nil {
+		body = errorResponse{Error: apiErr}
+	}
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// resolve returns the key, limit, and window for r, applying RateLimitWithTierFunc.
+// missingDim is non-empty if a required dimension was missing; key is empty if the
+// request should not be rate limited.
+func (l *RateLimiter) resolve(r *http.Request) (key, missingDim string, limit int64, window time.Duration) {
+	key, missingDim = l.keys.Key(r)
+	current := l.current.Load()
+	limit, window = current.limit, current.window
+	if key == "" || missingDim != "" || l.tierFn == nil {
+		return key, missingDim, limit, window
+	}
+	if name, tierLimit, tierWindow := l.tierFn(r); name != "" {
+		key = "tier:" + name + ":" + key
+		limit, window = int64(tierLimit), tierWindow
+	}
+	return key, missingDim, limit, window
+}
+
+// check counts the request against key using the configured algorithm. Returns the
+// remaining requests, the Unix time the limit fully resets, the Retry-After seconds
+// to use if exceeded, and whether the limit is exceeded.
+func (l *RateLimiter) check(r *http.Request, key string, limit int64, window time.Duration) (remaining, reset int64, retryAfter int, exceeded bool, err error) {
+	now := time.Now()
+
+	if l.algorithm == RateLimitTokenBucket {
+		refill := window
+		if limit > 0 {
+			refill = window / time.Duration(limit)
+		}
+		tokens, allowed, err := l.store.(store.TokenBucketStore).TakeToken(r.Context(), key, limit, refill)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		// Remaining is floored; reset is when the bucket is full again; a denied
+		// request can retry once the next whole token has refilled.
+		untilFull := time.Duration((float64(limit) - tokens) * float64(refill))
+		untilToken := time.Duration((1 - tokens) * float64(refill))
+		return int64(math.Floor(tokens)), now.Add(untilFull).Unix(), max(1, int(math.Ceil(untilToken.Seconds()))), !allowed, nil
+	}
+
+	if l.algorithm == RateLimitGCRA {
+		emission := window
+		if limit > 0 {
+			emission = window / time.Duration(limit)
+		}
+		tolerance := emission * time.Duration(l.burst)
+		ahead, allowed, err := l.store.(store.GCRAStore).UpdateTAT(r.Context(), key, emission, tolerance)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		// Each emission interval the TAT is ahead of now uses up one request of the
+		// burst; reset is when the TAT is reached; a denied request conforms once the
+		// TAT is within tolerance of the next arrival.
+		untilConform := ahead + emission - tolerance
+		return max(0, int64((tolerance-ahead)/emission)), now.Add(ahead).Unix(), max(1, int(math.Ceil(untilConform.Seconds()))), !allowed, nil
+	}
+
+	if l.algorithm == RateLimitSlidingWindow {
+		count, oldest, err := l.store.(store.SlidingWindowStore).IncrementSliding(r.Context(), key, limit, window, now)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		// The next slot frees when the oldest logged request leaves the window.
+		freed := oldest.Add(window)
+		return max(0, limit-count), freed.Unix(), max(1, int(math.Ceil(freed.Sub(now).Seconds()))), count > limit, nil
+	}
+
+	if ws, ok := l.store.(store.WindowResetStore); ok {
+		// The store fixes the reset time when the window starts, so every request
+		// in the window reports the same RateLimit-Reset.
+		count, resetAt, err := ws.IncrementWithReset(r.Context(), key, window)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		return max(0, limit-count), resetAt.Unix(), max(0, int(resetAt.Sub(now).Seconds())), count > limit, nil
+	}
+
+	count, ttl, err := l.store.Increment(r.Context(), key, window)
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+	return max(0, limit-count), now.Add(ttl).Unix(), int(ttl.Seconds()), count > limit, nil
+}
+
+// rateLimitStatus is the rate limit state reported in RateLimit-* headers.
+type rateLimitStatus struct {
+	limit     int64
+	remaining int64
+	reset     int64
+}
+
+// tighterThan reports whether s is more restrictive than other: fewer remaining
+// requests, or the same remaining with a later reset.
+func (s rateLimitStatus) tighterThan(other rateLimitStatus) bool {
+	if s.remaining != other.remaining {
+		return s.remaining < other.remaining
+	}
+	return s.reset > other.reset
+}
+
+// setRateLimitHeaders sets the RateLimit-* headers through recordRateLimit when
+// the Handler wrapper is active, or directly on w.
+func setRateLimitHeaders(w http.ResponseWriter, r *http.Request, useWrapper bool, limit, remaining, reset int64) {
+	if useWrapper {
+		recordRateLimit(r, limit, remaining, reset)
+		return
+	}
+	w.Header().Set("RateLimit-Limit", strconv.FormatInt(limit, 10))
+	w.Header().Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	w.Header().Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+}
+
+// setRetryAfter sets Retry-After to seconds on the wrapper state when active,
+// or directly on w.
+func setRetryAfter(w http.ResponseWriter, r *http.Request, useWrapper bool, seconds int) {
+	if useWrapper {
+		SetHeader(r, "Retry-After", strconv.Itoa(seconds))
+		return
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+// recordRateLimit records a limiter's status on the request state and sets the
+// RateLimit-* headers only if it is the most restrictive seen so far. This lets
+// layered limiters cooperate so clients see the tightest limit rather than
+// whichever limiter ran last.
+func recordRateLimit(r *http.Request, limit, remaining, reset int64) {
+	state := getState(r.Context())
+	if state == nil {
+		return
+	}
+	status := rateLimitStatus{limit: limit, remaining: remaining, reset: reset}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.frozen {
+		state.frozenMutation("SetHeader")
+		return
+	}
+	if state.rateLimit != nil && !status.tighterThan(*state.rateLimit) {
+		return
+	}
+	state.rateLimit = &status
+	if state.headers == nil {
+		state.headers = make(http.Header)
+	}
+	state.headers.Set("RateLimit-Limit", strconv.FormatInt(limit, 10))
+	state.headers.Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	state.headers.Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+}
+
 // maxKeyComponentSize limits individual key components to prevent memory exhaustion
 // from malicious headers or query parameters.
 const maxKeyComponentSize = 256
 
-// buildKey builds the rate limit key from all dimensions.
+// dimensionKeyStrategy is the KeyStrategy built from key dimension options.
+type dimensionKeyStrategy struct {
+	name string
+	dims []rateLimitDimension
+}
+
+// Key builds the rate limit key from all dimensions.
 // Returns (key, missingDimName). If missingDimName is non-empty, a required dimension was missing.
 // Key components are truncated to maxKeyComponentSize to prevent memory exhaustion.
-func (l *RateLimiter) buildKey(r *http.Request) (string, string) {
+func (s dimensionKeyStrategy) Key(r *http.Request) (string, string) {
 	var sb strings.Builder
-	sb.Grow(20 + len(l.keyDims)*30)
+	sb.Grow(20 + len(s.dims)*30)
 	hasContent := false
 
-	if l.name != "" {
-		sb.WriteString(l.name)
+	if s.name != "" {
+		sb.WriteString(s.name)
 		hasContent = true
 	}
 
-	for _, dim := range l.keyDims {
+	for _, dim := range s.dims {
 		part := dim.fn(r)
 		if part == "" {
 			if dim.required {
@@ -380,3 +868,17 @@ func (l *RateLimiter) buildKey(r *http.Request) (string, string) {
 	}
 	return sb.String(), ""
 }
+
+// prefixedKeyStrategy prefixes keys from a custom KeyStrategy with RateLimitWithName.
+type prefixedKeyStrategy struct {
+	prefix   string
+	strategy KeyStrategy
+}
+
+func (s prefixedKeyStrategy) Key(r *http.Request) (string, string) {
+	key, missing := s.strategy.Key(r)
+	if key == "" || missing != "" {
+		return key, missing
+	}
+	return s.prefix + ":" + key, ""
+}
@@ -0,0 +1,131 @@
+package chikit
+
+// Rate limiting delegated to an external quota service.
+// The checker makes the decision; the middleware renders the standard
+// RateLimit-* headers and 429/500 responses.
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+)
+
+// RateLimitDecision is an external quota service's verdict for a request.
+type RateLimitDecision struct {
+	// Allowed reports whether the request may proceed.
+	Allowed bool
+
+	// Limit and Remaining populate RateLimit-Limit and RateLimit-Remaining.
+	// Headers are omitted when Limit is 0.
+	Limit     int64
+	Remaining int64
+
+	// Reset is when the current window resets (RateLimit-Reset).
+	Reset time.Time
+
+	// RetryAfter is sent as Retry-After on denied requests.
+	// If zero, the time until Reset is used.
+	RetryAfter time.Duration
+}
+
+// retryAfter returns RetryAfter, or the time until Reset if it is zero.
+func (d RateLimitDecision) retryAfter() time.Duration {
+	if d.RetryAfter == 0 && !d.Reset.IsZero() {
+		return time.Until(d.Reset)
+	}
+	return d.RetryAfter
+}
+
+// RateLimitChecker asks an external service whether a request is within its limits.
+type RateLimitChecker func(ctx context.Context, r *http.Request) (RateLimitDecision, error)
+
+type externalRateLimitConfig struct {
+	failOpen   bool
+	headerMode RateLimitHeaderMode
+}
+
+// ExternalRateLimitOption configures ExternalRateLimit middleware.
+type ExternalRateLimitOption func(*externalRateLimitConfig)
+
+// ExternalRateLimitWithFailOpen lets requests through when the checker returns an
+// error, favoring availability over enforcement. Default is fail closed (500).
+func ExternalRateLimitWithFailOpen() ExternalRateLimitOption {
+	return func(c *externalRateLimitConfig) {
+		c.failOpen = true
+	}
+}
+
+// ExternalRateLimitWithHeaderMode configures when rate limit headers are included
+// in responses. Default is RateLimitHeadersAlways.
+func ExternalRateLimitWithHeaderMode(mode RateLimitHeaderMode) ExternalRateLimitOption {
+	return func(c *externalRateLimitConfig) {
+		c.headerMode = mode
+	}
+}
+
+// ExternalRateLimit returns middleware that delegates rate limit decisions to checker,
+// for organizations with an existing quota API instead of a Store. Headers and
+// responses match RateLimiter, and with the Handler wrapper active the decision takes
+// part in layered limiter reporting (the most restrictive status wins).
+//
+// Returns 429 (Too Many Requests) when the decision is not allowed.
+// Returns 500 (Internal Server Error) if checker fails, unless ExternalRateLimitWithFailOpen is set.
+//
+// Example:
+//
+//	r.Use(chikit.ExternalRateLimit(func(ctx context.Context, r *http.Request) (chikit.RateLimitDecision, error) {
+//		resp, err := quotaClient.Check(ctx, r.Header.Get("X-API-Key"))
+//		if err != nil {
+//			return chikit.RateLimitDecision{}, err
+//		}
+//		return chikit.RateLimitDecision{
+//			Allowed:   resp.Allowed,
+//			Limit:     resp.Limit,
+//			Remaining: resp.Remaining,
+//			Reset:     resp.ResetAt,
+//		}, nil
+//	}))
+func ExternalRateLimit(checker RateLimitChecker, opts ...ExternalRateLimitOption) func(http.Handler) http.Handler {
+	if checker == nil {
+		panic("ExternalRateLimit: checker must be non-nil")
+	}
+
+	cfg := &externalRateLimitConfig{headerMode: RateLimitHeadersAlways}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			useWrapper := HasState(r.Context())
+
+			decision, err := checker(r.Context(), r)
+			if err != nil {
+				if cfg.failOpen {
+					next.ServeHTTP(w, r)
+					return
+				}
+				respond(w, r, ErrInternal.With("Rate limit check failed"))
+				return
+			}
+
+			shouldSetHeaders := cfg.headerMode == RateLimitHeadersAlways ||
+				(cfg.headerMode == RateLimitHeadersOnLimitExceeded && !decision.Allowed)
+
+			if shouldSetHeaders && decision.Limit > 0 {
+				setRateLimitHeaders(w, r, useWrapper, decision.Limit, max(0, decision.Remaining), decision.Reset.Unix())
+			}
+
+			if decision.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if retryAfter := decision.retryAfter(); shouldSetHeaders && retryAfter > 0 {
+				setRetryAfter(w, r, useWrapper, int(math.Ceil(retryAfter.Seconds())))
+			}
+			respond(w, r, ErrRateLimited)
+		})
+	}
+}
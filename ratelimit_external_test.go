@@ -0,0 +1,97 @@
+package chikit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestExternalRateLimit(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second).Truncate(time.Second)
+
+	tests := []struct {
+		name              string
+		decision          RateLimitDecision
+		expected          int
+		expectedRemaining string
+		expectedRetry     string
+	}{
+		{
+			name:              "allowed",
+			decision:          RateLimitDecision{Allowed: true, Limit: 100, Remaining: 42, Reset: reset},
+			expected:          http.StatusOK,
+			expectedRemaining: "42",
+		},
+		{
+			name:              "denied",
+			decision:          RateLimitDecision{Allowed: false, Limit: 100, Remaining: 0, Reset: reset, RetryAfter: 12 * time.Second},
+			expected:          http.StatusTooManyRequests,
+			expectedRemaining: "0",
+			expectedRetry:     "12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := func(context.Context, *http.Request) (RateLimitDecision, error) {
+				return tt.decision, nil
+			}
+			handler := Handler()(ExternalRateLimit(checker)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+			if rec.Code != tt.expected {
+				t.Fatalf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if rec.Header().Get("RateLimit-Limit") != "100" {
+				t.Errorf("expected RateLimit-Limit 100, got %s", rec.Header().Get("RateLimit-Limit"))
+			}
+			if rec.Header().Get("RateLimit-Remaining") != tt.expectedRemaining {
+				t.Errorf("expected RateLimit-Remaining %s, got %s", tt.expectedRemaining, rec.Header().Get("RateLimit-Remaining"))
+			}
+			if rec.Header().Get("RateLimit-Reset") != strconv.FormatInt(reset.Unix(), 10) {
+				t.Errorf("expected RateLimit-Reset %d, got %s", reset.Unix(), rec.Header().Get("RateLimit-Reset"))
+			}
+			if rec.Header().Get("Retry-After") != tt.expectedRetry {
+				t.Errorf("expected Retry-After %q, got %q", tt.expectedRetry, rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestExternalRateLimit_CheckerError(t *testing.T) {
+	checker := func(context.Context, *http.Request) (RateLimitDecision, error) {
+		return RateLimitDecision{}, errors.New("quota service unavailable")
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ExternalRateLimitOption
+		expected int
+	}{
+		{"fail closed by default", nil, http.StatusInternalServerError},
+		{"fail open", []ExternalRateLimitOption{ExternalRateLimitWithFailOpen()}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ExternalRateLimit(checker, tt.opts...)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
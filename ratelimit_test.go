@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -376,6 +380,34 @@ func TestRateLimitHeaders(t *testing.T) {
 	}
 }
 
+func TestRateLimitHeaders_StableReset(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 5, time.Minute, RateLimitWithIP())
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	var first string
+	for i := range 3 {
+		req := httptest.NewRequest("GET", "/test", http.NoBody)
+		req.RemoteAddr = "192.168.1.1:1234"
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		reset := rr.Header().Get("RateLimit-Reset")
+		if i == 0 {
+			first = reset
+		} else if reset != first {
+			t.Errorf("request %d: expected RateLimit-Reset %s for the whole window, got %s", i+1, first, reset)
+		}
+		if i < 2 {
+			time.Sleep(600 * time.Millisecond)
+		}
+	}
+}
+
 func TestHeaderModes(t *testing.T) {
 	tests := []struct {
 		name                  string
@@ -572,6 +604,53 @@ func TestLayeredLimiters(t *testing.T) {
 	}
 }
 
+func TestLayeredLimiters_ReportsMostRestrictive(t *testing.T) {
+	tests := []struct {
+		name       string
+		strictLast bool
+	}{
+		{"strict inner", true},
+		{"strict outer", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := store.NewMemory()
+			defer st.Close()
+
+			loose := NewRateLimiter(st, 100, time.Minute, RateLimitWithName("loose"), RateLimitWithIP())
+			strict := NewRateLimiter(st, 3, time.Hour, RateLimitWithName("strict"), RateLimitWithIP())
+
+			inner := http.Handler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				SetResponse(r, http.StatusOK, nil)
+			}))
+			var chain http.Handler
+			if tt.strictLast {
+				chain = loose.Handler(strict.Handler(inner))
+			} else {
+				chain = strict.Handler(loose.Handler(inner))
+			}
+			handler := Handler()(chain)
+
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			req.RemoteAddr = "192.168.1.1:1234"
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("RateLimit-Limit"); got != "3" {
+				t.Errorf("expected RateLimit-Limit 3 from strict limiter, got %s", got)
+			}
+			if got := rr.Header().Get("RateLimit-Remaining"); got != "2" {
+				t.Errorf("expected RateLimit-Remaining 2 from strict limiter, got %s", got)
+			}
+			reset, _ := strconv.ParseInt(rr.Header().Get("RateLimit-Reset"), 10, 64)
+			if reset < time.Now().Add(59*time.Minute).Unix() {
+				t.Errorf("expected RateLimit-Reset from strict limiter window, got %d", reset)
+			}
+		})
+	}
+}
+
 func TestConcurrentSameKey(t *testing.T) {
 	t.Parallel()
 
@@ -806,3 +885,665 @@ func TestWithWrapper_RateLimited(t *testing.T) {
 		t.Errorf("expected Retry-After header")
 	}
 }
+
+func TestRateLimiter_Config(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 100, time.Minute,
+		RateLimitWithName("api"),
+		RateLimitWithHeaderMode(RateLimitHeadersOnLimitExceeded),
+		RateLimitWithIP(),
+		RateLimitWithHeaderRequired("X-API-Key"),
+	)
+
+	cfg := limiter.Config()
+	if cfg.Limit != 100 || cfg.Window != time.Minute || cfg.Name != "api" {
+		t.Errorf("expected limit 100, window 1m, name api, got %+v", cfg)
+	}
+	if cfg.HeaderMode != RateLimitHeadersOnLimitExceeded {
+		t.Errorf("expected header mode %d, got %d", RateLimitHeadersOnLimitExceeded, cfg.HeaderMode)
+	}
+	expected := []string{"IP", "header X-API-Key (required)"}
+	if !slices.Equal(cfg.Dimensions, expected) {
+		t.Errorf("expected dimensions %v, got %v", expected, cfg.Dimensions)
+	}
+}
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 3, 3*time.Second,
+		RateLimitWithIP(),
+		RateLimitWithAlgorithm(RateLimitTokenBucket),
+	)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i, expectedRemaining := range []string{"2", "1", "0"} {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected burst up to limit, got %d", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != expectedRemaining {
+			t.Errorf("request %d: expected RateLimit-Remaining %s, got %s", i+1, expectedRemaining, got)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.RemoteAddr = "192.0.2.1:1234"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 once bucket is empty, got %d", rec.Code)
+	}
+	// One token refills per second (3 requests / 3s), not after the full window.
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1 (time to next token), got %s", got)
+	}
+}
+
+func TestRateLimiter_TokenBucketRequiresSupportingStore(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for store without token bucket support")
+		}
+	}()
+	NewRateLimiter(&errorStore{}, 1, time.Second, RateLimitWithIP(), RateLimitWithAlgorithm(RateLimitTokenBucket))
+}
+
+func TestRateLimiter_InstrumentedStoreCapabilities(t *testing.T) {
+	algorithms := []RateLimitOption{
+		RateLimitWithAlgorithm(RateLimitTokenBucket),
+		RateLimitWithAlgorithm(RateLimitSlidingWindow),
+		RateLimitWithGCRA(1),
+	}
+	for i, algo := range algorithms {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("algorithm %d: expected panic for instrumented store without support", i)
+				}
+			}()
+			NewRateLimiter(store.Instrumented(&errorStore{}), 1, time.Second, RateLimitWithIP(), algo)
+		}()
+
+		st := store.Instrumented(store.NewMemory())
+		NewRateLimiter(st, 1, time.Second, RateLimitWithIP(), algo)
+		st.Close()
+	}
+}
+
+func TestRateLimiter_GCRA(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 3, 3*time.Second,
+		RateLimitWithIP(),
+		RateLimitWithGCRA(3),
+	)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i, expectedRemaining := range []string{"2", "1", "0"} {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected burst to pass, got %d", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != expectedRemaining {
+			t.Errorf("request %d: expected RateLimit-Remaining %s, got %s", i+1, expectedRemaining, got)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.RemoteAddr = "192.0.2.1:1234"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 once burst is used, got %d", rec.Code)
+	}
+	// Requests are spaced one emission interval (3s / 3 = 1s) apart.
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1 (one emission interval), got %s", got)
+	}
+
+	if got := limiter.Config().Burst; got != 3 {
+		t.Errorf("expected Config().Burst 3, got %d", got)
+	}
+}
+
+func TestRateLimiter_GCRADefaultBurst(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 10, time.Minute,
+		RateLimitWithIP(),
+		RateLimitWithAlgorithm(RateLimitGCRA),
+	)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i, expectedCode := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != expectedCode {
+			t.Errorf("request %d: expected status %d with burst 1, got %d", i+1, expectedCode, rec.Code)
+		}
+	}
+}
+
+func TestRateLimiter_GCRARequiresSupportingStore(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for store without GCRA support")
+		}
+	}()
+	NewRateLimiter(&errorStore{}, 1, time.Second, RateLimitWithIP(), RateLimitWithGCRA(1))
+}
+
+func TestRateLimiter_SlidingWindow(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 2, 200*time.Millisecond,
+		RateLimitWithIP(),
+		RateLimitWithAlgorithm(RateLimitSlidingWindow),
+	)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := time.Now()
+	for i, expectedRemaining := range []string{"1", "0"} {
+		rec := do()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != expectedRemaining {
+			t.Errorf("request %d: expected RateLimit-Remaining %s, got %s", i+1, expectedRemaining, got)
+		}
+		if i == 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	rec := do()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 with window full, got %d", rec.Code)
+	}
+	reset, _ := strconv.ParseInt(rec.Header().Get("RateLimit-Reset"), 10, 64)
+	if expiry := first.Add(200 * time.Millisecond); reset < expiry.Unix() || reset > expiry.Add(100*time.Millisecond).Unix() {
+		t.Errorf("expected RateLimit-Reset at oldest entry expiry %d, got %d", expiry.Unix(), reset)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After 1, got %s", rec.Header().Get("Retry-After"))
+	}
+
+	// The first request has left the window; the second is still in it.
+	time.Sleep(time.Until(first.Add(220 * time.Millisecond)))
+	if rec := do(); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 after oldest request left the window, got %d", rec.Code)
+	}
+	if rec := do(); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 with second request still in window, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_SlidingWindowRequiresSupportingStore(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for store without sliding window support")
+		}
+	}()
+	NewRateLimiter(&errorStore{}, 1, time.Second, RateLimitWithIP(), RateLimitWithAlgorithm(RateLimitSlidingWindow))
+}
+
+func TestRateLimiter_ResetFor(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 1, time.Minute, RateLimitWithName("api"), RateLimitWithHeaderRequired("X-API-Key"))
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	newRequest := func(apiKey string) *http.Request {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.Header.Set("X-API-Key", apiKey)
+		return req
+	}
+	do := func(apiKey string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(apiKey))
+		return rec.Code
+	}
+
+	do("tenant-a")
+	do("tenant-b")
+	if code := do("tenant-a"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected tenant-a to be limited, got %d", code)
+	}
+
+	if err := limiter.ResetFor(newRequest("tenant-a")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code := do("tenant-a"); code != http.StatusOK {
+		t.Errorf("expected tenant-a to be unblocked after reset, got %d", code)
+	}
+	if code := do("tenant-b"); code != http.StatusTooManyRequests {
+		t.Errorf("expected tenant-b to stay limited, got %d", code)
+	}
+
+	if err := limiter.ResetKey(context.Background(), "api:tenant-b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code := do("tenant-b"); code != http.StatusOK {
+		t.Errorf("expected tenant-b to be unblocked after ResetKey, got %d", code)
+	}
+
+	if err := limiter.ResetFor(httptest.NewRequest("GET", "/", http.NoBody)); err == nil {
+		t.Error("expected error when required dimension is missing")
+	}
+}
+
+func TestRateLimiter_ExceededResponse(t *testing.T) {
+	var gotRetryAfter time.Duration
+	customBody := func(_ *http.Request, retryAfter time.Duration) (int, any) {
+		gotRetryAfter = retryAfter
+		return http.StatusServiceUnavailable, map[string]string{"message": "Slow down"}
+	}
+
+	tests := []struct {
+		name         string
+		wrap         bool
+		fn           func(*http.Request, time.Duration) (int, any)
+		expectedCode int
+		expectedBody string
+	}{
+		{"custom body with wrapper", true, customBody, http.StatusServiceUnavailable, `{"message":"Slow down"}`},
+		{"custom body without wrapper", false, customBody, http.StatusServiceUnavailable, `{"message":"Slow down"}`},
+		{
+			"api error with wrapper", true,
+			func(*http.Request, time.Duration) (int, any) {
+				return http.StatusServiceUnavailable, ErrRateLimited.With("Try later")
+			},
+			http.StatusServiceUnavailable,
+			`{"error":{"type":"rate_limit_error","code":"limit_exceeded","message":"Try later"}}`,
+		},
+		{
+			"api error without wrapper", false,
+			func(*http.Request, time.Duration) (int, any) {
+				return http.StatusTooManyRequests, ErrRateLimited.With("Try later")
+			},
+			http.StatusTooManyRequests,
+			`{"error":{"type":"rate_limit_error","code":"limit_exceeded","message":"Try later"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := store.NewMemory()
+			defer st.Close()
+
+			limiter := NewRateLimiter(st, 1, time.Minute, RateLimitWithIP(), RateLimitWithExceededResponse(tt.fn))
+			var handler http.Handler = limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			if tt.wrap {
+				handler = Handler()(handler)
+			}
+
+			var rec *httptest.ResponseRecorder
+			for range 2 {
+				req := httptest.NewRequest("GET", "/", http.NoBody)
+				req.RemoteAddr = "192.0.2.1:1234"
+				rec = httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+			}
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected Content-Type application/json, got %s", ct)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.expectedBody {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, got)
+			}
+			if rec.Header().Get("Retry-After") == "" {
+				t.Error("expected Retry-After header to be kept")
+			}
+		})
+	}
+
+	if gotRetryAfter <= 0 || gotRetryAfter > time.Minute {
+		t.Errorf("expected hook to receive retry delay within the window, got %v", gotRetryAfter)
+	}
+}
+
+func TestRateLimiter_RetryAfterJitter(t *testing.T) {
+	defer SetRandSource(nil)
+
+	retryAfter := func() int {
+		st := store.NewMemory()
+		defer st.Close()
+
+		limiter := NewRateLimiter(st, 1, time.Minute,
+			RateLimitWithIP(),
+			RateLimitWithRetryAfterJitter(0.5),
+		)
+		handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		var rec *httptest.ResponseRecorder
+		for range 2 {
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", http.NoBody))
+		}
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected status 429, got %d", rec.Code)
+		}
+		seconds, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+		if err != nil {
+			t.Fatalf("invalid Retry-After %q", rec.Header().Get("Retry-After"))
+		}
+		return seconds
+	}
+
+	SetRandSource(rand.NewPCG(1, 2))
+	first := retryAfter()
+	SetRandSource(rand.NewPCG(1, 2))
+	second := retryAfter()
+
+	if first != second {
+		t.Errorf("expected the same Retry-After for the same seed, got %d and %d", first, second)
+	}
+	if first < 59 || first > 90 {
+		t.Errorf("expected Retry-After within [60, 90], got %d", first)
+	}
+}
+
+func TestRateLimiter_Exempt(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 1, time.Minute,
+		RateLimitWithHeaderRequired("X-API-Key"),
+		RateLimitWithExempt(func(r *http.Request) bool {
+			return r.Header.Get("X-Admin") == "true"
+		}),
+	)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(apiKey string, admin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if admin {
+			req.Header.Set("X-Admin", "true")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for range 3 {
+		rec := do("key-1", true)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected exempt request to pass, got %d", rec.Code)
+		}
+		if rec.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("expected no rate limit headers on exempt request, got %q", rec.Header().Get("RateLimit-Limit"))
+		}
+	}
+
+	count, err := st.Get(context.Background(), "key-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected exempt requests not to be counted, got %d", count)
+	}
+
+	if rec := do("", true); rec.Code != http.StatusOK {
+		t.Errorf("expected exempt request without required dimension to pass, got %d", rec.Code)
+	}
+	if rec := do("key-1", false); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("expected first non-exempt request to be counted, got %d remaining %q", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+}
+
+func TestRateLimiter_TierFunc(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 1, time.Minute,
+		RateLimitWithHeader("X-API-Key"),
+		RateLimitWithTierFunc(func(r *http.Request) (string, int, time.Duration) {
+			if r.Header.Get("X-Plan") == "paid" {
+				return "paid", 3, time.Hour
+			}
+			return "", 0, 0
+		}),
+	)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(plan string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.Header.Set("X-API-Key", "key-1")
+		if plan != "" {
+			req.Header.Set("X-Plan", plan)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := range 3 {
+		rec := do("paid")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("paid request %d: expected 200, got %d", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Limit"); got != "3" {
+			t.Errorf("expected RateLimit-Limit 3 for paid tier, got %q", got)
+		}
+	}
+	if rec := do("paid"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 after paid limit, got %d", rec.Code)
+	}
+
+	// Requests without a tier use the base limit and their own counter.
+	rec := do("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected base-limit request to pass, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("RateLimit-Limit"); got != "1" {
+		t.Errorf("expected RateLimit-Limit 1 for base limit, got %q", got)
+	}
+	if rec := do(""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 after base limit, got %d", rec.Code)
+	}
+
+	count, err := st.Get(context.Background(), "tier:paid:key-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("expected tier key to count 4 requests, got %d", count)
+	}
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("X-API-Key", "key-1")
+	req.Header.Set("X-Plan", "paid")
+	if err := limiter.ResetFor(req); err != nil {
+		t.Fatalf("ResetFor failed: %v", err)
+	}
+	if rec := do("paid"); rec.Code != http.StatusOK {
+		t.Errorf("expected paid request to pass after ResetFor, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_SetLimit(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 1, time.Minute, RateLimitWithIP())
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(); rec.Code != http.StatusOK {
+		t.Fatalf("expected first request to pass, got %d", rec.Code)
+	}
+	if rec := do(); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected old limit of 1 to be enforced, got %d", rec.Code)
+	}
+
+	limiter.SetLimit(3, time.Minute)
+
+	rec := do()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected request to pass under new limit, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("RateLimit-Limit"); got != "3" {
+		t.Errorf("expected RateLimit-Limit 3 after SetLimit, got %q", got)
+	}
+	if rec := do(); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected new limit of 3 to be enforced, got %d", rec.Code)
+	}
+
+	if cfg := limiter.Config(); cfg.Limit != 3 || cfg.Window != time.Minute {
+		t.Errorf("expected Config to report 3/1m, got %d/%v", cfg.Limit, cfg.Window)
+	}
+}
+
+func TestRateLimiter_SetLimitConcurrent(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	limiter := NewRateLimiter(st, 1000, time.Minute, RateLimitWithIP())
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			limiter.SetLimit(1000+i, time.Minute)
+		}()
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			req.RemoteAddr = "192.0.2.1:1234"
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRateLimiter_KeyStrategy(t *testing.T) {
+	st := store.NewMemory()
+	defer st.Close()
+
+	strategy := KeyStrategyFunc(func(r *http.Request) (string, string) {
+		sub := r.Header.Get("X-Subject")
+		if sub == "" {
+			return "", "subject claim"
+		}
+		return "sub-" + sub, ""
+	})
+	limiter := NewRateLimiter(st, 1, time.Minute, RateLimitWithName("api"), RateLimitWithKeyStrategy(strategy))
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(sub string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		if sub != "" {
+			req.Header.Set("X-Subject", sub)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("alice"); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec := do("alice"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for repeated subject, got %d", rec.Code)
+	}
+	if rec := do("bob"); rec.Code != http.StatusOK {
+		t.Errorf("expected separate key per subject, got %d", rec.Code)
+	}
+
+	count, err := st.Get(context.Background(), "api:sub-alice")
+	if err != nil || count != 2 {
+		t.Errorf("expected both alice requests under the name-prefixed key, got count=%d err=%v", count, err)
+	}
+
+	rec := do("")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing strategy input, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != "Missing required subject claim\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	if dims := limiter.Config().Dimensions; len(dims) != 1 || dims[0] != "custom key strategy" {
+		t.Errorf("unexpected dimensions %v", dims)
+	}
+}
+
+func TestRateLimiter_KeyStrategyWithDimensionsPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic when combining a key strategy with dimensions")
+		}
+	}()
+	NewRateLimiter(store.NewMemory(), 1, time.Minute,
+		RateLimitWithIP(),
+		RateLimitWithKeyStrategy(KeyStrategyFunc(func(*http.Request) (string, string) { return "k", "" })),
+	)
+}
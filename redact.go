@@ -0,0 +1,186 @@
+package chikit
+
+// Struct-tag driven redaction of sensitive fields.
+// Fields tagged `log:"redact"` are masked before request bodies are logged
+// or, optionally, echoed in responses.
+
+import (
+	"reflect"
+	"sync"
+)
+
+// RedactedValue replaces redacted string fields.
+const RedactedValue = "[REDACTED]"
+
+// redactMaxDepth bounds recursion through nested and self-referential values.
+const redactMaxDepth = 32
+
+// redactTypes caches whether a type contains any redacted field.
+var redactTypes sync.Map // map[reflect.Type]bool
+
+// Redact returns a copy of v with every field tagged `log:"redact"` masked:
+// non-empty strings become RedactedValue and other types are zeroed. Nested structs,
+// pointers, slices, arrays, and maps are walked. v is not modified; values whose
+// type contains no redacted fields are returned as-is.
+//
+// Example:
+//
+//	type LoginRequest struct {
+//		Email    string `json:"email"`
+//		Password string `json:"password" log:"redact"`
+//	}
+//
+//	canonlog.InfoAdd(ctx, "login", chikit.Redact(req)) // password: "[REDACTED]"
+func Redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !typeHasRedact(rv.Type()) {
+		return v
+	}
+	return redactValue(rv, 0).Interface()
+}
+
+// typeHasRedact reports whether values of t can contain a redacted field.
+// Only final results are cached, so concurrent callers never see a partial answer.
+func typeHasRedact(t reflect.Type) bool {
+	if cached, ok := redactTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	has := scanRedact(t, map[reflect.Type]bool{})
+	redactTypes.Store(t, has)
+	return has
+}
+
+// scanRedact reports whether a redacted field is reachable from t. Types in seen
+// have already been scanned in this walk, so recursive types terminate; a redacted
+// field reachable from them is reported when they are first visited.
+func scanRedact(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if cached, ok := redactTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return scanRedact(t.Elem(), seen)
+	case reflect.Interface:
+		return true // dynamic type is only known per value
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.IsExported() && (f.Tag.Get("log") == "redact" || scanRedact(f.Type, seen)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactValue returns a copy of v with redacted fields masked. Values whose type
+// contains no redacted fields, and values nested deeper than redactMaxDepth, are
+// returned as-is.
+func redactValue(v reflect.Value, depth int) reflect.Value {
+	if depth > redactMaxDepth || !typeHasRedact(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		return redactPointer(v, depth)
+	case reflect.Interface:
+		return redactInterface(v, depth)
+	case reflect.Slice:
+		return redactSlice(v, depth)
+	case reflect.Array:
+		return redactArray(v, depth)
+	case reflect.Map:
+		return redactMap(v, depth)
+	case reflect.Struct:
+		return redactStruct(v, depth)
+	}
+	return v
+}
+
+func redactPointer(v reflect.Value, depth int) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+	out := reflect.New(v.Type().Elem())
+	out.Elem().Set(redactValue(v.Elem(), depth+1))
+	return out
+}
+
+func redactInterface(v reflect.Value, depth int) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+	out := reflect.New(v.Type()).Elem()
+	out.Set(redactValue(v.Elem(), depth+1))
+	return out
+}
+
+func redactSlice(v reflect.Value, depth int) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+	out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		out.Index(i).Set(redactValue(v.Index(i), depth+1))
+	}
+	return out
+}
+
+func redactArray(v reflect.Value, depth int) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	for i := 0; i < v.Len(); i++ {
+		out.Index(i).Set(redactValue(v.Index(i), depth+1))
+	}
+	return out
+}
+
+func redactMap(v reflect.Value, depth int) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+	out := reflect.MakeMapWithSize(v.Type(), v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		out.SetMapIndex(iter.Key(), redactValue(iter.Value(), depth+1))
+	}
+	return out
+}
+
+func redactStruct(v reflect.Value, depth int) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	out.Set(v)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		field := out.Field(i)
+		if f.Tag.Get("log") == "redact" {
+			maskField(field)
+			continue
+		}
+		field.Set(redactValue(v.Field(i), depth+1))
+	}
+	return out
+}
+
+// maskField replaces a non-empty string with RedactedValue and zeroes other types.
+func maskField(field reflect.Value) {
+	if field.Kind() != reflect.String {
+		field.SetZero()
+		return
+	}
+	if field.Len() > 0 {
+		field.SetString(RedactedValue)
+	}
+}
@@ -0,0 +1,166 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type redactAddress struct {
+	Street string `json:"street"`
+	Secret string `json:"secret" log:"redact"`
+}
+
+type redactRequest struct {
+	Email     string            `json:"email"`
+	Password  string            `json:"password" log:"redact"`
+	PIN       int               `json:"pin" log:"redact"`
+	Address   *redactAddress    `json:"address"`
+	Addresses []redactAddress   `json:"addresses"`
+	Labels    map[string]string `json:"labels"`
+}
+
+func TestRedact(t *testing.T) {
+	req := redactRequest{
+		Email:     "a@example.com",
+		Password:  "hunter2",
+		PIN:       1234,
+		Address:   &redactAddress{Street: "Main St", Secret: "gate-code"},
+		Addresses: []redactAddress{{Street: "Side St", Secret: "alarm"}},
+		Labels:    map[string]string{"team": "core"},
+	}
+
+	got := Redact(req).(redactRequest)
+
+	if got.Email != "a@example.com" || got.Password != RedactedValue || got.PIN != 0 {
+		t.Errorf("expected top-level redaction, got %+v", got)
+	}
+	if got.Address.Secret != RedactedValue || got.Address.Street != "Main St" {
+		t.Errorf("expected nested pointer redaction, got %+v", got.Address)
+	}
+	if got.Addresses[0].Secret != RedactedValue {
+		t.Errorf("expected slice element redaction, got %+v", got.Addresses[0])
+	}
+	if req.Password != "hunter2" || req.Address.Secret != "gate-code" || req.Addresses[0].Secret != "alarm" {
+		t.Error("expected original value to be unmodified")
+	}
+
+	if got := Redact(&req).(*redactRequest); got == &req || got.Password != RedactedValue {
+		t.Errorf("expected redacted copy through pointer, got %+v", got)
+	}
+
+	untagged := map[string]int{"a": 1}
+	if got := Redact(untagged).(map[string]int); got["a"] != 1 {
+		t.Errorf("expected untagged value unchanged, got %v", got)
+	}
+}
+
+// redactNode references itself before its redacted field.
+type redactNode struct {
+	Next   *redactNode
+	Secret string `log:"redact"`
+}
+
+func TestRedact_RecursiveType(t *testing.T) {
+	got := Redact(redactNode{Secret: "pw1", Next: &redactNode{Secret: "pw2"}}).(redactNode)
+	if got.Secret != RedactedValue || got.Next.Secret != RedactedValue {
+		t.Errorf("expected every node redacted, got %q and %q", got.Secret, got.Next.Secret)
+	}
+
+	if got := Redact(&redactNode{Secret: "pw3"}).(*redactNode); got.Secret != RedactedValue {
+		t.Errorf("expected redaction through pointer, got %q", got.Secret)
+	}
+}
+
+// redactConcurrentNode is only used by TestRedact_ConcurrentFirstUse, so its type is
+// not yet cached when the test starts.
+type redactConcurrentNode struct {
+	Children []*redactConcurrentNode
+	Token    string `log:"redact"`
+}
+
+func TestRedact_ConcurrentFirstUse(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]string, 32)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v := redactConcurrentNode{Token: "secret", Children: []*redactConcurrentNode{{Token: "child"}}}
+			got := Redact(v).(redactConcurrentNode)
+			results[i] = got.Token + "," + got.Children[0].Token
+		}()
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		if got != RedactedValue+","+RedactedValue {
+			t.Errorf("goroutine %d: expected both tokens redacted, got %q", i, got)
+		}
+	}
+}
+
+func TestBindWithCanonlogBody(t *testing.T) {
+	buf := captureCanonlog(t)
+
+	handler := Handler(WithCanonlog())(Binder(BindWithCanonlogBody())(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var req redactRequest
+		if !JSON(r, &req) {
+			return
+		}
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email": "a@example.com", "password": "hunter2"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatalf("expected password to be redacted from log, got %s", buf.String())
+	}
+	entry := decodeCanonlog(t, buf)
+	body, _ := entry["request_body"].(map[string]any)
+	if body["password"] != RedactedValue || body["email"] != "a@example.com" {
+		t.Errorf("expected redacted request_body in log, got %v", entry["request_body"])
+	}
+}
+
+func TestWithResponseRedaction(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []HandlerOption
+		redacted bool
+	}{
+		{"enabled", []HandlerOption{WithResponseRedaction()}, true},
+		{"disabled by default", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				var req redactRequest
+				if !JSON(r, &req) {
+					return
+				}
+				SetResponse(r, http.StatusOK, req)
+			}))
+
+			req := httptest.NewRequest("POST", "/echo", strings.NewReader(`{"email": "a@example.com", "password": "hunter2"}`))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			var resp redactRequest
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if tt.redacted && resp.Password != RedactedValue {
+				t.Errorf("expected password redacted, got %q", resp.Password)
+			}
+			if !tt.redacted && resp.Password != "hunter2" {
+				t.Errorf("expected password echoed, got %q", resp.Password)
+			}
+		})
+	}
+}
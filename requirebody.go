@@ -0,0 +1,55 @@
+package chikit
+
+// Request body presence checks.
+// Rejects bodyless POST, PUT, and PATCH requests up front with a clear message
+// instead of letting them fail later as a cryptic decode error.
+
+import (
+	"net/http"
+	"slices"
+)
+
+type requireBodyConfig struct {
+	methods []string
+}
+
+// RequireBodyOption configures RequireBody middleware.
+type RequireBodyOption func(*requireBodyConfig)
+
+// RequireBodyWithExcludedMethods exempts methods (e.g., http.MethodPatch for
+// endpoints where an empty PATCH is valid) from the check.
+func RequireBodyWithExcludedMethods(methods ...string) RequireBodyOption {
+	return func(c *requireBodyConfig) {
+		c.methods = slices.DeleteFunc(c.methods, func(m string) bool {
+			return slices.Contains(methods, m)
+		})
+	}
+}
+
+// RequireBody returns middleware that rejects POST, PUT, and PATCH requests with
+// an empty body (Content-Length: 0 or no Content-Length and no chunked body).
+// Chunked requests pass, since their length is not known up front.
+//
+// Returns 400 (Bad Request) with "Request body required" before the handler runs.
+//
+// Example:
+//
+//	r.Use(chikit.RequireBody())
+func RequireBody(opts ...RequireBodyOption) func(http.Handler) http.Handler {
+	cfg := &requireBodyConfig{
+		methods: []string{http.MethodPost, http.MethodPut, http.MethodPatch},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength == 0 && slices.Contains(cfg.methods, r.Method) {
+				respond(w, r, ErrBadRequest.With("Request body required"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package chikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		chunked    bool
+		opts       []RequireBodyOption
+		wantStatus int
+	}{
+		{"bodyless POST", http.MethodPost, "", false, nil, http.StatusBadRequest},
+		{"bodyless PUT", http.MethodPut, "", false, nil, http.StatusBadRequest},
+		{"bodyless PATCH", http.MethodPatch, "", false, nil, http.StatusBadRequest},
+		{"POST with body", http.MethodPost, `{"name": "Ada"}`, false, nil, http.StatusOK},
+		{"chunked POST", http.MethodPost, `{"name": "Ada"}`, true, nil, http.StatusOK},
+		{"bodyless GET", http.MethodGet, "", false, nil, http.StatusOK},
+		{"bodyless DELETE", http.MethodDelete, "", false, nil, http.StatusOK},
+		{"excluded PATCH", http.MethodPatch, "", false, []RequireBodyOption{RequireBodyWithExcludedMethods(http.MethodPatch)}, http.StatusOK},
+		{"POST with PATCH excluded", http.MethodPost, "", false, []RequireBodyOption{RequireBodyWithExcludedMethods(http.MethodPatch)}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			h := Handler()(RequireBody(tt.opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				called = true
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("expected handler called=%v, got %v", tt.wantStatus == http.StatusOK, called)
+			}
+			if tt.wantStatus != http.StatusBadRequest {
+				return
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Error.Message != "Request body required" {
+				t.Errorf("expected message 'Request body required', got %q", resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestRequireBody_WithoutWrapper(t *testing.T) {
+	h := RequireBody()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", http.NoBody))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
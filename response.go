@@ -1,10 +1,15 @@
 package chikit
 
-import "net/http"
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+)
 
 // SetError sets an error response in the request context.
 // If wrapper middleware is not present (state is nil), this is a no-op.
-// If state is frozen (response already written), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
 // Use HasState() to check if wrapper middleware is active.
 func SetError(r *http.Request, err *APIError) {
 	state := getState(r.Context())
@@ -14,14 +19,26 @@ func SetError(r *http.Request, err *APIError) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 	if state.frozen {
+		state.frozenMutation("SetError")
 		return
 	}
 	state.err = err
 }
 
+// respond renders err for built-in middleware: through SetError when wrapper
+// middleware is present, so it is written as a JSON error envelope, or otherwise as
+// plain text with http.Error using err's message and status.
+func respond(w http.ResponseWriter, r *http.Request, err *APIError) {
+	if HasState(r.Context()) {
+		SetError(r, err)
+		return
+	}
+	http.Error(w, err.Message, err.Status)
+}
+
 // SetResponse sets a success response in the request context.
 // If wrapper middleware is not present (state is nil), this is a no-op.
-// If state is frozen (response already written), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
 // Use HasState() to check if wrapper middleware is active.
 func SetResponse(r *http.Request, status int, body any) {
 	state := getState(r.Context())
@@ -31,15 +48,204 @@ func SetResponse(r *http.Request, status int, body any) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 	if state.frozen {
+		state.frozenMutation("SetResponse")
 		return
 	}
 	state.status = status
 	state.body = body
+	state.stream = nil
+}
+
+// SetStream sets a streaming success response, for Server-Sent Events or large
+// downloads that must not be buffered. When the handler returns, the status and all
+// headers set via SetHeader, AddHeader, and SetCookie are sent first, then write is
+// called with the http.ResponseWriter as w; use http.NewResponseController(w) to
+// flush. Set Content-Type explicitly with SetHeader.
+//
+// An error set via SetError takes precedence and is written instead. Errors returned
+// by write and panics inside it are logged with canonical logging; the status has
+// already been sent by then, so they cannot change the response. WithTimeout only
+// bounds the handler: once streaming starts, write should stop when r.Context() is
+// done. SetStream replaces a body set by SetResponse, and vice versa. Only the first
+// SetStream call takes effect; later calls, including concurrent ones, are no-ops.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	chikit.SetHeader(r, "Content-Type", "text/event-stream")
+//	chikit.SetStream(r, http.StatusOK, func(w io.Writer) error {
+//		for event := range events {
+//			if _, err := fmt.Fprintf(w, "data: %s\n\n", event); err != nil {
+//				return err
+//			}
+//			http.NewResponseController(w.(http.ResponseWriter)).Flush()
+//		}
+//		return nil
+//	})
+func SetStream(r *http.Request, status int, write func(w io.Writer) error) {
+	state := getState(r.Context())
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.frozen {
+		state.frozenMutation("SetStream")
+		return
+	}
+	if state.stream != nil {
+		return
+	}
+	state.status = status
+	state.body = nil
+	state.stream = write
+}
+
+// SetJSONResponse sets a success response from already-encoded JSON (e.g., from a
+// cache or an upstream service). raw is written verbatim with Content-Type
+// application/json instead of being re-encoded; the caller is responsible for it
+// being valid JSON. An empty raw writes the status with no body.
+// SetResponse with a json.RawMessage body behaves the same way.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	if cached, ok := cache.Get(key); ok {
+//		chikit.SetJSONResponse(r, http.StatusOK, cached)
+//		return
+//	}
+func SetJSONResponse(r *http.Request, status int, raw json.RawMessage) {
+	SetResponse(r, status, raw)
+}
+
+// Deleted sets the response for a successful DELETE: 204 (No Content) when body is
+// nil, or 200 with body when the handler returns the deleted resource.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	if err := db.DeleteUser(ctx, id); err != nil { ... }
+//	chikit.Deleted(r, nil)
+func Deleted(r *http.Request, body any) {
+	if body == nil {
+		SetResponse(r, http.StatusNoContent, nil)
+		return
+	}
+	SetResponse(r, http.StatusOK, body)
+}
+
+// metaResponse is the body written by SetResponseWithMeta.
+type metaResponse struct {
+	Data     any            `json:"data"`
+	Meta     map[string]any `json:"meta"`
+	Warnings []FieldError   `json:"warnings,omitempty"`
+}
+
+// warningResponse wraps a success body when warnings were added.
+type warningResponse struct {
+	Data     any          `json:"data"`
+	Warnings []FieldError `json:"warnings"`
+}
+
+// dataResponse is the {"data": ...} envelope written by WithEnvelope.
+type dataResponse struct {
+	XMLName  xml.Name     `json:"-" xml:"response"`
+	Data     any          `json:"data" xml:"data"`
+	Warnings []FieldError `json:"warnings,omitempty" xml:"warnings>warning,omitempty"`
+}
+
+// withEnvelope wraps a success body as {"data": ...} for WithEnvelope. Empty bodies,
+// bodies that already carry a data field, multi-status results, and non-JSON bodies
+// from Adapt are returned unchanged.
+func withEnvelope(body any) any {
+	switch b := body.(type) {
+	case nil, rawResponse, metaResponse, dataResponse, multiStatusResponse:
+		return body
+	case json.RawMessage:
+		if len(b) == 0 {
+			return body
+		}
+	}
+	return dataResponse{Data: body}
+}
+
+// withWarnings attaches warnings to a success body. Bodies from SetResponseWithMeta
+// gain a warnings field; other JSON bodies are wrapped as {"data": ..., "warnings": [...]}.
+// Empty bodies and non-JSON bodies from Adapt are returned unchanged.
+func withWarnings(body any, warnings []FieldError) any {
+	if len(warnings) == 0 || body == nil {
+		return body
+	}
+	switch b := body.(type) {
+	case rawResponse:
+		return body
+	case json.RawMessage:
+		if len(b) == 0 {
+			return body
+		}
+	case metaResponse:
+		b.Warnings = warnings
+		return b
+	case dataResponse:
+		b.Warnings = warnings
+		return b
+	}
+	return warningResponse{Data: body, Warnings: warnings}
+}
+
+// SetResponseWithMeta sets a success response that carries metadata in the body.
+// The body is written as {"data": data, "meta": meta}, for clients that prefer
+// pagination totals or rate limit information in the body rather than headers.
+// A nil meta is written as an empty object.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	chikit.SetResponseWithMeta(r, http.StatusOK, users, map[string]any{
+//		"total": total,
+//		"page":  page,
+//	})
+func SetResponseWithMeta(r *http.Request, status int, data any, meta map[string]any) {
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	SetResponse(r, status, metaResponse{Data: data, Meta: meta})
+}
+
+// AddWarning adds a non-fatal warning to a success response (e.g., a deprecated
+// field was used or a value was coerced). Warnings do not change the status.
+// When warnings are present, the body is written as {"data": body, "warnings": [...]}
+// (or with a "warnings" field added for SetResponseWithMeta). Warnings are not
+// written for error responses or responses without a body.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	if req.LegacyName != "" {
+//		chikit.AddWarning(r, "legacy_name", "deprecated", "use name instead")
+//	}
+func AddWarning(r *http.Request, param, code, message string) {
+	state := getState(r.Context())
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.frozen {
+		state.frozenMutation("AddWarning")
+		return
+	}
+	state.warnings = append(state.warnings, FieldError{Param: param, Code: code, Message: message})
 }
 
 // SetHeader sets a response header in the request context.
 // If wrapper middleware is not present (state is nil), this is a no-op.
-// If state is frozen (response already written), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
 // Use HasState() to check if wrapper middleware is active.
 func SetHeader(r *http.Request, key, value string) {
 	state := getState(r.Context())
@@ -49,6 +255,7 @@ func SetHeader(r *http.Request, key, value string) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 	if state.frozen {
+		state.frozenMutation("SetHeader")
 		return
 	}
 	if state.headers == nil {
@@ -57,9 +264,65 @@ func SetHeader(r *http.Request, key, value string) {
 	state.headers.Set(key, value)
 }
 
+// SetCookie adds a Set-Cookie header to the response, like http.SetCookie.
+// Unlike SetHeader, each call adds a cookie, so several can be set per response.
+// Cookies that http.SetCookie considers invalid are dropped when the response is written.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	chikit.SetCookie(r, &http.Cookie{
+//		Name:     "session",
+//		Value:    token,
+//		Path:     "/",
+//		HttpOnly: true,
+//		Secure:   true,
+//		SameSite: http.SameSiteLaxMode,
+//	})
+func SetCookie(r *http.Request, c *http.Cookie) {
+	state := getState(r.Context())
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.frozen {
+		state.frozenMutation("SetCookie")
+		return
+	}
+	state.cookies = append(state.cookies, c)
+}
+
+// SetWeakETagLazy marks the response to carry a weak ETag (W/"<sha256>") computed
+// from the encoded body, but only when the request is a GET or HEAD with an
+// If-None-Match header, so responses nobody revalidates skip the hash. A matching
+// If-None-Match gets 304 Not Modified. With WithETag, the weak ETag is computed for
+// every response instead. An ETag set with SetHeader takes precedence.
+// If wrapper middleware is not present (state is nil), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
+//
+// Example:
+//
+//	chikit.SetWeakETagLazy(r)
+//	chikit.SetResponse(r, http.StatusOK, report)
+func SetWeakETagLazy(r *http.Request) {
+	state := getState(r.Context())
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.frozen {
+		state.frozenMutation("SetWeakETagLazy")
+		return
+	}
+	state.lazyETag = true
+}
+
 // AddHeader adds a response header value in the request context.
 // If wrapper middleware is not present (state is nil), this is a no-op.
-// If state is frozen (response already written), this is a no-op.
+// If state is frozen (response already written), this is a no-op (panics in strict mode).
 // Use HasState() to check if wrapper middleware is active.
 func AddHeader(r *http.Request, key, value string) {
 	state := getState(r.Context())
@@ -69,6 +332,7 @@ func AddHeader(r *http.Request, key, value string) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 	if state.frozen {
+		state.frozenMutation("AddHeader")
 		return
 	}
 	if state.headers == nil {
@@ -0,0 +1,108 @@
+package chikit
+
+// Route pattern access for handlers.
+// Exposes the chi route pattern a request matched (e.g., "/users/{id}") for
+// building self-links or labeling metrics.
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RoutePattern returns the chi route pattern the request matched (e.g., "/users/{id}"),
+// including mount prefixes. Returns false outside a chi router or when no route matched.
+//
+// Handler captures the request's routing position when the request enters it and
+// resolves the pattern on first call, so middleware registered after Handler can
+// read the full pattern before chi finishes routing. Without Handler, the pattern
+// chi has matched so far is returned, which is complete inside the route handler.
+//
+// Example:
+//
+//	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+//		pattern, _ := chikit.RoutePattern(r.Context()) // "/users/{id}"
+//		requestsByRoute.WithLabelValues(pattern).Inc()
+//	})
+func RoutePattern(ctx context.Context) (string, bool) {
+	if state := getState(ctx); state != nil {
+		if pattern := state.routePattern(); pattern != "" {
+			return pattern, true
+		}
+	}
+	if rctx := chi.RouteContext(ctx); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// routeLookup holds what is needed to resolve a request's route pattern, captured
+// before chi finishes routing so the lookup itself can be deferred until needed.
+type routeLookup struct {
+	routes chi.Routes
+	method string
+	path   string
+	prefix string
+}
+
+// newRouteLookup captures the router, method, remaining routing path, and mount
+// prefix of r at its current point in chi's routing.
+func newRouteLookup(r *http.Request) routeLookup {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return routeLookup{}
+	}
+	return routeLookup{
+		routes: rctx.Routes,
+		method: r.Method,
+		path:   routingPath(r, rctx),
+		prefix: strings.TrimSuffix(rctx.RoutePattern(), "/*"),
+	}
+}
+
+// find returns the full route pattern, or "" if there is no router or no route
+// matches.
+func (l routeLookup) find() string {
+	if l.routes == nil {
+		return ""
+	}
+	pattern := l.routes.Find(chi.NewRouteContext(), l.method, l.path)
+	if pattern == "" {
+		return ""
+	}
+	return l.prefix + pattern
+}
+
+// findRoutePattern resolves the chi route pattern the request will match.
+// Returns "" outside a chi router or when no route matches.
+func findRoutePattern(r *http.Request) string {
+	return newRouteLookup(r).find()
+}
+
+// routePattern returns the route pattern captured when Handler started, resolving
+// it on first use.
+func (s *State) routePattern() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.routeResolved {
+		s.route = s.routeLookup.find()
+		s.routeResolved = true
+	}
+	return s.route
+}
+
+// routingPath returns the path chi routes the request by: the path remaining below
+// the current mount point, or the full URL path at the top-level router.
+func routingPath(r *http.Request, rctx *chi.Context) string {
+	if rctx != nil && rctx.RoutePath != "" {
+		return rctx.RoutePath
+	}
+	if r.URL.RawPath != "" {
+		return r.URL.RawPath
+	}
+	return r.URL.Path
+}
@@ -0,0 +1,62 @@
+package chikit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRoutePattern(t *testing.T) {
+	var middlewarePattern, handlerPattern string
+	var middlewareOK, handlerOK bool
+
+	r := chi.NewRouter()
+	r.Use(Handler())
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			middlewarePattern, middlewareOK = RoutePattern(r.Context())
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/users/{id}", func(_ http.ResponseWriter, r *http.Request) {
+			handlerPattern, handlerOK = RoutePattern(r.Context())
+			SetResponse(r, http.StatusOK, nil)
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/api/users/123", http.NoBody)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if !handlerOK || handlerPattern != "/api/users/{id}" {
+		t.Errorf("expected handler to read /api/users/{id}, got %q (ok=%v)", handlerPattern, handlerOK)
+	}
+	if !middlewareOK || middlewarePattern != "/api/users/{id}" {
+		t.Errorf("expected middleware to read /api/users/{id} before routing, got %q (ok=%v)", middlewarePattern, middlewareOK)
+	}
+}
+
+func TestRoutePattern_WithoutHandler(t *testing.T) {
+	var pattern string
+	var ok bool
+
+	r := chi.NewRouter()
+	r.Get("/users/{id}", func(_ http.ResponseWriter, r *http.Request) {
+		pattern, ok = RoutePattern(r.Context())
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/123", http.NoBody))
+
+	if !ok || pattern != "/users/{id}" {
+		t.Errorf("expected /users/{id}, got %q (ok=%v)", pattern, ok)
+	}
+}
+
+func TestRoutePattern_NoRouter(t *testing.T) {
+	if pattern, ok := RoutePattern(context.Background()); ok || pattern != "" {
+		t.Errorf("expected no pattern outside a router, got %q (ok=%v)", pattern, ok)
+	}
+}
@@ -0,0 +1,127 @@
+package chikit
+
+// JSON Schema validation middleware for schema-first endpoints.
+//
+// Validates the raw request body against a JSON Schema before the handler runs,
+// for endpoints that don't bind into a Go struct.
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const schemaResourceURL = "chikit://schema.json"
+
+var schemaPrinter = message.NewPrinter(language.English)
+
+// JSONSchema returns middleware that validates the request body against a JSON Schema.
+// The schema is compiled once when the middleware is created; an invalid schema panics.
+//
+// Returns 400 (Bad Request) if the body is not valid JSON, or a validation_error with
+// one FieldError per schema violation. Field params are dot-separated instance paths
+// (e.g., "address.zip", "items.0.sku"). The validation status follows
+// BindWithValidationStatus when Binder is active. Returns 413 if MaxBodySize is
+// active and the body exceeds the limit.
+//
+// The body is restored after validation so the handler can decode it as usual:
+//
+//	r.With(chikit.JSONSchema(userSchema)).Post("/users", func(w http.ResponseWriter, r *http.Request) {
+//		var req map[string]any
+//		json.NewDecoder(r.Body).Decode(&req)
+//	})
+func JSONSchema(schema []byte) func(http.Handler) http.Handler {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		panic("JSONSchema: invalid schema JSON: " + err.Error())
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(schemaResourceURL, doc); err != nil {
+		panic("JSONSchema: " + err.Error())
+	}
+	sch, err := compiler.Compile(schemaResourceURL)
+	if err != nil {
+		panic("JSONSchema: " + err.Error())
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					respond(w, r, ErrPayloadTooLarge.With("Request body too large"))
+					return
+				}
+				respond(w, r, ErrBadRequest.With("Failed to read request body"))
+				return
+			}
+
+			inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+			if err != nil {
+				respond(w, r, ErrBadRequest.With("Invalid JSON request body"))
+				return
+			}
+
+			if err := sch.Validate(inst); err != nil {
+				var verr *jsonschema.ValidationError
+				if !errors.As(err, &verr) {
+					respond(w, r, ErrInternal.With("Schema validation failed"))
+					return
+				}
+				respond(w, r, newBindValidationError(getBindConfig(r.Context()), schemaFieldErrors(verr)))
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// schemaFieldErrors flattens a validation error tree into field errors.
+// Only leaf errors are reported since intermediate nodes merely group their causes.
+func schemaFieldErrors(verr *jsonschema.ValidationError) []FieldError {
+	var result []FieldError
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, c := range e.Causes {
+				walk(c)
+			}
+			return
+		}
+		if req, ok := e.ErrorKind.(*kind.Required); ok {
+			for _, missing := range req.Missing {
+				result = append(result, FieldError{
+					Param:   schemaParam(append(e.InstanceLocation[:len(e.InstanceLocation):len(e.InstanceLocation)], missing)),
+					Code:    "required",
+					Message: "required",
+				})
+			}
+			return
+		}
+		code := "schema"
+		if path := e.ErrorKind.KeywordPath(); len(path) > 0 {
+			code = path[len(path)-1]
+		}
+		result = append(result, FieldError{
+			Param:   schemaParam(e.InstanceLocation),
+			Code:    code,
+			Message: e.ErrorKind.LocalizedString(schemaPrinter),
+		})
+	}
+	walk(verr)
+	return result
+}
+
+func schemaParam(location []string) string {
+	return strings.Join(location, ".")
+}
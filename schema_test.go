@@ -0,0 +1,151 @@
+package chikit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testUserSchema = []byte(`{
+	"type": "object",
+	"required": ["name", "address"],
+	"properties": {
+		"name": {"type": "string", "minLength": 2},
+		"age": {"type": "integer", "minimum": 0},
+		"address": {
+			"type": "object",
+			"required": ["zip"],
+			"properties": {
+				"zip": {"type": "string", "pattern": "^[0-9]{5}$"}
+			}
+		}
+	}
+}`)
+
+func TestJSONSchema_ConformingBody(t *testing.T) {
+	body := `{"name":"Alice","age":30,"address":{"zip":"12345"}}`
+
+	var handlerBody string
+	handler := Handler()(JSONSchema(testUserSchema)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerBody = string(b)
+		SetResponse(r, http.StatusOK, map[string]string{"status": "ok"})
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if handlerBody != body {
+		t.Errorf("expected handler to receive original body, got %q", handlerBody)
+	}
+}
+
+func TestJSONSchema_NonConformingBody(t *testing.T) {
+	body := `{"name":"A","age":-1,"address":{"zip":"abc"}}`
+
+	handlerCalled := false
+	handler := Handler()(JSONSchema(testUserSchema)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		handlerCalled = true
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if handlerCalled {
+		t.Error("handler should not be called for non-conforming body")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	var resp map[string]*APIError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	apiErr := resp["error"]
+	if apiErr.Type != "validation_error" {
+		t.Errorf("expected type validation_error, got %s", apiErr.Type)
+	}
+
+	got := make(map[string]string)
+	for _, fe := range apiErr.Errors {
+		got[fe.Param] = fe.Code
+	}
+	expected := map[string]string{
+		"name":        "minLength",
+		"age":         "minimum",
+		"address.zip": "pattern",
+	}
+	for param, code := range expected {
+		if got[param] != code {
+			t.Errorf("expected error code %q for %q, got %q (all errors: %v)", code, param, got[param], apiErr.Errors)
+		}
+	}
+}
+
+func TestJSONSchema_MissingRequired(t *testing.T) {
+	handler := Handler()(JSONSchema(testUserSchema)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {})))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"address":{}}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp map[string]*APIError
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, fe := range resp["error"].Errors {
+		got[fe.Param] = fe.Code
+	}
+	if got["name"] != "required" {
+		t.Errorf("expected required error for name, got %v", resp["error"].Errors)
+	}
+	if got["address.zip"] != "required" {
+		t.Errorf("expected required error for address.zip, got %v", resp["error"].Errors)
+	}
+}
+
+func TestJSONSchema_InvalidJSON(t *testing.T) {
+	handler := Handler()(JSONSchema(testUserSchema)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {})))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{invalid`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestJSONSchema_WithoutWrapper(t *testing.T) {
+	handler := JSONSchema(testUserSchema)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"A"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestJSONSchema_InvalidSchemaPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid schema")
+		}
+	}()
+	JSONSchema([]byte(`{not json`))
+}
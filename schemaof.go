@@ -0,0 +1,272 @@
+package chikit
+
+// JSON Schema generation for bound request structs.
+// Reflects over the same json/query/path/form and validate tags that binding uses,
+// so API docs are generated from the types requests are validated against.
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaFormats maps validate tags to JSON Schema formats.
+var schemaFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+}
+
+// SchemaOf returns a JSON Schema (draft 2020-12, also valid as an OpenAPI 3.1 schema
+// object) describing T as binding sees it. Properties are named by their json tag,
+// falling back to query, path, and form tags like validation errors. Validate tags
+// become constraints:
+//   - required: listed in the object's required properties
+//   - min, max, len, gte, lte: minimum/maximum for numbers, minLength/maxLength for
+//     strings, minItems/maxItems for slices
+//   - gt, lt: exclusiveMinimum/exclusiveMaximum for numbers
+//   - oneof: enum
+//   - email, url, uuid, hostname, ipv4, ipv6: format
+//
+// Rules after dive apply to slice elements. Other tags, including custom validators
+// and rules combined with "|", are not represented. time.Time and types implementing
+// encoding.TextUnmarshaler are strings; *multipart.FileHeader is a binary string.
+//
+// The result can be marshaled into API docs or passed to JSONSchema:
+//
+//	schema := chikit.SchemaOf[CreateUserRequest]()
+//	spec.Components.Schemas["CreateUserRequest"] = schema
+func SchemaOf[T any]() map[string]any {
+	return typeSchema(reflect.TypeFor[T](), map[reflect.Type]bool{})
+}
+
+// typeSchema returns the schema for t. seen holds the struct types being expanded,
+// so recursive types end in a plain object schema instead of looping.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]any {
+	if t == fileHeaderType {
+		return map[string]any{"type": "string", "format": "binary"}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeFor[time.Time]() {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]any{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		properties := map[string]any{}
+		var required []string
+		structProperties(t, seen, properties, &required)
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
+
+// structProperties adds the properties of struct type t to properties, flattening
+// untagged embedded structs as encoding/json does.
+func structProperties(t reflect.Type, seen map[reflect.Type]bool, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, tagged := schemaFieldName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && !tagged {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				structProperties(embedded, seen, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		schema := typeSchema(field.Type, seen)
+		if applyValidateTag(schema, field.Tag.Get("validate")) {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// schemaFieldName returns the property name for field from its json, query, path,
+// or form tag, and whether a tag named it.
+func schemaFieldName(field reflect.StructField) (string, bool) {
+	for _, key := range []string{"json", "query", "path", "form"} {
+		if name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]; name != "" {
+			return name, true
+		}
+	}
+	return field.Name, false
+}
+
+// applyValidateTag adds the constraints in a validate tag to schema and reports
+// whether the field is required. Rules after dive apply to the items schema.
+func applyValidateTag(schema map[string]any, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required, dived := false, false
+	target := schema
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch {
+		case name == "dive":
+			items, ok := target["items"].(map[string]any)
+			if !ok {
+				return required
+			}
+			target, dived = items, true
+		case strings.Contains(rule, "|"):
+			// Alternatives such as "email|url" have no single constraint
+		case name == "required":
+			required = required || !dived
+		default:
+			applyRule(target, name, param)
+		}
+	}
+	return required
+}
+
+// schemaBounds maps JSON Schema types to the keywords their min and max rules set.
+var schemaBounds = map[string][2]string{
+	"integer": {"minimum", "maximum"},
+	"number":  {"minimum", "maximum"},
+	"string":  {"minLength", "maxLength"},
+	"array":   {"minItems", "maxItems"},
+	"object":  {"minProperties", "maxProperties"},
+}
+
+// schemaRule sets the constraint for a validate rule with numeric parameter n.
+// bounds are the min and max keywords for the schema's type.
+type schemaRule func(schema map[string]any, bounds [2]string, numeric bool, n any)
+
+// schemaRules maps validate rules with a numeric parameter to their constraints.
+var schemaRules = map[string]schemaRule{
+	"min": setSchemaMin,
+	"gte": setSchemaMin,
+	"max": setSchemaMax,
+	"lte": setSchemaMax,
+	"len": func(schema map[string]any, bounds [2]string, numeric bool, n any) {
+		setSchemaMin(schema, bounds, numeric, n)
+		setSchemaMax(schema, bounds, numeric, n)
+	},
+	"gt": func(schema map[string]any, _ [2]string, numeric bool, n any) {
+		if numeric {
+			schema["exclusiveMinimum"] = n
+		}
+	},
+	"lt": func(schema map[string]any, _ [2]string, numeric bool, n any) {
+		if numeric {
+			schema["exclusiveMaximum"] = n
+		}
+	},
+}
+
+func setSchemaMin(schema map[string]any, bounds [2]string, _ bool, n any) {
+	schema[bounds[0]] = n
+}
+
+func setSchemaMax(schema map[string]any, bounds [2]string, _ bool, n any) {
+	schema[bounds[1]] = n
+}
+
+// applyRule adds the constraint for one validate rule to schema.
+func applyRule(schema map[string]any, name, param string) {
+	if format, ok := schemaFormats[name]; ok {
+		schema["format"] = format
+		return
+	}
+
+	typ, _ := schema["type"].(string)
+	bounds, ok := schemaBounds[typ]
+	if !ok {
+		return
+	}
+	numeric := typ == "integer" || typ == "number"
+
+	if name == "oneof" {
+		if values, ok := schemaEnum(param, numeric); ok {
+			schema["enum"] = values
+		}
+		return
+	}
+
+	rule, ok := schemaRules[name]
+	if !ok {
+		return
+	}
+	if n, ok := schemaNumber(param); ok {
+		rule(schema, bounds, numeric, n)
+	}
+}
+
+// schemaEnum parses the space-separated values of a oneof rule, as numbers for
+// numeric schemas. Returns false if a numeric value does not parse.
+func schemaEnum(param string, numeric bool) ([]any, bool) {
+	var values []any
+	for v := range strings.FieldsSeq(param) {
+		if !numeric {
+			values = append(values, v)
+			continue
+		}
+		n, ok := schemaNumber(v)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, n)
+	}
+	return values, true
+}
+
+// schemaNumber parses a validate tag parameter as an integer or, failing that, a float.
+func schemaNumber(param string) (any, bool) {
+	if n, err := strconv.ParseInt(param, 10, 64); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(param, 64); err == nil {
+		return f, true
+	}
+	return nil, false
+}
@@ -0,0 +1,128 @@
+package chikit
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"len=5"`
+}
+
+type schemaAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type schemaUser struct {
+	schemaAudit
+	Email    string            `json:"email" validate:"required,email"`
+	Age      int               `json:"age" validate:"required,min=18,max=130"`
+	Role     string            `json:"role" validate:"omitempty,oneof=admin member"`
+	Level    int               `json:"level" validate:"oneof=1 2 3"`
+	Score    float64           `json:"score" validate:"gt=0,lt=1.5"`
+	Tags     []string          `json:"tags" validate:"max=10,dive,min=2"`
+	Address  *schemaAddress    `json:"address"`
+	Labels   map[string]string `json:"labels"`
+	Birthday time.Time         `json:"birthday"`
+	Contact  string            `json:"contact" validate:"email|url"`
+	Internal string            `json:"-"`
+	secret   string
+}
+
+func TestSchemaOf(t *testing.T) {
+	got, err := json.Marshal(SchemaOf[schemaUser]())
+	if err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+
+	want := `{
+		"type": "object",
+		"required": ["email", "age"],
+		"properties": {
+			"created_by": {"type": "string"},
+			"email": {"type": "string", "format": "email"},
+			"age": {"type": "integer", "minimum": 18, "maximum": 130},
+			"role": {"type": "string", "enum": ["admin", "member"]},
+			"level": {"type": "integer", "enum": [1, 2, 3]},
+			"score": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1.5},
+			"tags": {"type": "array", "maxItems": 10, "items": {"type": "string", "minLength": 2}},
+			"address": {
+				"type": "object",
+				"required": ["city"],
+				"properties": {
+					"city": {"type": "string"},
+					"zip": {"type": "string", "minLength": 5, "maxLength": 5}
+				}
+			},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"birthday": {"type": "string", "format": "date-time"},
+			"contact": {"type": "string"}
+		}
+	}`
+	var gotValue, wantValue any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected JSON: %v", err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("unexpected schema:\n%s", got)
+	}
+}
+
+func TestSchemaOf_QueryAndFormTags(t *testing.T) {
+	type uploadRequest struct {
+		File *multipart.FileHeader `form:"file" validate:"required"`
+		Page int                   `query:"page" validate:"omitempty,min=1"`
+	}
+	props := SchemaOf[uploadRequest]()["properties"].(map[string]any)
+
+	if file := props["file"]; !reflect.DeepEqual(file, map[string]any{"type": "string", "format": "binary"}) {
+		t.Errorf("expected binary string for file upload, got %v", file)
+	}
+	if page := props["page"]; !reflect.DeepEqual(page, map[string]any{"type": "integer", "minimum": int64(1)}) {
+		t.Errorf("expected integer page with minimum, got %v", page)
+	}
+}
+
+func TestSchemaOf_RecursiveType(t *testing.T) {
+	type node struct {
+		Name     string  `json:"name"`
+		Children []*node `json:"children"`
+	}
+	schema := SchemaOf[node]()
+	children := schema["properties"].(map[string]any)["children"].(map[string]any)
+	if items := children["items"]; !reflect.DeepEqual(items, map[string]any{"type": "object"}) {
+		t.Errorf("expected recursion to stop at a plain object, got %v", items)
+	}
+}
+
+func TestSchemaOf_WithJSONSchema(t *testing.T) {
+	schema, err := json.Marshal(SchemaOf[CreateUserRequest]())
+	if err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+	h := Handler()(JSONSchema(schema)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	for body, wantStatus := range map[string]int{
+		`{"email": "a@example.com", "age": 30}`: http.StatusOK,
+		`{"email": "a@example.com", "age": 12}`: http.StatusBadRequest,
+		`{"age": 30}`:                           http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		if rec.Code != wantStatus {
+			t.Errorf("%s: expected %d, got %d: %s", body, wantStatus, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package chikit
+
+// Scope-based authorization.
+// Runs after authentication and checks the caller's granted scopes, so routes
+// can require permissions without each handler inspecting token claims.
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// ScopeExtractor returns the scopes granted to the authenticated caller of a request.
+//
+// Thread safety: Extractors are called concurrently from multiple goroutines
+// and must be safe for concurrent use. Avoid shared mutable state.
+type ScopeExtractor func(ctx context.Context) []string
+
+// scopesConfig configures RequireScopesWith.
+type scopesConfig struct {
+	// Extractor returns the granted scopes (default: JWTScopes)
+	Extractor ScopeExtractor
+}
+
+// ScopesOption configures RequireScopesWith.
+type ScopesOption func(*scopesConfig)
+
+// WithScopeExtractor sets the function that reads granted scopes from the request
+// context, e.g. from a principal stored by AuthScheme or roles loaded by an earlier
+// middleware. Defaults to JWTScopes.
+func WithScopeExtractor(extractor ScopeExtractor) ScopesOption {
+	return func(c *scopesConfig) {
+		c.Extractor = extractor
+	}
+}
+
+// RequireScopes returns middleware that allows a request only if the caller has been
+// granted every scope in scopes. Place it after the authentication middleware that
+// stores the scope source in context (JWT by default). Returns 403 (Forbidden) with
+// the missing scopes listed in the message, e.g. "Missing required scopes: users:write".
+// Use RequireScopesWith to read scopes from somewhere other than the JWT.
+//
+// Example:
+//
+//	r.Use(chikit.JWT(keyfunc))
+//	r.With(chikit.RequireScopes("users:write")).Post("/users", createUser)
+func RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return RequireScopesWith()(scopes...)
+}
+
+// RequireScopesWith returns a RequireScopes constructor configured with opts, so a
+// custom extractor can be set once and shared across routes.
+//
+// Example with roles stored by another middleware:
+//
+//	roles := func(ctx context.Context) []string {
+//		user, _ := ctx.Value(userKey).(*User)
+//		return user.Roles
+//	}
+//	requireRoles := chikit.RequireScopesWith(chikit.WithScopeExtractor(roles))
+//	r.With(requireRoles("admin")).Delete("/users/{id}", deleteUser)
+func RequireScopesWith(opts ...ScopesOption) func(scopes ...string) func(http.Handler) http.Handler {
+	config := scopesConfig{
+		Extractor: JWTScopes,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.Extractor == nil {
+		panic("RequireScopesWith: extractor must not be nil")
+	}
+
+	return func(scopes ...string) func(http.Handler) http.Handler {
+		return requireScopes(config.Extractor, scopes)
+	}
+}
+
+func requireScopes(extractor ScopeExtractor, scopes []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			granted := make(map[string]bool)
+			for _, scope := range extractor(r.Context()) {
+				granted[scope] = true
+			}
+
+			var missing []string
+			for _, scope := range scopes {
+				if !granted[scope] {
+					missing = append(missing, scope)
+				}
+			}
+
+			if len(missing) > 0 {
+				respond(w, r, ErrForbidden.With("Missing required scopes: "+strings.Join(missing, ", ")))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// JWTScopes returns the scopes of the token verified by JWT, read from the
+// space-delimited "scope" claim (RFC 8693) or, if absent, the "scp" claim as
+// either a list or a space-delimited string. Returns nil if JWT did not run.
+func JWTScopes(ctx context.Context) []string {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []any:
+		scopes := make([]string, 0, len(scp))
+		for _, s := range scp {
+			if str, ok := s.(string); ok {
+				scopes = append(scopes, str)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
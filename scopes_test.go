@@ -0,0 +1,76 @@
+package chikit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRequireScopes(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      jwt.MapClaims
+		required    []string
+		wantStatus  int
+		wantMessage string
+	}{
+		{"scope claim", jwt.MapClaims{"scope": "users:read users:write"}, []string{"users:write"}, http.StatusOK, ""},
+		{"scp list", jwt.MapClaims{"scp": []any{"users:read", "users:write"}}, []string{"users:read", "users:write"}, http.StatusOK, ""},
+		{"scp string", jwt.MapClaims{"scp": "users:read"}, []string{"users:read"}, http.StatusOK, ""},
+		{"missing scopes listed", jwt.MapClaims{"scope": "users:read"}, []string{"users:read", "users:write", "admin"}, http.StatusForbidden, "Missing required scopes: users:write, admin"},
+		{"no scope claim", jwt.MapClaims{"sub": "user-1"}, []string{"users:read"}, http.StatusForbidden, "Missing required scopes: users:read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handler()(JWT(jwtTestKeyfunc)(RequireScopes(tt.required...)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signTestJWT(t, jwt.SigningMethodHS256, tt.claims))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantMessage == "" {
+				return
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Error.Code != ErrorCodeForbidden || resp.Error.Message != tt.wantMessage {
+				t.Errorf("expected forbidden %q, got %s %q", tt.wantMessage, resp.Error.Code, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestRequireScopes_Extractor(t *testing.T) {
+	type rolesKey struct{}
+	roles := func(ctx context.Context) []string {
+		r, _ := ctx.Value(rolesKey{}).([]string)
+		return r
+	}
+	h := RequireScopesWith(WithScopeExtractor(roles))("admin")(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req.WithContext(context.WithValue(req.Context(), rolesKey{}, []string{"admin"})))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with admin role, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without roles, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,168 @@
+package chikit
+
+// HMAC signature verification for webhook receivers.
+// Verifies a signature over the raw request body before the handler runs,
+// then restores the body so the handler can read it.
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSignatureHeader is the default header carrying the body signature.
+const DefaultSignatureHeader = "X-Signature"
+
+type signatureConfig struct {
+	algorithm       func() hash.Hash
+	header          string
+	prefix          string
+	timestampHeader string
+	tolerance       time.Duration
+}
+
+// SignatureOption configures VerifySignature middleware.
+type SignatureOption func(*signatureConfig)
+
+// SignatureWithAlgorithm sets the HMAC hash function. Default is sha256.New.
+func SignatureWithAlgorithm(fn func() hash.Hash) SignatureOption {
+	return func(c *signatureConfig) {
+		c.algorithm = fn
+	}
+}
+
+// SignatureWithHeader sets the header carrying the hex-encoded signature.
+// Default is DefaultSignatureHeader.
+func SignatureWithHeader(name string) SignatureOption {
+	return func(c *signatureConfig) {
+		c.header = name
+	}
+}
+
+// SignatureWithPrefix strips a scheme prefix from the signature header value
+// (e.g., "sha256=" for GitHub's X-Hub-Signature-256).
+func SignatureWithPrefix(prefix string) SignatureOption {
+	return func(c *signatureConfig) {
+		c.prefix = prefix
+	}
+}
+
+// SignatureWithTimestamp enables timestamped signatures to prevent replay.
+// The header holds the signing time as Unix seconds, the signature covers
+// "<timestamp>.<body>", and requests signed more than tolerance away from now
+// are rejected.
+func SignatureWithTimestamp(header string, tolerance time.Duration) SignatureOption {
+	return func(c *signatureConfig) {
+		c.timestampHeader = header
+		c.tolerance = tolerance
+	}
+}
+
+// VerifySignature returns middleware that verifies an HMAC signature over the raw
+// request body. secret returns the signing key for the request, allowing per-sender
+// secrets (e.g., looked up by a header or route parameter). Signatures are compared
+// in constant time. The body is restored for the handler after verification.
+//
+// Returns 401 (Unauthorized) if:
+//   - The signature (or timestamp, when enabled) header is missing or malformed
+//   - The signature does not match
+//   - The timestamp is outside the tolerance window
+//   - secret returns an empty key
+//
+// Returns 413 (Payload Too Large) if MaxBodySize is exceeded while reading the body.
+//
+// Example:
+//
+//	r.With(chikit.VerifySignature(
+//		func(*http.Request) []byte { return githubSecret },
+//		chikit.SignatureWithHeader("X-Hub-Signature-256"),
+//		chikit.SignatureWithPrefix("sha256="),
+//	)).Post("/webhooks/github", handleGitHubWebhook)
+func VerifySignature(secret func(*http.Request) []byte, opts ...SignatureOption) func(http.Handler) http.Handler {
+	if secret == nil {
+		panic("VerifySignature: secret must be non-nil")
+	}
+
+	cfg := &signatureConfig{
+		algorithm: sha256.New,
+		header:    DefaultSignatureHeader,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					respond(w, r, ErrPayloadTooLarge.With("Request body too large"))
+				} else {
+					respond(w, r, ErrBadRequest.With("Failed to read request body"))
+				}
+				return
+			}
+
+			if apiErr := verifyBodySignature(cfg, r, secret(r), body); apiErr != nil {
+				respond(w, r, apiErr)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func verifyBodySignature(cfg *signatureConfig, r *http.Request, key, body []byte) *APIError {
+	value := r.Header.Get(cfg.header)
+	if value == "" {
+		return ErrUnauthorized.With("Missing signature")
+	}
+	value, ok := strings.CutPrefix(value, cfg.prefix)
+	if !ok {
+		return ErrUnauthorized.With("Invalid signature format")
+	}
+	sig, err := hex.DecodeString(value)
+	if err != nil {
+		return ErrUnauthorized.With("Invalid signature format")
+	}
+	if len(key) == 0 {
+		return ErrUnauthorized.With("Invalid signature")
+	}
+
+	mac := hmac.New(cfg.algorithm, key)
+	if cfg.timestampHeader != "" {
+		ts := r.Header.Get(cfg.timestampHeader)
+		if ts == "" {
+			return ErrUnauthorized.With("Missing signature timestamp")
+		}
+		secs, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return ErrUnauthorized.With("Invalid signature timestamp")
+		}
+		skew := time.Since(time.Unix(secs, 0))
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > cfg.tolerance {
+			return ErrUnauthorized.With("Signature timestamp outside tolerance")
+		}
+		mac.Write([]byte(ts + "."))
+	}
+	mac.Write(body)
+
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrUnauthorized.With("Invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package chikit
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testWebhookSecret = []byte("whsec_test")
+
+func signBody(fn func() hash.Hash, payload string) string {
+	mac := hmac.New(fn, testWebhookSecret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookSecret(*http.Request) []byte {
+	return testWebhookSecret
+}
+
+func TestVerifySignature(t *testing.T) {
+	const body = `{"event":"payment.succeeded"}`
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		expected  int
+	}{
+		{"valid signature", body, signBody(sha256.New, body), http.StatusOK},
+		{"tampered body", `{"event":"payment.refunded"}`, signBody(sha256.New, body), http.StatusUnauthorized},
+		{"missing signature", body, "", http.StatusUnauthorized},
+		{"non-hex signature", body, "not-hex", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			handler := Handler()(VerifySignature(webhookSecret)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				received = string(data)
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set("X-Signature", tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if tt.expected == http.StatusOK && received != tt.body {
+				t.Errorf("expected body to be re-readable downstream, got %q", received)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_HeaderPrefixAndAlgorithm(t *testing.T) {
+	const body = `{"action":"opened"}`
+
+	handler := VerifySignature(webhookSecret,
+		SignatureWithHeader("X-Hub-Signature"),
+		SignatureWithPrefix("sha1="),
+		SignatureWithAlgorithm(sha1.New),
+	)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature", "sha1="+signBody(sha1.New, body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/webhooks", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature", signBody(sha1.New, body))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without prefix, got %d", rec.Code)
+	}
+}
+
+func TestVerifySignature_Timestamp(t *testing.T) {
+	const body = `{"event":"ping"}`
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		signedAt  int64
+		expected  int
+	}{
+		{"within tolerance", now - 60, now - 60, http.StatusOK},
+		{"outside tolerance", now - 600, now - 600, http.StatusUnauthorized},
+		{"timestamp not covered by signature", now, now - 60, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := VerifySignature(webhookSecret,
+				SignatureWithTimestamp("X-Signature-Timestamp", 5*time.Minute),
+			)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			signed := strconv.FormatInt(tt.signedAt, 10) + "." + body
+			req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(body))
+			req.Header.Set("X-Signature", signBody(sha256.New, signed))
+			req.Header.Set("X-Signature-Timestamp", strconv.FormatInt(tt.timestamp, 10))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
@@ -44,6 +44,7 @@ const sloConfigKey sloContextKey = "slo_config"
 type sloConfig struct {
 	tier   SLOTier
 	target time.Duration
+	start  time.Time
 }
 
 // SLO sets a predefined SLO tier in context.
@@ -58,6 +59,7 @@ func SLO(tier SLOTier) func(http.Handler) http.Handler {
 			cfg := &sloConfig{
 				tier:   tier,
 				target: sloTargets[tier],
+				start:  time.Now(),
 			}
 			ctx := context.WithValue(r.Context(), sloConfigKey, cfg)
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -73,6 +75,54 @@ func SLOWithTarget(target time.Duration) func(http.Handler) http.Handler {
 			cfg := &sloConfig{
 				tier:   sloCustom,
 				target: target,
+				start:  time.Now(),
+			}
+			ctx := context.WithValue(r.Context(), sloConfigKey, cfg)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// SLOByRoute sets SLO tiers from a map of chi route patterns, centralizing SLO
+// assignment in one place instead of r.With(chikit.SLO(...)) on every route.
+// Routes not in the map get defaultTier; pass "" to leave them without an SLO.
+// Panics if a tier, or a non-empty defaultTier, is not one of the predefined tiers.
+//
+// Register with r.Use on the chi router. The route pattern is resolved before
+// routing completes, so patterns must match exactly as registered, including
+// mount prefixes (e.g., "/api/users/{id}").
+//
+// Example:
+//
+//	r.Use(chikit.SLOByRoute(map[string]chikit.SLOTier{
+//		"/health":     chikit.SLOCritical,
+//		"/users/{id}": chikit.SLOHighFast,
+//		"/reports":    chikit.SLOHighSlow,
+//	}, chikit.SLOLow))
+func SLOByRoute(routes map[string]SLOTier, defaultTier SLOTier) func(http.Handler) http.Handler {
+	for pattern, tier := range routes {
+		if _, ok := sloTargets[tier]; !ok {
+			panic("SLOByRoute: unknown tier " + string(tier) + " for route " + pattern)
+		}
+	}
+	if _, ok := sloTargets[defaultTier]; !ok && defaultTier != "" {
+		panic("SLOByRoute: unknown default tier " + string(defaultTier))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tier, ok := routes[findRoutePattern(r)]
+			if !ok {
+				tier = defaultTier
+			}
+			if tier == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cfg := &sloConfig{
+				tier:   tier,
+				target: sloTargets[tier],
+				start:  time.Now(),
 			}
 			ctx := context.WithValue(r.Context(), sloConfigKey, cfg)
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -82,6 +132,13 @@ func SLOWithTarget(target time.Duration) func(http.Handler) http.Handler {
 
 // GetSLO retrieves the SLO tier and target from context.
 // Returns the tier, target duration, and true if set; otherwise empty values and false.
+//
+// The tier is set before the handler runs, so handlers can read their own tier,
+// for example to pick a cheaper code path on latency-critical routes:
+//
+//	if tier, _, ok := chikit.GetSLO(r.Context()); ok && tier == chikit.SLOCritical {
+//		results = cache.Approximate(query)
+//	}
 func GetSLO(ctx context.Context) (SLOTier, time.Duration, bool) {
 	cfg, ok := ctx.Value(sloConfigKey).(*sloConfig)
 	if !ok {
@@ -89,3 +146,26 @@ func GetSLO(ctx context.Context) (SLOTier, time.Duration, bool) {
 	}
 	return cfg.tier, cfg.target, true
 }
+
+// SLOBudget returns the latency budget remaining for the current request.
+// The budget is the SLO target minus the time elapsed since Handler started the
+// request, the same duration slo_status is judged on. Without Handler, time is
+// measured from the SLO middleware. Returns 0 once the target has been exceeded.
+// Returns 0 and false if no SLO is set.
+//
+// Example:
+//
+//	if remaining, ok := chikit.SLOBudget(r.Context()); ok && remaining < 20*time.Millisecond {
+//		// Skip optional enrichment to stay within the SLO
+//	}
+func SLOBudget(ctx context.Context) (time.Duration, bool) {
+	cfg, ok := ctx.Value(sloConfigKey).(*sloConfig)
+	if !ok {
+		return 0, false
+	}
+	start := cfg.start
+	if state := getState(ctx); state != nil {
+		start = state.start
+	}
+	return max(0, cfg.target-time.Since(start)), true
+}
@@ -195,6 +195,82 @@ func TestTrack_RouteWithoutSLO(t *testing.T) {
 	}
 }
 
+func TestSLOByRoute(t *testing.T) {
+	tiers := map[string]SLOTier{}
+	record := func(_ http.ResponseWriter, r *http.Request) {
+		tier, _, _ := GetSLO(r.Context())
+		tiers[r.URL.Path] = tier
+	}
+
+	r := chi.NewRouter()
+	r.Use(SLOByRoute(map[string]SLOTier{
+		"/health":         SLOCritical,
+		"/users/{id}":     SLOHighFast,
+		"/api/v1/reports": SLOHighSlow,
+	}, SLOLow))
+	r.Get("/health", record)
+	r.Get("/users/{id}", record)
+	r.Get("/export", record)
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/reports", record)
+	})
+
+	for _, path := range []string{"/health", "/users/123", "/export", "/api/v1/reports"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, http.NoBody))
+	}
+
+	expected := map[string]SLOTier{
+		"/health":         SLOCritical,
+		"/users/123":      SLOHighFast,
+		"/export":         SLOLow,
+		"/api/v1/reports": SLOHighSlow,
+	}
+	for path, tier := range expected {
+		if tiers[path] != tier {
+			t.Errorf("%s: expected tier %s, got %s", path, tier, tiers[path])
+		}
+	}
+}
+
+func TestSLOByRoute_NoDefault(t *testing.T) {
+	var found bool
+
+	r := chi.NewRouter()
+	r.Use(SLOByRoute(map[string]SLOTier{"/health": SLOCritical}, ""))
+	r.Get("/export", func(_ http.ResponseWriter, r *http.Request) {
+		_, _, found = GetSLO(r.Context())
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/export", http.NoBody))
+
+	if found {
+		t.Error("expected no SLO for unlisted route without a default tier")
+	}
+}
+
+func TestSLOByRoute_PanicsOnUnknownTier(t *testing.T) {
+	tests := []struct {
+		name        string
+		routes      map[string]SLOTier
+		defaultTier SLOTier
+	}{
+		{"route tier", map[string]SLOTier{"/users": "fast"}, SLOLow},
+		{"default tier", map[string]SLOTier{"/users": SLOHighFast}, "slow"},
+		{"custom tier", map[string]SLOTier{"/users": sloCustom}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic for unknown tier")
+				}
+			}()
+			SLOByRoute(tt.routes, tt.defaultTier)
+		})
+	}
+}
+
 func TestTierConstants(t *testing.T) {
 	if SLOCritical != "critical" {
 		t.Errorf("expected Critical = 'critical', got %s", SLOCritical)
@@ -209,3 +285,98 @@ func TestTierConstants(t *testing.T) {
 		t.Errorf("expected Low = 'low', got %s", SLOLow)
 	}
 }
+
+func TestSLO_HandlerReadsTierMidRequest(t *testing.T) {
+	var tier SLOTier
+	var found bool
+
+	handler := Handler(WithCanonlog(), WithSLOs())(SLO(SLOCritical)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		tier, _, found = GetSLO(r.Context())
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("GET", "/test", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !found {
+		t.Fatal("expected handler to read SLO tier")
+	}
+	if tier != SLOCritical {
+		t.Errorf("expected tier %s, got %s", SLOCritical, tier)
+	}
+}
+
+func TestSLOBudget_DecreasesWithElapsedTime(t *testing.T) {
+	target := 200 * time.Millisecond
+
+	var before, after time.Duration
+	var ok bool
+
+	handler := SLOWithTarget(target)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		before, ok = SLOBudget(r.Context())
+		time.Sleep(50 * time.Millisecond)
+		after, _ = SLOBudget(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/test", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !ok {
+		t.Fatal("expected budget to be available")
+	}
+	if before > target || before < target-20*time.Millisecond {
+		t.Errorf("expected initial budget near %v, got %v", target, before)
+	}
+	if after > before-50*time.Millisecond {
+		t.Errorf("expected budget to decrease by at least 50ms, before=%v after=%v", before, after)
+	}
+}
+
+func TestSLOBudget_MeasuresFromHandlerStart(t *testing.T) {
+	target := 200 * time.Millisecond
+
+	var remaining time.Duration
+	slowMiddleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := Handler()(slowMiddleware(SLOWithTarget(target)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		remaining, _ = SLOBudget(r.Context())
+	}))))
+
+	req := httptest.NewRequest("GET", "/test", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if remaining > target-50*time.Millisecond {
+		t.Errorf("expected budget to include time spent before the SLO middleware, got %v", remaining)
+	}
+}
+
+func TestSLOBudget_Exhausted(t *testing.T) {
+	var remaining time.Duration
+
+	handler := SLOWithTarget(10 * time.Millisecond)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		remaining, _ = SLOBudget(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/test", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if remaining != 0 {
+		t.Errorf("expected exhausted budget to be 0, got %v", remaining)
+	}
+}
+
+func TestSLOBudget_NoSLO(t *testing.T) {
+	req := httptest.NewRequest("GET", "/test", http.NoBody)
+	remaining, ok := SLOBudget(req.Context())
+	if ok {
+		t.Error("expected ok=false without SLO")
+	}
+	if remaining != 0 {
+		t.Errorf("expected 0 budget, got %v", remaining)
+	}
+}
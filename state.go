@@ -3,23 +3,105 @@ package chikit
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type stateContextKey string
 
 const stateKey stateContextKey = "chikit_state"
 
+// strictMode makes mutations of frozen state panic instead of being ignored.
+var strictMode atomic.Bool
+
+// SetStrictMode enables or disables strict mode. In strict mode, calling SetError,
+// SetResponse, SetStream, SetHeader, AddHeader, SetCookie, SetWeakETagLazy, or
+// AddWarning after the response has been written panics, surfacing ordering bugs
+// (e.g., a goroutine setting a response after the handler returned) that are
+// otherwise silent no-ops. Mutations from handlers that keep running after a
+// WithTimeout 504 are still ignored, since that is expected.
+//
+// Intended for tests and development. Leave disabled in production:
+//
+//	func TestMain(m *testing.M) {
+//		chikit.SetStrictMode(true)
+//		os.Exit(m.Run())
+//	}
+func SetStrictMode(enabled bool) {
+	strictMode.Store(enabled)
+}
+
 // State holds the response state for a request.
 type State struct {
-	mu      sync.Mutex
-	err     *APIError
-	status  int
-	body    any
-	headers http.Header
-	written bool
-	frozen  bool
+	mu       sync.Mutex
+	err      *APIError
+	status   int
+	body     any
+	headers  http.Header
+	written  bool
+	frozen   bool
+	timedOut bool
+	phases   statePhases
+
+	// handlerExited is set when a WithTimeout handler goroutine returns.
+	handlerExited bool
+
+	// accept is the request's Accept header, used to negotiate HTML error pages.
+	accept string
+
+	// routeLookup is captured when Handler starts; RoutePattern resolves it into
+	// route on first use and sets routeResolved.
+	routeLookup   routeLookup
+	route         string
+	routeResolved bool
+
+	// start is when Handler started the request. duration_ms, slo_status, and
+	// SLOBudget all measure from it.
+	start time.Time
+
+	// compressMin and encoding configure WithCompression: the minimum body size to
+	// compress and the coding negotiated from Accept-Encoding ("" for none).
+	compressMin int
+	encoding    string
+
+	// etag enables WithETag; ifNoneMatch is the request's If-None-Match header,
+	// captured for GET and HEAD requests only. lazyETag is set by SetWeakETagLazy.
+	etag        bool
+	ifNoneMatch string
+	lazyETag    bool
+
+	// negotiate enables WithContentNegotiation; xml is set when the Accept header
+	// prefers XML over JSON.
+	negotiate bool
+	xml       bool
+
+	// problem enables WithProblemDetails.
+	problem bool
+
+	// stream, if set by SetStream, writes the body directly to the ResponseWriter
+	// instead of body being encoded.
+	stream func(io.Writer) error
+
+	// cookies are written as Set-Cookie headers, in the order added via SetCookie.
+	cookies []*http.Cookie
+
+	// warnings are non-fatal field warnings added via AddWarning.
+	warnings []FieldError
+
+	// rateLimit is the most restrictive status reported by layered rate limiters.
+	rateLimit *rateLimitStatus
+}
+
+// statePhases records timestamps for the request lifecycle phases logged by canonlog.
+// Zero values mean the phase was not reached.
+type statePhases struct {
+	handlerStart time.Time
+	handlerEnd   time.Time
+	writeStart   time.Time
+	writeEnd     time.Time
 }
 
 // stateSnapshot holds a frozen copy of state for safe reading after freeze.
@@ -27,6 +109,7 @@ type stateSnapshot struct {
 	err     *APIError
 	status  int
 	headers http.Header
+	phases  statePhases
 }
 
 // markWritten attempts to mark the state as written and frozen.
@@ -44,6 +127,15 @@ func (s *State) markWritten() bool {
 	return true
 }
 
+// frozenMutation reports a mutation attempted on frozen state.
+// Panics in strict mode unless the state was frozen by a timeout.
+// Must be called while holding the mutex.
+func (s *State) frozenMutation(op string) {
+	if strictMode.Load() && !s.timedOut {
+		panic("chikit: " + op + " called after response was written")
+	}
+}
+
 // snapshot returns a frozen copy of the current state for safe reading.
 // Must be called while holding the mutex or after state is frozen.
 func (s *State) snapshot() stateSnapshot {
@@ -53,9 +145,52 @@ func (s *State) snapshot() stateSnapshot {
 		err:     s.err,
 		status:  s.status,
 		headers: s.headers,
+		phases:  s.phases,
 	}
 }
 
+// markHandlerStart records when the downstream handler chain starts.
+func (s *State) markHandlerStart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phases.handlerStart = time.Now()
+}
+
+// markHandlerEnd records when the handler phase ends. The first call wins, so a
+// timeout ends the phase even if the handler goroutine keeps running.
+func (s *State) markHandlerEnd() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.phases.handlerEnd.IsZero() {
+		s.phases.handlerEnd = time.Now()
+	}
+}
+
+// markHandlerExited records that a WithTimeout handler goroutine returned, releasing
+// its slot in abandonedHandlerCount if the timeout had already fired.
+func (s *State) markHandlerExited() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlerExited = true
+	if s.timedOut {
+		abandonedHandlerCount.Add(-1)
+	}
+}
+
+// markWriteStart records when writing the response starts.
+func (s *State) markWriteStart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phases.writeStart = time.Now()
+}
+
+// markWriteEnd records when writing the response finishes.
+func (s *State) markWriteEnd() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phases.writeEnd = time.Now()
+}
+
 // HasState returns true if wrapper state exists in the context.
 func HasState(ctx context.Context) bool {
 	return getState(ctx) != nil
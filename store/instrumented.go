@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Observer is called after every store operation with the operation name
+// ("increment", "take_token", "increment_sliding", "update_tat", "get", "reset",
+// or "close"), its duration, and its error (nil on success).
+//
+// Thread safety: Observers are called concurrently from multiple goroutines
+// and must be safe for concurrent use.
+type Observer func(op string, dur time.Duration, err error)
+
+type instrumentConfig struct {
+	observers []Observer
+}
+
+// InstrumentOption configures Instrumented.
+type InstrumentOption func(*instrumentConfig)
+
+// InstrumentWithObserver adds an observer for store operations.
+// Can be given multiple times (e.g., one for metrics, one for tracing).
+func InstrumentWithObserver(fn Observer) InstrumentOption {
+	return func(c *instrumentConfig) {
+		c.observers = append(c.observers, fn)
+	}
+}
+
+// instrumented wraps a Store and reports the latency and error of every call.
+type instrumented struct {
+	inner     Store
+	observers []Observer
+}
+
+// Instrumented wraps inner with a transparent decorator that times every call and
+// reports it to the configured observers. Works with Memory, Redis, or any custom Store.
+//
+// The returned store has the methods of every optional capability; those inner does
+// not implement return errors.ErrUnsupported. Use Supports to check a capability,
+// which looks through the wrapper to inner.
+//
+// Example:
+//
+//	st := store.Instrumented(redisStore, store.InstrumentWithObserver(func(op string, dur time.Duration, err error) {
+//		storeLatency.WithLabelValues(op).Observe(dur.Seconds())
+//		if err != nil {
+//			storeErrors.WithLabelValues(op).Inc()
+//		}
+//	}))
+func Instrumented(inner Store, opts ...InstrumentOption) Store {
+	cfg := &instrumentConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &instrumented{inner: inner, observers: cfg.observers}
+}
+
+// Unwrap returns the wrapped store, for Supports.
+func (s *instrumented) Unwrap() Store {
+	return s.inner
+}
+
+func (s *instrumented) observe(op string, start time.Time, err error) {
+	dur := time.Since(start)
+	for _, fn := range s.observers {
+		fn(op, dur, err)
+	}
+}
+
+// Increment delegates to the inner store and reports the "increment" operation.
+func (s *instrumented) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	start := time.Now()
+	count, ttl, err := s.inner.Increment(ctx, key, window)
+	s.observe("increment", start, err)
+	return count, ttl, err
+}
+
+// IncrementWithReset delegates to the inner store and reports the "increment"
+// operation. If the inner store does not implement WindowResetStore, it falls back
+// to Increment and derives the reset time from the TTL.
+func (s *instrumented) IncrementWithReset(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	ws, ok := s.inner.(WindowResetStore)
+	if !ok {
+		count, ttl, err := s.Increment(ctx, key, window)
+		return count, time.Now().Add(ttl), err
+	}
+	start := time.Now()
+	count, resetAt, err := ws.IncrementWithReset(ctx, key, window)
+	s.observe("increment", start, err)
+	return count, resetAt, err
+}
+
+// TakeToken delegates to the inner store and reports the "take_token" operation.
+// Returns an error wrapping errors.ErrUnsupported if the inner store does not
+// implement TokenBucketStore.
+func (s *instrumented) TakeToken(ctx context.Context, key string, capacity int64, refillInterval time.Duration) (float64, bool, error) {
+	tb, ok := s.inner.(TokenBucketStore)
+	if !ok {
+		return 0, false, fmt.Errorf("store: token bucket %w by %T", errors.ErrUnsupported, s.inner)
+	}
+	start := time.Now()
+	tokens, allowed, err := tb.TakeToken(ctx, key, capacity, refillInterval)
+	s.observe("take_token", start, err)
+	return tokens, allowed, err
+}
+
+// IncrementSliding delegates to the inner store and reports the "increment_sliding"
+// operation. Returns an error wrapping errors.ErrUnsupported if the inner store does
+// not implement SlidingWindowStore.
+func (s *instrumented) IncrementSliding(ctx context.Context, key string, limit int64, window time.Duration, now time.Time) (int64, time.Time, error) {
+	sw, ok := s.inner.(SlidingWindowStore)
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("store: sliding window %w by %T", errors.ErrUnsupported, s.inner)
+	}
+	start := time.Now()
+	count, oldest, err := sw.IncrementSliding(ctx, key, limit, window, now)
+	s.observe("increment_sliding", start, err)
+	return count, oldest, err
+}
+
+// UpdateTAT delegates to the inner store and reports the "update_tat" operation.
+// Returns an error wrapping errors.ErrUnsupported if the inner store does not
+// implement GCRAStore.
+func (s *instrumented) UpdateTAT(ctx context.Context, key string, emission, tolerance time.Duration) (time.Duration, bool, error) {
+	gs, ok := s.inner.(GCRAStore)
+	if !ok {
+		return 0, false, fmt.Errorf("store: GCRA %w by %T", errors.ErrUnsupported, s.inner)
+	}
+	start := time.Now()
+	ahead, allowed, err := gs.UpdateTAT(ctx, key, emission, tolerance)
+	s.observe("update_tat", start, err)
+	return ahead, allowed, err
+}
+
+// Get delegates to the inner store and reports the "get" operation.
+func (s *instrumented) Get(ctx context.Context, key string) (int64, error) {
+	start := time.Now()
+	count, err := s.inner.Get(ctx, key)
+	s.observe("get", start, err)
+	return count, err
+}
+
+// Reset delegates to the inner store and reports the "reset" operation.
+func (s *instrumented) Reset(ctx context.Context, key string) error {
+	start := time.Now()
+	err := s.inner.Reset(ctx, key)
+	s.observe("reset", start, err)
+	return err
+}
+
+// Close delegates to the inner store and reports the "close" operation.
+func (s *instrumented) Close() error {
+	start := time.Now()
+	err := s.inner.Close()
+	s.observe("close", start, err)
+	return err
+}
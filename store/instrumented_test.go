@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errBackend = errors.New("storage backend unavailable")
+
+type errorStore struct{}
+
+func (e *errorStore) Increment(_ context.Context, _ string, _ time.Duration) (int64, time.Duration, error) {
+	return 0, 0, errBackend
+}
+
+func (e *errorStore) Get(_ context.Context, _ string) (int64, error) {
+	return 0, errBackend
+}
+
+func (e *errorStore) Reset(_ context.Context, _ string) error {
+	return errBackend
+}
+
+func (e *errorStore) Close() error {
+	return nil
+}
+
+type observedCall struct {
+	op  string
+	err error
+}
+
+func recordingObserver() (*[]observedCall, InstrumentOption) {
+	var mu sync.Mutex
+	calls := &[]observedCall{}
+	return calls, InstrumentWithObserver(func(op string, _ time.Duration, err error) {
+		mu.Lock()
+		*calls = append(*calls, observedCall{op: op, err: err})
+		mu.Unlock()
+	})
+}
+
+func TestInstrumented_Errors(t *testing.T) {
+	calls, opt := recordingObserver()
+	st := Instrumented(&errorStore{}, opt)
+	ctx := context.Background()
+
+	if _, _, err := st.Increment(ctx, "key", time.Minute); !errors.Is(err, errBackend) {
+		t.Errorf("expected inner error from Increment, got %v", err)
+	}
+	if _, err := st.Get(ctx, "key"); !errors.Is(err, errBackend) {
+		t.Errorf("expected inner error from Get, got %v", err)
+	}
+	if err := st.Reset(ctx, "key"); !errors.Is(err, errBackend) {
+		t.Errorf("expected inner error from Reset, got %v", err)
+	}
+	if err := st.Close(); err != nil {
+		t.Errorf("expected nil error from Close, got %v", err)
+	}
+
+	want := []observedCall{
+		{"increment", errBackend},
+		{"get", errBackend},
+		{"reset", errBackend},
+		{"close", nil},
+	}
+	if len(*calls) != len(want) {
+		t.Fatalf("expected %d observed calls, got %d", len(want), len(*calls))
+	}
+	for i, w := range want {
+		got := (*calls)[i]
+		if got.op != w.op || !errors.Is(got.err, w.err) {
+			t.Errorf("call %d: expected %s/%v, got %s/%v", i, w.op, w.err, got.op, got.err)
+		}
+	}
+}
+
+func TestInstrumented_Transparent(t *testing.T) {
+	calls, opt := recordingObserver()
+	st := Instrumented(NewMemory(), opt)
+	defer st.Close()
+	ctx := context.Background()
+
+	count, ttl, err := st.Increment(ctx, "key", time.Minute)
+	if err != nil || count != 1 || ttl <= 0 {
+		t.Fatalf("expected count 1 with positive TTL, got %d, %v, %v", count, ttl, err)
+	}
+	if count, err := st.Get(ctx, "key"); err != nil || count != 1 {
+		t.Errorf("expected Get to return 1, got %d, %v", count, err)
+	}
+
+	if len(*calls) != 2 || (*calls)[0].op != "increment" || (*calls)[1].op != "get" {
+		t.Errorf("expected increment and get to be observed, got %v", *calls)
+	}
+	for _, c := range *calls {
+		if c.err != nil {
+			t.Errorf("expected nil error for %s, got %v", c.op, c.err)
+		}
+	}
+}
+
+func TestInstrumented_IncrementWithReset(t *testing.T) {
+	var ops []string
+	st := Instrumented(NewMemory(), InstrumentWithObserver(func(op string, _ time.Duration, _ error) {
+		ops = append(ops, op)
+	}))
+	defer st.Close()
+
+	ws, ok := st.(WindowResetStore)
+	if !ok {
+		t.Fatal("expected instrumented store to implement WindowResetStore")
+	}
+	if count, _, err := ws.IncrementWithReset(context.Background(), "key", time.Minute); err != nil || count != 1 {
+		t.Fatalf("expected count 1, got count=%d err=%v", count, err)
+	}
+	if len(ops) != 1 || ops[0] != "increment" {
+		t.Errorf("expected increment observation, got %v", ops)
+	}
+
+	fallback := Instrumented(&errorStore{}).(WindowResetStore)
+	if _, _, err := fallback.IncrementWithReset(context.Background(), "key", time.Minute); err == nil {
+		t.Error("expected error from inner Increment for store without reset times")
+	}
+}
+
+func TestInstrumented_TakeToken(t *testing.T) {
+	var ops []string
+	st := Instrumented(NewMemory(), InstrumentWithObserver(func(op string, _ time.Duration, _ error) {
+		ops = append(ops, op)
+	}))
+	defer st.Close()
+
+	tb, ok := st.(TokenBucketStore)
+	if !ok {
+		t.Fatal("expected instrumented store to implement TokenBucketStore")
+	}
+	if _, allowed, err := tb.TakeToken(context.Background(), "bucket", 1, time.Second); err != nil || !allowed {
+		t.Fatalf("expected allowed, got allowed=%v err=%v", allowed, err)
+	}
+	if len(ops) != 1 || ops[0] != "take_token" {
+		t.Errorf("expected take_token observation, got %v", ops)
+	}
+
+	unsupported := Instrumented(&errorStore{}).(TokenBucketStore)
+	if _, _, err := unsupported.TakeToken(context.Background(), "bucket", 1, time.Second); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for store without token buckets, got %v", err)
+	}
+}
+
+func TestInstrumented_IncrementSliding(t *testing.T) {
+	var ops []string
+	st := Instrumented(NewMemory(), InstrumentWithObserver(func(op string, _ time.Duration, _ error) {
+		ops = append(ops, op)
+	}))
+	defer st.Close()
+
+	sw, ok := st.(SlidingWindowStore)
+	if !ok {
+		t.Fatal("expected instrumented store to implement SlidingWindowStore")
+	}
+	if count, _, err := sw.IncrementSliding(context.Background(), "log", 1, time.Second, time.Now()); err != nil || count != 1 {
+		t.Fatalf("expected count 1, got count=%d err=%v", count, err)
+	}
+	if len(ops) != 1 || ops[0] != "increment_sliding" {
+		t.Errorf("expected increment_sliding observation, got %v", ops)
+	}
+
+	unsupported := Instrumented(&errorStore{}).(SlidingWindowStore)
+	if _, _, err := unsupported.IncrementSliding(context.Background(), "log", 1, time.Second, time.Now()); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for store without sliding windows, got %v", err)
+	}
+}
+
+func TestInstrumented_UpdateTAT(t *testing.T) {
+	var ops []string
+	st := Instrumented(NewMemory(), InstrumentWithObserver(func(op string, _ time.Duration, _ error) {
+		ops = append(ops, op)
+	}))
+	defer st.Close()
+
+	gs, ok := st.(GCRAStore)
+	if !ok {
+		t.Fatal("expected instrumented store to implement GCRAStore")
+	}
+	if _, allowed, err := gs.UpdateTAT(context.Background(), "gcra", time.Second, time.Second); err != nil || !allowed {
+		t.Fatalf("expected allowed, got allowed=%v err=%v", allowed, err)
+	}
+	if len(ops) != 1 || ops[0] != "update_tat" {
+		t.Errorf("expected update_tat observation, got %v", ops)
+	}
+
+	unsupported := Instrumented(&errorStore{}).(GCRAStore)
+	if _, _, err := unsupported.UpdateTAT(context.Background(), "gcra", time.Second, time.Second); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for store without GCRA, got %v", err)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	plain := Instrumented(&errorStore{})
+	if Supports[TokenBucketStore](plain) || Supports[SlidingWindowStore](plain) || Supports[GCRAStore](plain) {
+		t.Error("expected instrumented plain store not to support optional capabilities")
+	}
+	if !Supports[Store](plain) {
+		t.Error("expected instrumented store to support Store")
+	}
+
+	memory := Instrumented(Instrumented(NewMemory()))
+	defer memory.Close()
+	if !Supports[TokenBucketStore](memory) || !Supports[SlidingWindowStore](memory) || !Supports[GCRAStore](memory) {
+		t.Error("expected nested instrumented memory store to support every capability")
+	}
+}
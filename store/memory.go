@@ -11,6 +11,17 @@ type memoryEntry struct {
 	expiration time.Time
 }
 
+type memoryBucket struct {
+	tokens     float64
+	updated    time.Time
+	expiration time.Time
+}
+
+type memoryLog struct {
+	times      []time.Time // oldest first
+	expiration time.Time
+}
+
 // Memory is an in-memory implementation of Store using a map with mutex protection.
 //
 // WARNING: This implementation is NOT suitable for distributed deployments.
@@ -27,6 +38,9 @@ type memoryEntry struct {
 type Memory struct {
 	mu      sync.RWMutex
 	entries map[string]*memoryEntry
+	buckets map[string]*memoryBucket
+	logs    map[string]*memoryLog
+	tats    map[string]time.Time
 	stopCh  chan struct{}
 }
 
@@ -37,13 +51,20 @@ type Memory struct {
 // Important: You must call Close() when done to stop the cleanup goroutine.
 // Failing to call Close() will result in a goroutine leak.
 func NewMemory() *Memory {
-	m := &Memory{
+	m := newMemory()
+	go m.cleanup()
+	return m
+}
+
+// newMemory creates a Memory without starting its cleanup goroutine.
+func newMemory() *Memory {
+	return &Memory{
 		entries: make(map[string]*memoryEntry),
+		buckets: make(map[string]*memoryBucket),
+		logs:    make(map[string]*memoryLog),
+		tats:    make(map[string]time.Time),
 		stopCh:  make(chan struct{}),
 	}
-
-	go m.cleanup()
-	return m
 }
 
 // Increment atomically increments the counter for the given key and returns the new count, TTL, and any error.
@@ -57,20 +78,115 @@ func (m *Memory) Increment(_ context.Context, key string, window time.Duration)
 	defer m.mu.Unlock()
 
 	now := time.Now()
-	entry, exists := m.entries[key]
+	count, expiration := m.increment(key, window, now)
+	return count, max(0, expiration.Sub(now)), nil
+}
 
+// IncrementWithReset increments the counter for key like Increment and returns the
+// time its window resets. See WindowResetStore for semantics.
+//
+// Note: The context parameter is accepted for interface compatibility but is not used.
+func (m *Memory) IncrementWithReset(_ context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count, expiration := m.increment(key, window, time.Now())
+	return count, expiration, nil
+}
+
+// increment increments the counter for key, starting a new window if it doesn't
+// exist or has expired, and returns the count and the window's expiration.
+// Must be called with m.mu held.
+func (m *Memory) increment(key string, window time.Duration, now time.Time) (int64, time.Time) {
+	entry, exists := m.entries[key]
 	if !exists || now.After(entry.expiration) {
-		expiration := now.Add(window)
-		m.entries[key] = &memoryEntry{
-			count:      1,
-			expiration: expiration,
-		}
-		return 1, window, nil
+		entry = &memoryEntry{expiration: now.Add(window)}
+		m.entries[key] = entry
 	}
-
 	entry.count++
-	ttl := max(0, time.Until(entry.expiration))
-	return entry.count, ttl, nil
+	return entry.count, entry.expiration
+}
+
+// TakeToken atomically refills the token bucket for key and takes one token if available.
+// See TokenBucketStore for semantics. Buckets are stored separately from Increment counters.
+//
+// Note: The context parameter is accepted for interface compatibility but is not used.
+func (m *Memory) TakeToken(_ context.Context, key string, capacity int64, refillInterval time.Duration) (float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	limit := float64(capacity)
+	bucket, exists := m.buckets[key]
+	if !exists || now.After(bucket.expiration) {
+		bucket = &memoryBucket{tokens: limit, updated: now}
+		m.buckets[key] = bucket
+	}
+
+	bucket.tokens = min(limit, bucket.tokens+float64(now.Sub(bucket.updated))/float64(refillInterval))
+	bucket.updated = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	bucket.expiration = now.Add(time.Duration((limit - bucket.tokens) * float64(refillInterval)))
+	return bucket.tokens, allowed, nil
+}
+
+// IncrementSliding atomically records a request at now in the sliding window log for key.
+// See SlidingWindowStore for semantics. Logs are stored separately from Increment counters.
+//
+// Note: The context parameter is accepted for interface compatibility but is not used.
+func (m *Memory) IncrementSliding(_ context.Context, key string, limit int64, window time.Duration, now time.Time) (int64, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log, exists := m.logs[key]
+	if !exists {
+		log = &memoryLog{}
+		m.logs[key] = log
+	}
+
+	cutoff := now.Add(-window)
+	expired := 0
+	for expired < len(log.times) && !log.times[expired].After(cutoff) {
+		expired++
+	}
+	log.times = log.times[expired:]
+
+	count := int64(len(log.times)) + 1
+	if count <= limit {
+		log.times = append(log.times, now)
+	}
+	if len(log.times) == 0 {
+		delete(m.logs, key)
+		return count, now, nil
+	}
+	log.expiration = log.times[len(log.times)-1].Add(window)
+	return count, log.times[0], nil
+}
+
+// UpdateTAT atomically applies GCRA to the theoretical arrival time stored for key.
+// See GCRAStore for semantics. TATs are stored separately from Increment counters.
+//
+// Note: The context parameter is accepted for interface compatibility but is not used.
+func (m *Memory) UpdateTAT(_ context.Context, key string, emission, tolerance time.Duration) (time.Duration, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	tat, exists := m.tats[key]
+	if !exists || tat.Before(now) {
+		tat = now
+	}
+
+	next := tat.Add(emission)
+	if next.Sub(now) > tolerance {
+		return tat.Sub(now), false, nil
+	}
+	m.tats[key] = next
+	return next.Sub(now), true, nil
 }
 
 // Get retrieves the current count for the given key without incrementing.
@@ -93,6 +209,9 @@ func (m *Memory) Reset(_ context.Context, key string) error {
 	defer m.mu.Unlock()
 
 	delete(m.entries, key)
+	delete(m.buckets, key)
+	delete(m.logs, key)
+	delete(m.tats, key)
 	return nil
 }
 
@@ -101,6 +220,9 @@ func (m *Memory) Close() error {
 	close(m.stopCh)
 	m.mu.Lock()
 	m.entries = nil
+	m.buckets = nil
+	m.logs = nil
+	m.tats = nil
 	m.mu.Unlock()
 	return nil
 }
@@ -108,26 +230,51 @@ func (m *Memory) Close() error {
 // runCleanup executes a single cleanup cycle, removing all expired entries.
 // This is exposed for testing purposes to trigger cleanup without waiting for the ticker.
 func (m *Memory) runCleanup() {
-	now := time.Now()
-	var expiredKeys []string
+	entryExpiration := func(e *memoryEntry) time.Time { return e.expiration }
+	bucketExpiration := func(b *memoryBucket) time.Time { return b.expiration }
+	logExpiration := func(l *memoryLog) time.Time { return l.expiration }
+	tatExpiration := func(tat time.Time) time.Time { return tat }
 
+	now := time.Now()
 	m.mu.RLock()
-	for key, entry := range m.entries {
-		if now.After(entry.expiration) {
-			expiredKeys = append(expiredKeys, key)
+	expiredKeys := collectExpired(m.entries, now, entryExpiration)
+	expiredBuckets := collectExpired(m.buckets, now, bucketExpiration)
+	expiredLogs := collectExpired(m.logs, now, logExpiration)
+	expiredTATs := collectExpired(m.tats, now, tatExpiration)
+	m.mu.RUnlock()
+
+	if len(expiredKeys) == 0 && len(expiredBuckets) == 0 && len(expiredLogs) == 0 && len(expiredTATs) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	now = time.Now()
+	deleteExpired(m.entries, expiredKeys, now, entryExpiration)
+	deleteExpired(m.buckets, expiredBuckets, now, bucketExpiration)
+	deleteExpired(m.logs, expiredLogs, now, logExpiration)
+	deleteExpired(m.tats, expiredTATs, now, tatExpiration)
+	m.mu.Unlock()
+}
+
+// collectExpired returns the keys of values in entries that expired before now.
+// Must be called with at least a read lock held.
+func collectExpired[V any](entries map[string]V, now time.Time, expiration func(V) time.Time) []string {
+	var expired []string
+	for key, v := range entries {
+		if now.After(expiration(v)) {
+			expired = append(expired, key)
 		}
 	}
-	m.mu.RUnlock()
+	return expired
+}
 
-	if len(expiredKeys) > 0 {
-		m.mu.Lock()
-		now := time.Now()
-		for _, key := range expiredKeys {
-			if entry, exists := m.entries[key]; exists && now.After(entry.expiration) {
-				delete(m.entries, key)
-			}
+// deleteExpired deletes keys from entries whose values are still expired at now,
+// skipping keys refreshed since collectExpired. Must be called with the write lock held.
+func deleteExpired[V any](entries map[string]V, keys []string, now time.Time, expiration func(V) time.Time) {
+	for _, key := range keys {
+		if v, exists := entries[key]; exists && now.After(expiration(v)) {
+			delete(entries, key)
 		}
-		m.mu.Unlock()
 	}
 }
 
@@ -620,3 +620,175 @@ func BenchmarkMemory_Cleanup(b *testing.B) {
 		m.runCleanup()
 	}
 }
+
+func TestMemory_IncrementWithReset(t *testing.T) {
+	m := NewMemory()
+	defer m.Close()
+	ctx := context.Background()
+
+	before := time.Now()
+	count, resetAt, err := m.IncrementWithReset(ctx, "window", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 || resetAt.Before(before.Add(50*time.Millisecond)) || resetAt.After(time.Now().Add(50*time.Millisecond)) {
+		t.Fatalf("expected count 1 resetting one window from now, got count=%d resetAt=%v", count, resetAt)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	count, again, err := m.IncrementWithReset(ctx, "window", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || !again.Equal(resetAt) {
+		t.Errorf("expected count 2 with reset %v, got count=%d reset=%v", resetAt, count, again)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	count, next, _ := m.IncrementWithReset(ctx, "window", 50*time.Millisecond)
+	if count != 1 || !next.After(resetAt) {
+		t.Errorf("expected a new window after reset, got count=%d reset=%v", count, next)
+	}
+}
+
+func TestMemory_TakeToken(t *testing.T) {
+	m := NewMemory()
+	defer m.Close()
+	ctx := context.Background()
+
+	for i, wantTokens := range []float64{2, 1, 0} {
+		tokens, allowed, err := m.TakeToken(ctx, "bucket", 3, 50*time.Millisecond)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !allowed || tokens < wantTokens || tokens >= wantTokens+0.5 {
+			t.Errorf("take %d: expected allowed with ~%.0f tokens left, got allowed=%v tokens=%.2f", i+1, wantTokens, allowed, tokens)
+		}
+	}
+
+	if _, allowed, _ := m.TakeToken(ctx, "bucket", 3, 50*time.Millisecond); allowed {
+		t.Error("expected empty bucket to deny")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, allowed, _ := m.TakeToken(ctx, "bucket", 3, 50*time.Millisecond); !allowed {
+		t.Error("expected a token after refill interval")
+	}
+}
+
+func TestMemory_TakeToken_Cleanup(t *testing.T) {
+	m := NewMemory()
+	defer m.Close()
+
+	m.TakeToken(context.Background(), "bucket", 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	m.runCleanup()
+
+	m.mu.RLock()
+	_, exists := m.buckets["bucket"]
+	m.mu.RUnlock()
+	if exists {
+		t.Error("expected refilled bucket to be cleaned up")
+	}
+}
+
+func TestMemory_UpdateTAT(t *testing.T) {
+	m := NewMemory()
+	defer m.Close()
+	ctx := context.Background()
+
+	emission := 50 * time.Millisecond
+	tolerance := 2 * emission
+
+	for i := range 2 {
+		ahead, allowed, err := m.UpdateTAT(ctx, "gcra", emission, tolerance)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := time.Duration(i+1) * emission
+		if !allowed || ahead > want || ahead < want-10*time.Millisecond {
+			t.Errorf("request %d: expected allowed with TAT ~%v ahead, got allowed=%v ahead=%v", i+1, want, allowed, ahead)
+		}
+	}
+
+	// Denied: the TAT is not advanced, so retrying does not push it further out.
+	for range 2 {
+		ahead, allowed, _ := m.UpdateTAT(ctx, "gcra", emission, tolerance)
+		if allowed || ahead > tolerance {
+			t.Errorf("expected burst exhausted to deny without advancing TAT, got allowed=%v ahead=%v", allowed, ahead)
+		}
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, allowed, _ := m.UpdateTAT(ctx, "gcra", emission, tolerance); !allowed {
+		t.Error("expected a request to conform after one emission interval")
+	}
+}
+
+func TestMemory_UpdateTAT_Cleanup(t *testing.T) {
+	m := NewMemory()
+	defer m.Close()
+
+	m.UpdateTAT(context.Background(), "gcra", 10*time.Millisecond, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	m.runCleanup()
+
+	m.mu.RLock()
+	_, exists := m.tats["gcra"]
+	m.mu.RUnlock()
+	if exists {
+		t.Error("expected passed TAT to be cleaned up")
+	}
+}
+
+func TestMemory_IncrementSliding(t *testing.T) {
+	m := NewMemory()
+	defer m.Close()
+	ctx := context.Background()
+
+	start := time.Now()
+	window := time.Minute
+
+	for i, at := range []time.Duration{0, 20 * time.Second} {
+		count, oldest, err := m.IncrementSliding(ctx, "log", 2, window, start.Add(at))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != int64(i+1) || !oldest.Equal(start) {
+			t.Errorf("request %d: expected count %d oldest %v, got %d %v", i+1, i+1, start, count, oldest)
+		}
+	}
+
+	// Rejected: not recorded, so repeating it does not grow the log.
+	for range 2 {
+		if count, _, _ := m.IncrementSliding(ctx, "log", 2, window, start.Add(30*time.Second)); count != 3 {
+			t.Errorf("expected rejected count 3, got %d", count)
+		}
+	}
+
+	// A fixed window starting at start would reset at 60s; the sliding window frees
+	// exactly one slot when the first request leaves it.
+	count, oldest, _ := m.IncrementSliding(ctx, "log", 2, window, start.Add(61*time.Second))
+	if count != 2 || !oldest.Equal(start.Add(20*time.Second)) {
+		t.Errorf("expected count 2 with oldest at +20s, got %d %v", count, oldest.Sub(start))
+	}
+	if count, _, _ := m.IncrementSliding(ctx, "log", 2, window, start.Add(62*time.Second)); count != 3 {
+		t.Errorf("expected second request after slide to be rejected, got count %d", count)
+	}
+}
+
+func TestMemory_IncrementSliding_Cleanup(t *testing.T) {
+	m := NewMemory()
+	defer m.Close()
+
+	m.IncrementSliding(context.Background(), "log", 1, 10*time.Millisecond, time.Now())
+	time.Sleep(20 * time.Millisecond)
+	m.runCleanup()
+
+	m.mu.RLock()
+	_, exists := m.logs["log"]
+	m.mu.RUnlock()
+	if exists {
+		t.Error("expected expired log to be cleaned up")
+	}
+}
@@ -0,0 +1,218 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// postgresTableName matches an optionally schema-qualified, unquoted identifier.
+// Table names are interpolated into SQL, so anything else is rejected.
+var postgresTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Postgres is a PostgreSQL-backed implementation of Store for deployments that run
+// Postgres but not Redis. Counters are rows in a single table updated with an atomic
+// upsert, so limits are shared across all instances using the same database.
+//
+// Each request costs one round trip and a row lock on its key. For high request
+// rates, prefer Redis.
+//
+// Postgres uses database/sql and does not import a driver: open the *sql.DB with
+// any Postgres driver (e.g., github.com/jackc/pgx/v5/stdlib) and pass it in config.
+type Postgres struct {
+	db     *sql.DB
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	incrementSQL string
+	getSQL       string
+	resetSQL     string
+	cleanupSQL   string
+}
+
+// PostgresConfig holds configuration for the Postgres store.
+// All fields should be populated explicitly by your application code.
+type PostgresConfig struct {
+	// DB is the connection pool to use (required). The caller owns it:
+	// Close does not close DB.
+	DB *sql.DB
+
+	// Table is the counter table, optionally schema-qualified (default: "chikit_ratelimit").
+	// It is created if it does not exist.
+	Table string
+
+	// CleanupInterval is how often expired rows are deleted (default: 1 minute).
+	CleanupInterval time.Duration
+
+	// MaxOpenConns limits open connections in DB (default: unchanged)
+	MaxOpenConns int
+
+	// MaxIdleConns limits idle connections kept in DB (default: unchanged)
+	MaxIdleConns int
+
+	// ConnMaxLifetime is the maximum lifetime of a connection in DB (default: unchanged)
+	ConnMaxLifetime time.Duration
+}
+
+// NewPostgres creates a Postgres store with the given configuration.
+// Validates the connection with a ping and creates the counter table if needed:
+//
+//	CREATE TABLE IF NOT EXISTS chikit_ratelimit (
+//		key        TEXT PRIMARY KEY,
+//		count      BIGINT NOT NULL,
+//		expires_at TIMESTAMPTZ NOT NULL
+//	)
+//
+// Starts a background goroutine that deletes expired rows every CleanupInterval.
+//
+// Important: You must call Close() when done to stop the cleanup goroutine.
+//
+// Example:
+//
+//	db, err := sql.Open("pgx", dsn)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	st, err := store.NewPostgres(store.PostgresConfig{DB: db})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer st.Close()
+func NewPostgres(config PostgresConfig) (*Postgres, error) {
+	if config.DB == nil {
+		return nil, errors.New("postgres store requires DB")
+	}
+	if config.Table == "" {
+		config.Table = "chikit_ratelimit"
+	}
+	if !postgresTableName.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid postgres table name %q", config.Table)
+	}
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = time.Minute
+	}
+	if config.MaxOpenConns > 0 && config.MaxIdleConns > config.MaxOpenConns {
+		return nil, fmt.Errorf("MaxIdleConns (%d) cannot exceed MaxOpenConns (%d)", config.MaxIdleConns, config.MaxOpenConns)
+	}
+
+	if config.MaxOpenConns > 0 {
+		config.DB.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		config.DB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		config.DB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := config.DB.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	createSQL := `CREATE TABLE IF NOT EXISTS ` + config.Table + ` (
+	key        TEXT PRIMARY KEY,
+	count      BIGINT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+)`
+	if _, err := config.DB.ExecContext(ctx, createSQL); err != nil {
+		return nil, fmt.Errorf("postgres create table failed: %w", err)
+	}
+
+	p := &Postgres{
+		db:     config.DB,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+
+		// An expired row is restarted at 1 with a fresh expiry, so expiration is
+		// enforced even if cleanup has not run yet.
+		incrementSQL: `INSERT INTO ` + config.Table + ` AS t (key, count, expires_at)
+VALUES ($1, 1, now() + $2::bigint * interval '1 microsecond')
+ON CONFLICT (key) DO UPDATE SET
+	count = CASE WHEN t.expires_at <= now() THEN 1 ELSE t.count + 1 END,
+	expires_at = CASE WHEN t.expires_at <= now() THEN EXCLUDED.expires_at ELSE t.expires_at END
+RETURNING count, EXTRACT(EPOCH FROM expires_at - now())::float8`,
+		getSQL:     `SELECT count FROM ` + config.Table + ` WHERE key = $1 AND expires_at > now()`,
+		resetSQL:   `DELETE FROM ` + config.Table + ` WHERE key = $1`,
+		cleanupSQL: `DELETE FROM ` + config.Table + ` WHERE expires_at <= now()`,
+	}
+
+	go p.cleanup(config.CleanupInterval)
+	return p, nil
+}
+
+// Increment atomically increments the counter for the given key with a single upsert
+// and returns the new count, time remaining until the window resets, and any error.
+// Expired rows are treated as absent and restart at 1.
+func (p *Postgres) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	var count int64
+	var ttlSeconds float64
+	if err := p.db.QueryRowContext(ctx, p.incrementSQL, key, window.Microseconds()).Scan(&count, &ttlSeconds); err != nil {
+		return 0, 0, fmt.Errorf("postgres increment failed: %w", err)
+	}
+	return count, time.Duration(ttlSeconds * float64(time.Second)), nil
+}
+
+// Get retrieves the current count for the given key without incrementing.
+// Returns 0 if the key doesn't exist or has expired.
+func (p *Postgres) Get(ctx context.Context, key string) (int64, error) {
+	var count int64
+	err := p.db.QueryRowContext(ctx, p.getSQL, key).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("postgres get failed: %w", err)
+	}
+	return count, nil
+}
+
+// Reset removes the counter for the given key.
+func (p *Postgres) Reset(ctx context.Context, key string) error {
+	if _, err := p.db.ExecContext(ctx, p.resetSQL, key); err != nil {
+		return fmt.Errorf("postgres reset failed: %w", err)
+	}
+	return nil
+}
+
+// Close stops the cleanup goroutine and waits for an in-flight cleanup to finish.
+// The DB passed in PostgresConfig is left open.
+func (p *Postgres) Close() error {
+	close(p.stopCh)
+	<-p.doneCh
+	return nil
+}
+
+// runCleanup deletes all expired rows.
+// This is exposed for testing purposes to trigger cleanup without waiting for the ticker.
+func (p *Postgres) runCleanup(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, p.cleanupSQL); err != nil {
+		return fmt.Errorf("postgres cleanup failed: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) cleanup(interval time.Duration) {
+	defer close(p.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			// Errors are retried on the next tick; expired rows are already
+			// ignored by Increment and Get.
+			_ = p.runCleanup(ctx)
+			cancel()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
@@ -4,6 +4,8 @@ package store
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -22,6 +24,99 @@ local ttl = redis.call('TTL', KEYS[1])
 return {count, ttl}
 `)
 
+// incrResetScript is a Lua script that atomically increments a counter like incrScript and
+// records when its window resets in a companion key, using the Redis server clock. Counters
+// created by incrScript have no companion key, so their reset time is derived from the
+// remaining TTL. Returns [count, resetAt] where resetAt is in Unix milliseconds.
+var incrResetScript = redis.NewScript(`
+local window = tonumber(ARGV[1])
+local count = redis.call('INCR', KEYS[1])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+if count == 1 then
+    redis.call('PEXPIRE', KEYS[1], window)
+    redis.call('SET', KEYS[2], string.format('%d', now + window), 'PX', window)
+    return {count, now + window}
+end
+local resetAt = tonumber(redis.call('GET', KEYS[2]))
+if resetAt == nil then
+    resetAt = now + math.max(0, redis.call('PTTL', KEYS[1]))
+end
+return {count, resetAt}
+`)
+
+// tokenBucketScript is a Lua script that atomically refills a token bucket stored as a
+// hash and takes one token if available. Uses the Redis server clock so all instances
+// agree on elapsed time. Returns [allowed, tokens]; tokens is a string because Redis
+// truncates Lua numbers to integers.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+    tokens = capacity
+    ts = now
+end
+tokens = math.min(capacity, tokens + (now - ts) / interval)
+local allowed = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) * interval / 1000) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript is a Lua script that atomically trims a sorted-set request log to
+// the current window and records the request if below the limit. Scores are Unix
+// microseconds. Returns [count, oldest] where count includes this request and oldest is
+// the score of the oldest entry in the window (now if empty).
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1]) + 1
+if count <= limit then
+    redis.call('ZADD', KEYS[1], now, ARGV[4])
+    redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if #oldest == 0 then
+    return {count, now}
+end
+return {count, tonumber(oldest[2])}
+`)
+
+// gcraScript is a Lua script that atomically applies GCRA to a theoretical arrival time
+// stored as Unix microseconds. Uses the Redis server clock so all instances agree on
+// now. Returns [allowed, ahead] where ahead is how far the stored TAT is ahead of now
+// in microseconds.
+var gcraScript = redis.NewScript(`
+local emission = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+    tat = now
+end
+local next = tat + emission
+if next - now > tolerance then
+    return {0, tat - now}
+end
+redis.call('SET', KEYS[1], string.format('%d', next), 'PX', math.ceil((next - now) / 1000) + 1)
+return {1, next - now}
+`)
+
+// resetKeySuffix names the companion key holding a fixed window's reset time.
+const resetKeySuffix = ":reset"
+
 // Redis is a Redis-backed implementation of Store suitable for distributed deployments.
 // Uses Redis atomic operations via Lua scripts to ensure rate limit accuracy across
 // multiple instances in Kubernetes or other distributed environments.
@@ -150,6 +245,122 @@ func (r *Redis) Increment(ctx context.Context, key string, window time.Duration)
 	return count, ttl, nil
 }
 
+// IncrementWithReset atomically increments the counter for key like Increment and returns
+// the time its window resets, using a Lua script. The reset time is fixed when the window
+// is created and kept in a companion key, so it is the same for every request in the
+// window. See WindowResetStore for semantics.
+func (r *Redis) IncrementWithReset(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	fullKey := r.prefix + key
+
+	result, err := incrResetScript.Run(ctx, r.client, []string{fullKey, fullKey + resetKeySuffix}, window.Milliseconds()).Slice()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("redis increment failed: %w", err)
+	}
+
+	if len(result) != 2 {
+		return 0, time.Time{}, fmt.Errorf("unexpected result length: got %d, want 2", len(result))
+	}
+
+	count, ok := result[0].(int64)
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("unexpected type for count: %T", result[0])
+	}
+
+	resetAt, ok := result[1].(int64)
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("unexpected type for reset time: %T", result[1])
+	}
+
+	return count, time.UnixMilli(resetAt), nil
+}
+
+// TakeToken atomically refills the token bucket for key and takes one token if available,
+// using a Lua script. See TokenBucketStore for semantics.
+func (r *Redis) TakeToken(ctx context.Context, key string, capacity int64, refillInterval time.Duration) (float64, bool, error) {
+	fullKey := r.prefix + key
+
+	result, err := tokenBucketScript.Run(ctx, r.client, []string{fullKey}, capacity, refillInterval.Microseconds()).Slice()
+	if err != nil {
+		return 0, false, fmt.Errorf("redis take token failed: %w", err)
+	}
+
+	if len(result) != 2 {
+		return 0, false, fmt.Errorf("unexpected result length: got %d, want 2", len(result))
+	}
+
+	allowed, ok := result[0].(int64)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected type for allowed: %T", result[0])
+	}
+
+	tokensStr, ok := result[1].(string)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected type for tokens: %T", result[1])
+	}
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("unexpected value for tokens: %w", err)
+	}
+
+	return tokens, allowed == 1, nil
+}
+
+// IncrementSliding atomically records a request at now in the sliding window log for key,
+// stored as a sorted set, using a Lua script. See SlidingWindowStore for semantics.
+// Timestamps come from now, so instances sharing a key should have synchronized clocks.
+func (r *Redis) IncrementSliding(ctx context.Context, key string, limit int64, window time.Duration, now time.Time) (int64, time.Time, error) {
+	fullKey := r.prefix + key
+	member := strconv.FormatInt(now.UnixMicro(), 10) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+
+	result, err := slidingWindowScript.Run(ctx, r.client, []string{fullKey}, limit, window.Microseconds(), now.UnixMicro(), member).Slice()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("redis increment sliding failed: %w", err)
+	}
+
+	if len(result) != 2 {
+		return 0, time.Time{}, fmt.Errorf("unexpected result length: got %d, want 2", len(result))
+	}
+
+	count, ok := result[0].(int64)
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("unexpected type for count: %T", result[0])
+	}
+
+	oldest, ok := result[1].(int64)
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("unexpected type for oldest: %T", result[1])
+	}
+
+	return count, time.UnixMicro(oldest), nil
+}
+
+// UpdateTAT atomically applies GCRA to the theoretical arrival time stored for key,
+// using a Lua script. See GCRAStore for semantics.
+func (r *Redis) UpdateTAT(ctx context.Context, key string, emission, tolerance time.Duration) (time.Duration, bool, error) {
+	fullKey := r.prefix + key
+
+	result, err := gcraScript.Run(ctx, r.client, []string{fullKey}, emission.Microseconds(), tolerance.Microseconds()).Slice()
+	if err != nil {
+		return 0, false, fmt.Errorf("redis update tat failed: %w", err)
+	}
+
+	if len(result) != 2 {
+		return 0, false, fmt.Errorf("unexpected result length: got %d, want 2", len(result))
+	}
+
+	allowed, ok := result[0].(int64)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected type for allowed: %T", result[0])
+	}
+
+	ahead, ok := result[1].(int64)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected type for ahead: %T", result[1])
+	}
+
+	return time.Duration(ahead) * time.Microsecond, allowed == 1, nil
+}
+
 // Get retrieves the current count for the given key without incrementing.
 // Returns 0 if the key doesn't exist or has expired.
 func (r *Redis) Get(ctx context.Context, key string) (int64, error) {
@@ -165,7 +376,7 @@ func (r *Redis) Get(ctx context.Context, key string) (int64, error) {
 
 // Reset removes the counter for the given key.
 func (r *Redis) Reset(ctx context.Context, key string) error {
-	if err := r.client.Del(ctx, r.prefix+key).Err(); err != nil {
+	if err := r.client.Del(ctx, r.prefix+key, r.prefix+key+resetKeySuffix).Err(); err != nil {
 		return fmt.Errorf("redis reset failed: %w", err)
 	}
 	return nil
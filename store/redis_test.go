@@ -764,3 +764,102 @@ func ExampleRedis() {
 
 	fmt.Printf("Request count: %d\n", count)
 }
+
+func TestRedis_IncrementWithReset(t *testing.T) {
+	store, cleanup := setupRedisTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	count, resetAt, err := store.IncrementWithReset(ctx, "test:window", 2*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 || time.Until(resetAt) <= 0 || time.Until(resetAt) > 3*time.Second {
+		t.Fatalf("expected count 1 resetting one window from now, got count=%d resetAt=%v", count, resetAt)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	count, again, err := store.IncrementWithReset(ctx, "test:window", 2*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || !again.Equal(resetAt) {
+		t.Errorf("expected count 2 with reset %v, got count=%d reset=%v", resetAt, count, again)
+	}
+
+	if err := store.Reset(ctx, "test:window"); err != nil {
+		t.Fatalf("unexpected reset error: %v", err)
+	}
+	if n, _ := store.client.Exists(ctx, store.prefix+"test:window"+resetKeySuffix).Result(); n != 0 {
+		t.Error("expected Reset to remove the reset time")
+	}
+}
+
+func TestRedis_TakeToken(t *testing.T) {
+	store, cleanup := setupRedisTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for i := range 3 {
+		if _, allowed, err := store.TakeToken(ctx, "test:bucket", 3, time.Second); err != nil || !allowed {
+			t.Fatalf("take %d: expected allowed, got allowed=%v err=%v", i+1, allowed, err)
+		}
+	}
+
+	tokens, allowed, err := store.TakeToken(ctx, "test:bucket", 3, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed || tokens >= 1 {
+		t.Errorf("expected empty bucket to deny, got allowed=%v tokens=%.2f", allowed, tokens)
+	}
+}
+
+func TestRedis_IncrementSliding(t *testing.T) {
+	store, cleanup := setupRedisTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	start := time.Now().Truncate(time.Microsecond)
+	for i := range 2 {
+		count, oldest, err := store.IncrementSliding(ctx, "test:log", 2, time.Minute, start.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != int64(i+1) || !oldest.Equal(start) {
+			t.Errorf("request %d: expected count %d oldest %v, got %d %v", i+1, i+1, start, count, oldest)
+		}
+	}
+
+	if count, _, _ := store.IncrementSliding(ctx, "test:log", 2, time.Minute, start.Add(2*time.Second)); count != 3 {
+		t.Errorf("expected rejected count 3, got %d", count)
+	}
+
+	count, oldest, err := store.IncrementSliding(ctx, "test:log", 2, time.Minute, start.Add(61*time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || !oldest.Equal(start.Add(time.Second)) {
+		t.Errorf("expected count 2 with oldest at +1s, got %d %v", count, oldest.Sub(start))
+	}
+}
+
+func TestRedis_UpdateTAT(t *testing.T) {
+	store, cleanup := setupRedisTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for i := range 3 {
+		if _, allowed, err := store.UpdateTAT(ctx, "test:gcra", time.Second, 3*time.Second); err != nil || !allowed {
+			t.Fatalf("request %d: expected allowed, got allowed=%v err=%v", i+1, allowed, err)
+		}
+	}
+
+	ahead, allowed, err := store.UpdateTAT(ctx, "test:gcra", time.Second, 3*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed || ahead <= 2*time.Second || ahead > 3*time.Second {
+		t.Errorf("expected burst exhausted to deny with TAT ~3s ahead, got allowed=%v ahead=%v", allowed, ahead)
+	}
+}
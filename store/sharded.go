@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"hash/maphash"
+	"runtime"
+	"time"
+)
+
+// ShardedMemory is an in-memory Store that partitions keys across independently
+// locked Memory shards, so concurrent requests for different keys rarely contend on
+// the same mutex. Semantics are identical to Memory, including expiration, cleanup,
+// and the WindowResetStore, TokenBucketStore, SlidingWindowStore, and GCRAStore extensions.
+//
+// The same distributed-deployment warning as Memory applies: state is local to the
+// process. Prefer ShardedMemory over Memory for single-instance services handling
+// many distinct keys at high concurrency.
+type ShardedMemory struct {
+	shards []*Memory
+	seed   maphash.Seed
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+type shardedConfig struct {
+	cleanupInterval time.Duration
+}
+
+// ShardedMemoryOption configures NewShardedMemory.
+type ShardedMemoryOption func(*shardedConfig)
+
+// ShardedMemoryWithCleanupInterval sets how often expired entries are removed from
+// all shards (default: 1 minute, as for Memory).
+func ShardedMemoryWithCleanupInterval(d time.Duration) ShardedMemoryOption {
+	return func(c *shardedConfig) {
+		c.cleanupInterval = d
+	}
+}
+
+// NewShardedMemory creates an in-memory store with the given number of shards.
+// A shard count of zero or less uses runtime.GOMAXPROCS(0). A single background
+// goroutine cleans up expired entries in every shard.
+//
+// Important: You must call Close() when done to stop the cleanup goroutine.
+//
+// Example:
+//
+//	st := store.NewShardedMemory(32)
+//	defer st.Close()
+func NewShardedMemory(shards int, opts ...ShardedMemoryOption) *ShardedMemory {
+	cfg := &shardedConfig{cleanupInterval: time.Minute}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	if cfg.cleanupInterval <= 0 {
+		cfg.cleanupInterval = time.Minute
+	}
+
+	s := &ShardedMemory{
+		shards: make([]*Memory, shards),
+		seed:   maphash.MakeSeed(),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	for i := range s.shards {
+		s.shards[i] = newMemory()
+	}
+
+	go s.cleanup(cfg.cleanupInterval)
+	return s
+}
+
+// shard returns the shard that owns key.
+func (s *ShardedMemory) shard(key string) *Memory {
+	return s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+}
+
+// Increment atomically increments the counter for the given key in its shard.
+// See Memory.Increment.
+func (s *ShardedMemory) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	return s.shard(key).Increment(ctx, key, window)
+}
+
+// IncrementWithReset increments the counter for key in its shard and returns the time
+// its window resets. See WindowResetStore for semantics.
+func (s *ShardedMemory) IncrementWithReset(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	return s.shard(key).IncrementWithReset(ctx, key, window)
+}
+
+// TakeToken atomically refills the token bucket for key and takes one token if available.
+// See TokenBucketStore for semantics.
+func (s *ShardedMemory) TakeToken(ctx context.Context, key string, capacity int64, refillInterval time.Duration) (float64, bool, error) {
+	return s.shard(key).TakeToken(ctx, key, capacity, refillInterval)
+}
+
+// IncrementSliding atomically records a request at now in the sliding window log for key.
+// See SlidingWindowStore for semantics.
+func (s *ShardedMemory) IncrementSliding(ctx context.Context, key string, limit int64, window time.Duration, now time.Time) (int64, time.Time, error) {
+	return s.shard(key).IncrementSliding(ctx, key, limit, window, now)
+}
+
+// UpdateTAT atomically applies GCRA to the theoretical arrival time stored for key.
+// See GCRAStore for semantics.
+func (s *ShardedMemory) UpdateTAT(ctx context.Context, key string, emission, tolerance time.Duration) (time.Duration, bool, error) {
+	return s.shard(key).UpdateTAT(ctx, key, emission, tolerance)
+}
+
+// Get retrieves the current count for the given key without incrementing.
+// Returns 0 if the key doesn't exist or has expired.
+func (s *ShardedMemory) Get(ctx context.Context, key string) (int64, error) {
+	return s.shard(key).Get(ctx, key)
+}
+
+// Reset removes all state for the given key.
+func (s *ShardedMemory) Reset(ctx context.Context, key string) error {
+	return s.shard(key).Reset(ctx, key)
+}
+
+// Close stops the background cleanup goroutine and releases all shards.
+func (s *ShardedMemory) Close() error {
+	close(s.stopCh)
+	<-s.doneCh
+	for _, m := range s.shards {
+		m.Close()
+	}
+	return nil
+}
+
+// runCleanup removes expired entries from every shard.
+// This is exposed for testing purposes to trigger cleanup without waiting for the ticker.
+func (s *ShardedMemory) runCleanup() {
+	for _, m := range s.shards {
+		m.runCleanup()
+	}
+}
+
+func (s *ShardedMemory) cleanup(interval time.Duration) {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runCleanup()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	_ TokenBucketStore   = (*ShardedMemory)(nil)
+	_ SlidingWindowStore = (*ShardedMemory)(nil)
+	_ GCRAStore          = (*ShardedMemory)(nil)
+)
+
+func TestShardedMemory_Increment_ConcurrentAccuracy(t *testing.T) {
+	s := NewShardedMemory(8)
+	defer s.Close()
+	ctx := context.Background()
+
+	const keys = 50
+	const perKey = 40
+
+	var wg sync.WaitGroup
+	for k := range keys {
+		for range perKey {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, _, err := s.Increment(ctx, "key-"+strconv.Itoa(k), time.Minute); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	for k := range keys {
+		count, err := s.Get(ctx, "key-"+strconv.Itoa(k))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != perKey {
+			t.Errorf("key-%d: expected count %d, got %d", k, perKey, count)
+		}
+	}
+}
+
+func TestShardedMemory_Reset(t *testing.T) {
+	s := NewShardedMemory(4)
+	defer s.Close()
+	ctx := context.Background()
+
+	s.Increment(ctx, "a", time.Minute)
+	s.Increment(ctx, "b", time.Minute)
+	if err := s.Reset(ctx, "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count, _ := s.Get(ctx, "a"); count != 0 {
+		t.Errorf("expected reset key to be 0, got %d", count)
+	}
+	if count, _ := s.Get(ctx, "b"); count != 1 {
+		t.Errorf("expected other key to be unaffected, got %d", count)
+	}
+}
+
+func TestShardedMemory_Cleanup(t *testing.T) {
+	s := NewShardedMemory(4)
+	defer s.Close()
+	ctx := context.Background()
+
+	for i := range 100 {
+		s.Increment(ctx, "expired-"+strconv.Itoa(i), 10*time.Millisecond)
+	}
+	s.Increment(ctx, "live", time.Minute)
+	time.Sleep(20 * time.Millisecond)
+	s.runCleanup()
+
+	total := 0
+	for i, m := range s.shards {
+		m.mu.RLock()
+		n := len(m.entries)
+		m.mu.RUnlock()
+		if n > 1 {
+			t.Errorf("shard %d: expected expired entries to be removed, %d remain", i, n)
+		}
+		total += n
+	}
+	if total != 1 {
+		t.Errorf("expected only the live entry to remain, got %d entries", total)
+	}
+}
+
+func TestShardedMemory_CleanupInterval(t *testing.T) {
+	s := NewShardedMemory(2, ShardedMemoryWithCleanupInterval(10*time.Millisecond))
+	defer s.Close()
+
+	s.Increment(context.Background(), "key", 5*time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		m := s.shard("key")
+		m.mu.RLock()
+		_, exists := m.entries["key"]
+		m.mu.RUnlock()
+		if !exists {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("expected background cleanup to remove the expired entry")
+}
+
+func TestShardedMemory_DefaultShards(t *testing.T) {
+	s := NewShardedMemory(0)
+	defer s.Close()
+	if len(s.shards) < 1 {
+		t.Errorf("expected at least one shard, got %d", len(s.shards))
+	}
+}
+
+func benchmarkManyKeys(b *testing.B, st Store) {
+	ctx := context.Background()
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "bench:" + strconv.Itoa(i)
+	}
+	var next atomic.Uint64
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := next.Add(1)
+		for pb.Next() {
+			_, _, _ = st.Increment(ctx, keys[i%uint64(len(keys))], time.Minute)
+			i++
+		}
+	})
+}
+
+func BenchmarkMemory_Increment_ParallelManyKeys(b *testing.B) {
+	m := NewMemory()
+	defer m.Close()
+	benchmarkManyKeys(b, m)
+}
+
+func BenchmarkShardedMemory_Increment_ParallelManyKeys(b *testing.B) {
+	s := NewShardedMemory(32)
+	defer s.Close()
+	benchmarkManyKeys(b, s)
+}
@@ -4,7 +4,9 @@
 // Choose the implementation based on your deployment architecture:
 //
 //   - Memory: For development and single-instance deployments only
+//   - ShardedMemory: Memory partitioned across locks, for single instances with many keys
 //   - Redis: For production distributed deployments (Kubernetes, multiple instances)
+//   - Postgres: For distributed deployments that already run PostgreSQL but not Redis
 //
 // Example with in-memory store:
 //
@@ -21,6 +23,8 @@
 //		log.Fatal(err)
 //	}
 //	defer store.Close()
+//
+// Any store can be wrapped with Instrumented to observe operation latency and errors.
 package store
 
 import (
@@ -57,3 +61,83 @@ type Store interface {
 	// Close releases any resources held by the store (connections, goroutines, etc.).
 	Close() error
 }
+
+// Supports reports whether st supports the optional capability T (e.g.,
+// TokenBucketStore). Wrappers such as Instrumented implement every capability and
+// delegate to the store they wrap; they expose it with an Unwrap() Store method, and
+// Supports requires the capability at every layer.
+//
+// Example:
+//
+//	if !store.Supports[store.TokenBucketStore](st) {
+//		log.Fatal("token bucket rate limiting needs Memory or Redis")
+//	}
+func Supports[T any](st Store) bool {
+	for {
+		if _, ok := st.(T); !ok {
+			return false
+		}
+		w, ok := st.(interface{ Unwrap() Store })
+		if !ok {
+			return true
+		}
+		st = w.Unwrap()
+	}
+}
+
+// WindowResetStore is implemented by stores that record when a fixed window resets.
+// Memory, ShardedMemory, and Redis implement it. The rate limiter uses it when
+// available so RateLimit-Reset is the same for every request in a window, instead
+// of now plus a TTL that is counted down (and, in Redis, rounded) per request.
+type WindowResetStore interface {
+	// IncrementWithReset increments the counter for key like Increment and returns:
+	//   - count: The new count after incrementing
+	//   - resetAt: When the window resets, fixed when the window is created
+	//   - err: Any error that occurred during the operation
+	IncrementWithReset(ctx context.Context, key string, window time.Duration) (count int64, resetAt time.Time, err error)
+}
+
+// TokenBucketStore is implemented by stores that support token bucket rate limiting.
+// Memory and Redis implement it.
+type TokenBucketStore interface {
+	// TakeToken atomically refills the bucket for key and takes one token if available.
+	// A bucket holds at most capacity tokens and gains one token every refillInterval;
+	// a new bucket starts full. Returns:
+	//   - tokens: Tokens left after this call (fractional while refilling)
+	//   - allowed: Whether a token was taken
+	//   - err: Any error that occurred during the operation
+	//
+	// Buckets expire once they would be full again, so idle keys use no storage.
+	TakeToken(ctx context.Context, key string, capacity int64, refillInterval time.Duration) (tokens float64, allowed bool, err error)
+}
+
+// SlidingWindowStore is implemented by stores that support sliding window log rate limiting.
+// Memory and Redis implement it.
+type SlidingWindowStore interface {
+	// IncrementSliding atomically drops entries for key older than now-window and, if
+	// fewer than limit remain, records a new entry at now. Returns:
+	//   - count: Requests in the window including this one; greater than limit when
+	//     the request was rejected and therefore not recorded
+	//   - oldest: Time of the oldest recorded entry in the window (now if none); the
+	//     window frees a slot at oldest+window
+	//   - err: Any error that occurred during the operation
+	//
+	// Rejected requests are not recorded, so a client retrying while limited does not
+	// extend its own block, and at most limit entries are stored per key.
+	IncrementSliding(ctx context.Context, key string, limit int64, window time.Duration, now time.Time) (count int64, oldest time.Time, err error)
+}
+
+// GCRAStore is implemented by stores that support generic cell rate algorithm (GCRA)
+// rate limiting. Memory and Redis implement it.
+type GCRAStore interface {
+	// UpdateTAT atomically applies GCRA to the theoretical arrival time (TAT) stored for
+	// key: the request is allowed if advancing the TAT by emission keeps it at most
+	// tolerance ahead of now, in which case the advanced TAT is stored. A missing or
+	// past TAT counts as now. Returns:
+	//   - ahead: How far the stored TAT is ahead of now after this call
+	//   - allowed: Whether the request was allowed
+	//   - err: Any error that occurred during the operation
+	//
+	// Keys expire once their TAT has passed, so idle keys use no storage.
+	UpdateTAT(ctx context.Context, key string, emission, tolerance time.Duration) (ahead time.Duration, allowed bool, err error)
+}
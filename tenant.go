@@ -0,0 +1,184 @@
+package chikit
+
+// Tenant extraction middleware for multi-tenant apps keyed by subdomain.
+// Extracts the tenant slug from the Host header (e.g., "acme" from
+// "acme.api.example.com"), optionally resolves it, and stores it in context.
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/nhalm/canonlog"
+)
+
+type tenantContextKey string
+
+const tenantKey tenantContextKey = "tenant"
+
+// Tenant identifies the tenant resolved from the request host.
+type Tenant struct {
+	// Slug is the subdomain label identifying the tenant (e.g., "acme")
+	Slug string
+
+	// Value holds application data returned by the resolver (e.g., a tenant record)
+	Value any
+}
+
+// TenantResolver looks up a tenant by slug. Return an error for unknown tenants,
+// which results in 404. Return an *APIError to control the response instead
+// (e.g., ErrServiceUnavailable when the tenant store is down).
+//
+// Thread safety: Resolvers are called concurrently from multiple goroutines
+// and must be safe for concurrent use.
+type TenantResolver func(slug string) (Tenant, error)
+
+type tenantConfig struct {
+	baseDomain string
+	resolver   TenantResolver
+	allowApex  bool
+}
+
+// TenantOption configures TenantFromHost middleware.
+type TenantOption func(*tenantConfig)
+
+// TenantWithResolver validates the slug with a resolver. Without a resolver,
+// any well-formed slug is accepted.
+func TenantWithResolver(fn TenantResolver) TenantOption {
+	return func(c *tenantConfig) {
+		c.resolver = fn
+	}
+}
+
+// TenantWithAllowApex allows requests to the base domain itself (no subdomain).
+// These requests pass through with no tenant in context. By default they return 404.
+func TenantWithAllowApex() TenantOption {
+	return func(c *tenantConfig) {
+		c.allowApex = true
+	}
+}
+
+// TenantFromHost returns middleware that extracts the tenant slug from the subdomain
+// of the Host header under baseDomain. The tenant is stored in the request context
+// (see TenantFromContext) and added to the canonlog entry as "tenant".
+//
+// Returns 404 (Not Found) if:
+//   - The slug is not a single valid DNS label (e.g., "a.b.api.example.com")
+//   - The resolver rejects the slug
+//   - The request targets the apex domain and TenantWithAllowApex is not set
+//
+// Returns 400 (Bad Request) if the host is not under baseDomain.
+//
+// Example:
+//
+//	r.Use(chikit.TenantFromHost("api.example.com",
+//		chikit.TenantWithResolver(func(slug string) (chikit.Tenant, error) {
+//			t, err := db.FindTenant(slug)
+//			if err != nil {
+//				return chikit.Tenant{}, err
+//			}
+//			return chikit.Tenant{Slug: slug, Value: t}, nil
+//		}),
+//	))
+func TenantFromHost(baseDomain string, opts ...TenantOption) func(http.Handler) http.Handler {
+	if baseDomain == "" {
+		panic("TenantFromHost: baseDomain must be non-empty")
+	}
+
+	cfg := &tenantConfig{
+		baseDomain: strings.ToLower(strings.TrimSuffix(baseDomain, ".")),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := r.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+			if host == cfg.baseDomain {
+				if cfg.allowApex {
+					next.ServeHTTP(w, r)
+					return
+				}
+				respond(w, r, ErrNotFound.With("Tenant not found"))
+				return
+			}
+
+			slug, ok := strings.CutSuffix(host, "."+cfg.baseDomain)
+			if !ok {
+				respond(w, r, ErrBadRequest.With("Invalid host"))
+				return
+			}
+			if !validTenantSlug(slug) {
+				respond(w, r, ErrNotFound.With("Tenant not found"))
+				return
+			}
+
+			tenant := Tenant{Slug: slug}
+			if cfg.resolver != nil {
+				resolved, err := cfg.resolver(slug)
+				if err != nil {
+					var apiErr *APIError
+					if errors.As(err, &apiErr) {
+						respond(w, r, apiErr)
+					} else {
+						respond(w, r, ErrNotFound.With("Tenant not found"))
+					}
+					return
+				}
+				tenant = resolved
+				if tenant.Slug == "" {
+					tenant.Slug = slug
+				}
+			}
+
+			ctx := r.Context()
+			if _, ok := canonlog.TryGetLogger(ctx); ok {
+				canonlog.InfoAdd(ctx, "tenant", tenant.Slug)
+			}
+			ctx = context.WithValue(ctx, tenantKey, tenant)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// validTenantSlug reports whether slug is a single DNS label: 1-63 characters of
+// [a-z0-9-], not starting or ending with a hyphen.
+func validTenantSlug(slug string) bool {
+	if len(slug) == 0 || len(slug) > 63 {
+		return false
+	}
+	if slug[0] == '-' || slug[len(slug)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(slug); i++ {
+		c := slug[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// TenantFromContext retrieves the tenant stored by TenantFromHost from the request context.
+// Returns the tenant and true if present, or a zero Tenant and false if not present
+// (including apex requests allowed by TenantWithAllowApex).
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		if tenant, ok := chikit.TenantFromContext(r.Context()); ok {
+//			record := tenant.Value.(*TenantRecord)
+//		}
+//	}
+func TenantFromContext(ctx context.Context) (Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey).(Tenant)
+	return tenant, ok
+}
@@ -0,0 +1,129 @@
+package chikit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testTenantResolver(slug string) (Tenant, error) {
+	switch slug {
+	case "acme":
+		return Tenant{Value: 42}, nil
+	case "down":
+		return Tenant{}, ErrServiceUnavailable.With("Tenant store unavailable")
+	default:
+		return Tenant{}, errors.New("unknown tenant")
+	}
+}
+
+func TestTenantFromHost_ValidSubdomain(t *testing.T) {
+	var tenant Tenant
+	var found bool
+
+	handler := Handler(WithCanonlog())(TenantFromHost("api.example.com", TenantWithResolver(testTenantResolver))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		tenant, found = TenantFromContext(r.Context())
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	buf := captureCanonlog(t)
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Host = "ACME.api.example.com:8080"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if !found {
+		t.Fatal("expected tenant in context")
+	}
+	if tenant.Slug != "acme" {
+		t.Errorf("expected slug acme, got %s", tenant.Slug)
+	}
+	if tenant.Value != 42 {
+		t.Errorf("expected resolver value 42, got %v", tenant.Value)
+	}
+
+	entry := decodeCanonlog(t, buf)
+	if entry["tenant"] != "acme" {
+		t.Errorf("expected tenant=acme in log entry, got %v", entry["tenant"])
+	}
+}
+
+func TestTenantFromHost_Rejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		expected int
+	}{
+		{"unknown tenant", "globex.api.example.com", http.StatusNotFound},
+		{"resolver api error", "down.api.example.com", http.StatusServiceUnavailable},
+		{"nested subdomain", "a.acme.api.example.com", http.StatusNotFound},
+		{"invalid label", "-acme.api.example.com", http.StatusNotFound},
+		{"apex not allowed", "api.example.com", http.StatusNotFound},
+		{"foreign host", "acme.other.com", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled := false
+			handler := Handler()(TenantFromHost("api.example.com", TenantWithResolver(testTenantResolver))(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				handlerCalled = true
+			})))
+
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if handlerCalled {
+				t.Error("handler should not be called")
+			}
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
+
+func TestTenantFromHost_AllowApex(t *testing.T) {
+	var found bool
+	handler := TenantFromHost("api.example.com", TenantWithAllowApex())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, found = TenantFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Host = "api.example.com"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if found {
+		t.Error("expected no tenant in context for apex request")
+	}
+}
+
+func TestTenantFromHost_WithoutResolver(t *testing.T) {
+	var tenant Tenant
+	handler := TenantFromHost("api.example.com")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, _ = TenantFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Host = "initech.api.example.com"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if tenant.Slug != "initech" {
+		t.Errorf("expected slug initech, got %s", tenant.Slug)
+	}
+}
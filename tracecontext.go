@@ -0,0 +1,144 @@
+package chikit
+
+// W3C Trace Context propagation without a tracer.
+// Parses or generates a traceparent so logs can be correlated across services
+// that do not run OpenTelemetry.
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/nhalm/canonlog"
+)
+
+type traceContextKey string
+
+const traceKey traceContextKey = "trace_context"
+
+// TraceParentHeader and TraceStateHeader are the W3C Trace Context headers.
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+type traceInfo struct {
+	traceID string
+	spanID  string
+	state   string
+}
+
+// TraceContext returns middleware that propagates W3C Trace Context
+// (https://www.w3.org/TR/trace-context/). A valid incoming traceparent keeps its
+// trace ID; otherwise a new trace ID is generated and any tracestate is discarded.
+// Each request gets a new span ID, which is echoed on the response as
+// traceparent ("00-<trace-id>-<span-id>-<flags>") and stored in context
+// alongside the trace ID (see TraceIDFromContext and SpanIDFromContext).
+//
+// With WithCanonlog, trace_id and span_id are added to the request's log line.
+// Register TraceContext inside Handler so the fields reach its logger.
+//
+// Example:
+//
+//	r.Use(chikit.Handler(chikit.WithCanonlog()))
+//	r.Use(chikit.TraceContext())
+func TraceContext() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceID, flags, ok := parseTraceParent(r.Header.Get(TraceParentHeader))
+			var state string
+			if ok {
+				state = strings.Join(r.Header.Values(TraceStateHeader), ",")
+			} else {
+				var err error
+				if traceID, err = randomHex(16); err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				flags = "00"
+			}
+			spanID, err := randomHex(8)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			traceParent := "00-" + traceID + "-" + spanID + "-" + flags
+			if HasState(r.Context()) {
+				SetHeader(r, TraceParentHeader, traceParent)
+			} else {
+				w.Header().Set(TraceParentHeader, traceParent)
+			}
+
+			ctx := r.Context()
+			if logger, ok := canonlog.TryGetLogger(ctx); ok {
+				logger.InfoAdd("trace_id", traceID)
+				logger.InfoAdd("span_id", spanID)
+			}
+
+			ctx = context.WithValue(ctx, traceKey, traceInfo{traceID: traceID, spanID: spanID, state: state})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// parseTraceParent returns the trace ID and flags from a traceparent header value.
+// Version 00 must be exactly 55 characters; later versions may append fields.
+func parseTraceParent(value string) (traceID, flags string, ok bool) {
+	if !validTraceParentLayout(value) {
+		return "", "", false
+	}
+	version, traceID, spanID, flags := value[0:2], value[3:35], value[36:52], value[53:55]
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(value) != 55) {
+		return "", "", false
+	}
+	if !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, flags, true
+}
+
+// validTraceParentLayout reports whether value has the version-traceid-spanid-flags
+// field lengths and separators. Later versions may append fields after another '-'.
+func validTraceParentLayout(value string) bool {
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return false
+	}
+	return value[2] == '-' && value[35] == '-' && value[52] == '-'
+}
+
+// isLowerHex reports whether s consists only of lowercase hex digits.
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// TraceIDFromContext retrieves the 32-character hex trace ID set by TraceContext.
+// Returns the ID and true if present, or "" and false if not present.
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	info, ok := ctx.Value(traceKey).(traceInfo)
+	return info.traceID, ok
+}
+
+// SpanIDFromContext retrieves the 16-character hex span ID TraceContext assigned
+// to this request. Use it as the parent ID when propagating to downstream calls.
+// Returns the ID and true if present, or "" and false if not present.
+func SpanIDFromContext(ctx context.Context) (string, bool) {
+	info, ok := ctx.Value(traceKey).(traceInfo)
+	return info.spanID, ok
+}
+
+// TraceStateFromContext retrieves the incoming tracestate for propagation to
+// downstream calls. Returns "" and false if absent or discarded because the
+// incoming traceparent was invalid.
+func TraceStateFromContext(ctx context.Context) (string, bool) {
+	info, ok := ctx.Value(traceKey).(traceInfo)
+	return info.state, ok && info.state != ""
+}
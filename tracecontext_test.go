@@ -0,0 +1,88 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceContext_PropagatesIncoming(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	buf := captureCanonlog(t)
+
+	var gotTrace, gotSpan, gotState string
+	handler := Handler(WithCanonlog())(TraceContext()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotTrace, _ = TraceIDFromContext(r.Context())
+		gotSpan, _ = SpanIDFromContext(r.Context())
+		gotState, _ = TraceStateFromContext(r.Context())
+		SetResponse(r, http.StatusOK, nil)
+	})))
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set("tracestate", "vendor=abc")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if gotTrace != traceID {
+		t.Errorf("expected trace ID %s, got %s", traceID, gotTrace)
+	}
+	if len(gotSpan) != 16 || gotSpan == "00f067aa0ba902b7" {
+		t.Errorf("expected a new 16-char span ID, got %q", gotSpan)
+	}
+	if gotState != "vendor=abc" {
+		t.Errorf("expected tracestate vendor=abc, got %q", gotState)
+	}
+	if expected := "00-" + traceID + "-" + gotSpan + "-01"; rec.Header().Get("traceparent") != expected {
+		t.Errorf("expected traceparent %s, got %s", expected, rec.Header().Get("traceparent"))
+	}
+
+	entry := decodeCanonlog(t, buf)
+	if entry["trace_id"] != traceID || entry["span_id"] != gotSpan {
+		t.Errorf("expected trace_id and span_id in log, got %v", entry)
+	}
+}
+
+func TestTraceContext_GeneratesWhenAbsentOrInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+	}{
+		{"absent", ""},
+		{"malformed", "not-a-traceparent"},
+		{"zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{"uppercase hex", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTrace string
+			var found bool
+			handler := TraceContext()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTrace, found = TraceIDFromContext(r.Context())
+				_, hasState := TraceStateFromContext(r.Context())
+				if hasState {
+					t.Error("expected tracestate to be discarded")
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			if tt.traceParent != "" {
+				req.Header.Set("traceparent", tt.traceParent)
+			}
+			req.Header.Set("tracestate", "vendor=abc")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if !found || len(gotTrace) != 32 || strings.Contains(tt.traceParent, gotTrace) {
+				t.Errorf("expected a newly generated trace ID, got %q", gotTrace)
+			}
+			header := rec.Header().Get("traceparent")
+			if _, _, ok := parseTraceParent(header); !ok || !strings.Contains(header, gotTrace) {
+				t.Errorf("expected valid traceparent with new trace ID, got %q", header)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package chikit
+
+// Path-based API versioning (/v1/..., /v2/...).
+// Validates the leading version segment and stores it in context, optionally
+// stripping it so routes are declared once without the version prefix.
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type urlVersionContextKey string
+
+const urlVersionKey urlVersionContextKey = "url_version"
+
+// URLVersion returns middleware that reads the API version from the first path
+// segment (e.g., "v2" in /v2/users), rejects versions not in supported with 404,
+// and stores the version in context (see URLVersionFromContext). The path is left
+// unchanged, so routes include the version prefix. Use URLVersionStrip to remove it.
+//
+// Example:
+//
+//	r.Use(chikit.URLVersion("v1", "v2"))
+//	r.Get("/v1/users", listUsersV1)
+//	r.Get("/v2/users", listUsersV2)
+func URLVersion(supported ...string) func(http.Handler) http.Handler {
+	return urlVersion(supported, false)
+}
+
+// URLVersionStrip is URLVersion that also removes the version segment from the
+// request path, so downstream routing and handlers see /users for /v2/users and
+// branch on URLVersionFromContext where versions differ.
+//
+// Example:
+//
+//	r.Use(chikit.URLVersionStrip("v1", "v2"))
+//	r.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+//		version, _ := chikit.URLVersionFromContext(r.Context())
+//		// ...
+//	})
+func URLVersionStrip(supported ...string) func(http.Handler) http.Handler {
+	return urlVersion(supported, true)
+}
+
+func urlVersion(supported []string, strip bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version, rest := splitVersionSegment(r.URL.Path)
+			if !slices.Contains(supported, version) {
+				err := ErrNotFound.With("Unsupported API version")
+				respond(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), urlVersionKey, version)
+			r = r.WithContext(ctx)
+
+			if strip {
+				u := *r.URL
+				u.Path = rest
+				if u.RawPath != "" {
+					_, u.RawPath = splitVersionSegment(u.RawPath)
+				}
+				r.URL = &u
+				if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePath != "" {
+					if v, routeRest := splitVersionSegment(rctx.RoutePath); v == version {
+						rctx.RoutePath = routeRest
+					}
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// splitVersionSegment splits "/v2/users" into "v2" and "/users".
+// The remainder is "/" when the path is only the version.
+func splitVersionSegment(path string) (string, string) {
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment, "/" + rest
+}
+
+// URLVersionFromContext retrieves the API version set by URLVersion or URLVersionStrip.
+// Returns the version and true if present, or "" and false if not present.
+func URLVersionFromContext(ctx context.Context) (string, bool) {
+	version, ok := ctx.Value(urlVersionKey).(string)
+	return version, ok
+}
@@ -0,0 +1,73 @@
+package chikit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestURLVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		expected        int
+		expectedVersion string
+	}{
+		{"supported version", "/v2/users", http.StatusOK, "v2"},
+		{"unsupported version", "/v9/users", http.StatusNotFound, ""},
+		{"no version", "/users", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var version, path string
+			handler := Handler()(URLVersion("v1", "v2")(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				version, _ = URLVersionFromContext(r.Context())
+				path = r.URL.Path
+				SetResponse(r, http.StatusOK, nil)
+			})))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, http.NoBody))
+
+			if rec.Code != tt.expected {
+				t.Fatalf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if version != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, version)
+			}
+			if tt.expected == http.StatusOK && path != tt.path {
+				t.Errorf("expected path unchanged, got %q", path)
+			}
+		})
+	}
+}
+
+func TestURLVersionStrip_ChiRouting(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Handler())
+	r.Use(URLVersionStrip("v1", "v2"))
+
+	var version, path string
+	r.Get("/users", func(_ http.ResponseWriter, r *http.Request) {
+		version, _ = URLVersionFromContext(r.Context())
+		path = r.URL.Path
+		SetResponse(r, http.StatusOK, nil)
+	})
+
+	req := httptest.NewRequest("GET", "/v2/users", http.NoBody)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if version != "v2" || path != "/users" {
+		t.Errorf("expected version v2 and path /users, got %q and %q", version, path)
+	}
+	if req.URL.Path != "/v2/users" {
+		t.Errorf("expected original request URL to be unmodified, got %q", req.URL.Path)
+	}
+}
@@ -61,11 +61,7 @@ func MaxBodySize(maxBytes int64, opts ...BodySizeOption) func(http.Handler) http
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.ContentLength > cfg.maxBytes {
-				if HasState(r.Context()) {
-					SetError(r, ErrPayloadTooLarge.With("Request body too large"))
-				} else {
-					http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
-				}
+				respond(w, r, ErrPayloadTooLarge.With("Request body too large"))
 				return
 			}
 
@@ -125,15 +121,9 @@ func ValidateHeaders(opts ...ValidateHeadersOption) func(http.Handler) http.Hand
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			useWrapper := HasState(r.Context())
-
 			for i := range cfg.rules {
 				if err := validateHeaderRule(r, &cfg.rules[i]); err != nil {
-					if useWrapper {
-						SetError(r, err)
-					} else {
-						http.Error(w, err.Message, err.Status)
-					}
+					respond(w, r, err)
 					return
 				}
 			}
@@ -149,8 +139,8 @@ func validateHeaderRule(r *http.Request, rule *ValidateHeaderConfig) *APIError {
 	if value == "" {
 		if rule.Required {
 			return &APIError{
-				Type:    "validation_error",
-				Code:    "missing_header",
+				Type:    ErrorTypeValidation,
+				Code:    ErrorCodeMissingHeader,
 				Message: fmt.Sprintf("Missing required header: %s", rule.Name),
 				Param:   rule.Name,
 				Status:  http.StatusBadRequest,
@@ -194,8 +184,8 @@ func validateCheckAllowList(rule *ValidateHeaderConfig, checkValue string) *APIE
 	}
 
 	return &APIError{
-		Type:    "validation_error",
-		Code:    "invalid_header",
+		Type:    ErrorTypeValidation,
+		Code:    ErrorCodeInvalidHeader,
 		Message: fmt.Sprintf("Header %s value not in allowed list", rule.Name),
 		Param:   rule.Name,
 		Status:  http.StatusBadRequest,
@@ -214,8 +204,8 @@ func validateCheckDenyList(rule *ValidateHeaderConfig, checkValue string) *APIEr
 		}
 		if checkValue == compareVal {
 			return &APIError{
-				Type:    "validation_error",
-				Code:    "invalid_header",
+				Type:    ErrorTypeValidation,
+				Code:    ErrorCodeInvalidHeader,
 				Message: fmt.Sprintf("Header %s value is denied", rule.Name),
 				Param:   rule.Name,
 				Status:  http.StatusBadRequest,